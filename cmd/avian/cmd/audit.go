@@ -0,0 +1,97 @@
+/*
+Copyright © 2020 Avian Digital Forensics <sja@avian.dk>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit-log for the administrative actions",
+	Long: `Audit handles the audit-log for the administrative actions, the
+service records who applied or deleted the runners, servers and nms
+with a summary of the request (the secrets are redacted) and the outcome.`,
+}
+
+// auditListCmd represents the list audit command
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the audit-log with the latest first",
+	Long: `List the audit-log with the latest first. - For example:
+
+	avian audit list --since 7d --actor ci`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listAudit(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "could not list audit-log from backend: %v\n", err)
+		}
+	},
+}
+
+var auditService *avian.AuditService
+
+// auditListRequest is the request for the list audit command
+var auditListRequest avian.AuditListRequest
+
+// auditFull - if the requests should be listed without truncating
+var auditFull bool
+
+// auditRequestWidth is the width for the requests in the list
+const auditRequestWidth = 80
+
+func init() {
+	auditService = avian.NewAuditService(newClient())
+
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditListCmd)
+	auditListCmd.Flags().StringVar(&auditListRequest.Since, "since", "", "only list the entries since the duration (for example 24h or 7d) or the date (2006-01-02)")
+	auditListCmd.Flags().StringVar(&auditListRequest.Actor, "actor", "", "only list the entries for the actor (name of the api-token)")
+	auditListCmd.Flags().Int64Var(&auditListRequest.Limit, "limit", 0, "max amount of entries to list (defaults to 100)")
+	auditListCmd.Flags().StringVar(&auditListRequest.PageToken, "page", "", "token for the page to list")
+	auditListCmd.Flags().BoolVar(&auditFull, "full", false, "list the requests without truncating them")
+}
+
+func listAudit(ctx context.Context) error {
+	resp, err := auditService.List(ctx, auditListRequest)
+	if err != nil {
+		return err
+	}
+
+	var headers table.Row
+	var body []table.Row
+	headers = table.Row{"ID", "Time", "Actor", "Remote", "Method", "Outcome", "Reason", "Request"}
+	for _, e := range resp.Entries {
+		request := e.Request
+		if !auditFull && len(request) > auditRequestWidth {
+			request = request[:auditRequestWidth] + "..."
+		}
+		created := time.Unix(e.CTime, 0).Format("2006-01-02 15:04:05")
+		body = append(body, table.Row{e.ID, created, e.Actor, e.Remote, e.Method, e.Outcome, e.Reason, request})
+	}
+
+	fmt.Println(pretty.Format(headers, body))
+	printNextPage(resp.NextPageToken)
+	return nil
+}
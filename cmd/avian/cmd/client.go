@@ -0,0 +1,121 @@
+/*
+Copyright © 2020 Avian Digital Forensics <sja@avian.dk>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/certs"
+	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// config is the config-file for the cli
+type config struct {
+	// Token is the api-token (key.secret) for the service
+	Token string `yaml:"token"`
+
+	// TLS for the connection to the service
+	TLS struct {
+		// CA to verify the service with (enables https)
+		CA string `yaml:"ca"`
+
+		// Cert and Key is the client-certificate for mutual TLS
+		Cert string `yaml:"cert"`
+		Key  string `yaml:"key"`
+	} `yaml:"tls"`
+}
+
+// newClient returns a client for the avian-service,
+// the address and the port is read from the env-variables
+// AVIAN_ADDRESS and AVIAN_PORT, the api-token and the
+// tls-settings from the env-variables or the config-file
+func newClient() *avian.Client {
+	cfg := getConfig()
+
+	address := os.Getenv("AVIAN_ADDRESS")
+	if address == "" {
+		ip, err := utils.GetIPAddress()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot get ip-address: %v", err)
+			os.Exit(1)
+		}
+		address = ip
+	}
+
+	port := os.Getenv("AVIAN_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	scheme := "http"
+	if cfg.TLS.CA != "" || cfg.TLS.Cert != "" {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s:%s/oto/", scheme, address, port)
+
+	client := avian.New(url, cfg.Token)
+	if scheme == "https" {
+		tlsConfig, err := certs.ClientConfig(cfg.TLS.CA, cfg.TLS.Cert, cfg.TLS.Key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot load the tls-settings: %v", err)
+			os.Exit(1)
+		}
+		client.HTTPClient.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+	return client
+}
+
+// getConfig returns the config from the config-file, the values
+// are overridden by the env-variables AVIAN_TOKEN, AVIAN_TLS_CA,
+// AVIAN_TLS_CERT and AVIAN_TLS_KEY
+func getConfig() config {
+	var cfg config
+	if b, err := ioutil.ReadFile(configPath()); err == nil {
+		if err := yaml.Unmarshal(b, &cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot parse config-file: %v\n", err)
+		}
+	}
+
+	env := func(value *string, name string) {
+		if v := os.Getenv(name); v != "" {
+			*value = v
+		}
+	}
+	env(&cfg.Token, "AVIAN_TOKEN")
+	env(&cfg.TLS.CA, "AVIAN_TLS_CA")
+	env(&cfg.TLS.Cert, "AVIAN_TLS_CERT")
+	env(&cfg.TLS.Key, "AVIAN_TLS_KEY")
+	return cfg
+}
+
+// configPath returns the path for the config-file from the
+// env-variable AVIAN_CONFIG or ~/.avian/config.yml
+func configPath() string {
+	if path := os.Getenv("AVIAN_CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".avian", "config.yml")
+}
@@ -0,0 +1,201 @@
+/*
+Copyright © 2020 Avian Digital Forensics <sja@avian.dk>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/backup"
+	"github.com/spf13/cobra"
+)
+
+// dbCmd represents the db command
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Backup, restore and export the database",
+	Long: `Db backs up, restores and exports the database for the service.
+The backups contain the encrypted credentials and the api-tokens - keep
+them as safe as the database (they are only readable by the owner).`,
+}
+
+// dbBackupCmd represents the db backup command
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup <file>",
+	Short: "Backup the database to the file",
+	Long: `Backup writes a backup of the database to the file, the service
+can keep running while the backup is taken. The backup is a consistent
+snapshot of the SQLite-database or a dump of the tables for postgres
+and mysql (use --dump to move the SQLite-database to another driver).
+- For example:
+
+	avian db backup avian-2020-06-01.db
+	avian db backup avian.json --dump`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := backupDB(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not backup database: %v\n", err)
+		}
+	},
+}
+
+// dbRestoreCmd represents the db restore command
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Restore the database from a backup",
+	Long: `Restore restores the database from a backup, stop the service
+before the database is restored. The database must be empty (or not
+exist) unless --force is set, then it is replaced by the backup. A dump
+can be restored to any of the drivers and is migrated to the latest
+schema-version. - For example:
+
+	avian db restore avian-2020-06-01.db --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := restoreDB(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not restore database: %v\n", err)
+		}
+	},
+}
+
+// dbExportCmd represents the db export command
+var dbExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the runners, servers and nms as portable json",
+	Long: `Export writes the runners (with their stages), the servers and the
+nms (with their licences) as portable json, the credentials are masked.
+- For example:
+
+	avian db export --format json --out avian-export.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := exportDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "could not export database: %v\n", err)
+		}
+	},
+}
+
+// dbDump - if the sqlite-database should be backed up as a dump
+var dbDump bool
+
+// dbForce - if the database should be replaced by the restore
+var dbForce bool
+
+// dbExportFormat is the format for the export
+var dbExportFormat string
+
+// dbExportOut is the file for the export (stdout if it is empty)
+var dbExportOut string
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbBackupCmd)
+	dbCmd.AddCommand(dbRestoreCmd)
+	dbCmd.AddCommand(dbExportCmd)
+	dbCmd.PersistentFlags().StringVar(&dbName, "db", "avian.db", "path to the sqlite-database or the DSN for postgres and mysql (or set AVIAN_DB_DSN)")
+	dbCmd.PersistentFlags().StringVar(&dbDriver, "db-driver", datastore.DriverSQLite, "driver for the database ("+strings.Join(datastore.Drivers, ", ")+")")
+	dbBackupCmd.Flags().BoolVar(&dbDump, "dump", false, "backup the sqlite-database as a dump of the tables")
+	dbRestoreCmd.Flags().BoolVar(&dbForce, "force", false, "replace the existing database")
+	dbExportCmd.Flags().StringVar(&dbExportFormat, "format", backup.FormatJSON, "format for the export (json)")
+	dbExportCmd.Flags().StringVar(&dbExportOut, "out", "", "file for the export (defaults to stdout)")
+}
+
+func backupDB(path string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if dbDriver == datastore.DriverSQLite && !dbDump {
+		if err := backup.Snapshot(db, path); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "snapshot written to %s\n", path)
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if err := backup.Write(db, f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "dump written to %s\n", path)
+	return nil
+}
+
+func restoreDB(path string) error {
+	snapshot, err := backup.IsSnapshot(path)
+	if err != nil {
+		return err
+	}
+	if snapshot {
+		if dbDriver != datastore.DriverSQLite {
+			return fmt.Errorf("%s is a snapshot of a sqlite-database - it can't be restored to %s", path, dbDriver)
+		}
+		if err := backup.CopySnapshot(path, dbDSN(), dbForce); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "restored snapshot %s\n", path)
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := backup.Restore(db, f, dbForce); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "restored dump %s\n", path)
+	return nil
+}
+
+func exportDB() error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var w io.Writer = os.Stdout
+	if dbExportOut != "" {
+		f, err := os.OpenFile(dbExportOut, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return backup.WriteExport(db, w, dbExportFormat)
+}
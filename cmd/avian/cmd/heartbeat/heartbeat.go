@@ -1,10 +1,14 @@
 package heartbeat
 
 import (
+	"context"
 	"time"
 
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
 	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/avian-digital-forensics/auto-processing/pkg/metrics"
+	"github.com/avian-digital-forensics/auto-processing/pkg/notify"
 	"github.com/avian-digital-forensics/auto-processing/pkg/services"
 	"github.com/jinzhu/gorm"
 	"go.uber.org/zap"
@@ -14,14 +18,17 @@ type Service struct {
 	runnersvc services.RunnerService
 	pause     time.Duration
 	db        *gorm.DB
+	metrics   *metrics.Metrics
 	logger    *zap.Logger
 }
 
-func New(r services.RunnerService, logger *zap.Logger) Service {
-	return Service{r, 2 * time.Minute, r.DB, logger}
+func New(r services.RunnerService, m *metrics.Metrics, logger *zap.Logger) Service {
+	return Service{r, 2 * time.Minute, r.DB, m, logger}
 }
 
-func (s Service) Beat() {
+// Beat checks the health of the active runners until the
+// context is done, the current pass is finished before it returns
+func (s Service) Beat(ctx context.Context) {
 	for {
 		var runners []api.Runner
 		var lastCheck = time.Now().Add(-s.pause)
@@ -32,27 +39,31 @@ func (s Service) Beat() {
 		s.logger.Info("Got unhealthy runners from db", zap.Int("amount", len(runners)))
 
 		for _, runner := range runners {
-			runner.Status = avian.StatusTimeout
-			runner.Active = false
-			if err := s.db.Save(&runner).Error; err != nil {
+			// the runner might have finished since it was fetched
+			stopped, err := s.runnersvc.Deactivate(&runner, avian.StatusTimeout)
+			if err != nil {
 				s.logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
+				continue
 			}
-
-			// Set servers activity
-			if err := s.runnersvc.SetServerActivity(runner, false); err != nil {
-				s.logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
+			if !stopped {
+				continue
 			}
 
-			// update nms information
-			if err := s.runnersvc.ResetNms(runner); err != nil {
-				s.logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
-			}
+			s.metrics.HeartbeatTimeout()
+			s.runnersvc.Publish(events.Event{Type: events.TypeRunner, Runner: runner.Name, Status: avian.Status(runner.Status)})
+			s.runnersvc.Notify(notify.Event{Trigger: notify.OnTimeout, Runner: runner.Name, Status: avian.Status(runner.Status)})
 
+			// the server, licences and token are released by Deactivate
 			if err := s.runnersvc.RemoveScript(runner); err != nil {
 				s.logger.Error("Cannot remove script for runner", zap.String("exception", err.Error()))
 			}
 		}
 
-		time.Sleep(s.pause)
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Heartbeat stopped")
+			return
+		case <-time.After(s.pause):
+		}
 	}
 }
@@ -23,7 +23,6 @@ import (
 	"github.com/avian-digital-forensics/auto-processing/configs"
 	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
 	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
-	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
@@ -53,8 +52,9 @@ var nmsApplyCmd = &cobra.Command{
 var nmsLicencesCmd = &cobra.Command{
 	Use:   "licences",
 	Short: "List licences for the specified nms (specify by address)",
+	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := licencesNms(context.Background()); err != nil {
+		if err := licencesNms(context.Background(), args[0]); err != nil {
 			fmt.Fprintf(os.Stderr, "could not list licences from backend: %v\n", err)
 		}
 	},
@@ -71,31 +71,68 @@ var nmsListCmd = &cobra.Command{
 	},
 }
 
-var nmsService *avian.NmsService
+// nmsGetCmd represents the get nms command
+var nmsGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get the specified nms with its licences (specify by address)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := getNms(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not get nms from backend: %v\n", err)
+		}
+	},
+}
 
-func init() {
-	address := os.Getenv("AVIAN_ADDRESS")
-	if address == "" {
-		ip, err := utils.GetIPAddress()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Cannot get ip-address: %v", err)
-			os.Exit(1)
+// nmsDeleteCmd represents the delete nms command
+var nmsDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete the specified nms with its licences (specify by address)",
+	Long: `Delete the specified nms with its licences (specify by address). - The nms
+can't be deleted while it is used by any waiting or active runners`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := deleteNms(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not delete nms from backend: %v\n", err)
 		}
-		address = ip
-	}
+	},
+}
 
-	port := os.Getenv("AVIAN_PORT")
-	if port == "" {
-		port = "8080"
-	}
-	url := fmt.Sprintf("http://%s:%s/oto/", address, port)
+// nmsVerifyPasswordCmd represents the verify-password nms command
+var nmsVerifyPasswordCmd = &cobra.Command{
+	Use:   "verify-password",
+	Short: "Verify the stored password for the specified nms (specify by address)",
+	Long: `Verify the stored password for the specified nms (specify by address),
+the password to verify is read from stdin (it requires an admin-token). -
+The stored passwords are never returned by the service. - For example:
 
-	nmsService = avian.NewNmsService(avian.New(url, "hej"))
+	avian nms verify-password license.avian.dk < password.txt`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := verifyNmsPassword(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not verify password for nms: %v\n", err)
+		}
+	},
+}
+
+var (
+	nmsService     *avian.NmsService
+	nmsListRequest avian.NmsListRequest
+)
+
+func init() {
+	nmsService = avian.NewNmsService(newClient())
 
 	rootCmd.AddCommand(nmsCmd)
 	nmsCmd.AddCommand(nmsApplyCmd)
 	nmsCmd.AddCommand(nmsListCmd)
 	nmsCmd.AddCommand(nmsLicencesCmd)
+	nmsCmd.AddCommand(nmsGetCmd)
+	nmsCmd.AddCommand(nmsDeleteCmd)
+	nmsCmd.AddCommand(nmsVerifyPasswordCmd)
+	nmsListCmd.Flags().StringVar(&nmsListRequest.Address, "address", "", "only list the nms with addresses matching the glob-pattern (for example 10.0.*)")
+	nmsListCmd.Flags().StringVar(&nmsListRequest.Sort, "sort", "", "sort by id, address or created (prefix with - for descending order)")
+	nmsListCmd.Flags().Int64Var(&nmsListRequest.Limit, "limit", 0, "max amount of nms to list (defaults to 100)")
+	nmsListCmd.Flags().StringVar(&nmsListRequest.PageToken, "page", "", "token for the page to list")
 }
 
 func applyNms(ctx context.Context, path string) error {
@@ -114,7 +151,7 @@ func applyNms(ctx context.Context, path string) error {
 }
 
 func listNms(ctx context.Context) error {
-	resp, err := nmsService.List(ctx, avian.NmsListRequest{})
+	resp, err := nmsService.List(ctx, nmsListRequest)
 	if err != nil {
 		return err
 	}
@@ -127,24 +164,63 @@ func listNms(ctx context.Context) error {
 	}
 
 	fmt.Println(pretty.Format(headers, body))
+	printNextPage(resp.NextPageToken)
 	return nil
 }
 
-func licencesNms(ctx context.Context) error {
-	resp, err := nmsService.List(ctx, avian.NmsListRequest{})
+func licencesNms(ctx context.Context, address string) error {
+	resp, err := nmsService.ListLicences(ctx, avian.NmsListLicencesRequest{Address: address})
 	if err != nil {
 		return err
 	}
 
+	printLicences(address, resp.Licences)
+	return nil
+}
+
+func getNms(ctx context.Context, address string) error {
+	resp, err := nmsService.Get(ctx, avian.NmsGetRequest{Address: address})
+	if err != nil {
+		return err
+	}
+
+	s := resp.Nms
+	headers := table.Row{"ID", "Address", "Port", "Workers", "In-Use"}
+	fmt.Println(pretty.Format(headers, []table.Row{{s.ID, s.Address, s.Port, s.Workers, s.InUse}}))
+	printLicences(s.Address, s.Licences)
+	return nil
+}
+
+func deleteNms(ctx context.Context, address string) error {
+	if _, err := nmsService.Delete(ctx, avian.NmsDeleteRequest{Address: address}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "NMS: %s has been deleted\n", address)
+	return nil
+}
+
+func printLicences(address string, licences []avian.Licence) {
 	var headers table.Row
 	var body []table.Row
 	headers = table.Row{"ID", "Address", "Type", "Licences", "In-Use"}
-	for _, s := range resp.Nms {
-		for _, lic := range s.Licences {
-			body = append(body, table.Row{lic.ID, s.Address, lic.Type, lic.Amount, lic.InUse})
-		}
+	for _, lic := range licences {
+		body = append(body, table.Row{lic.ID, address, lic.Type, lic.Amount, lic.InUse})
 	}
 
 	fmt.Println(pretty.Format(headers, body))
+}
+
+func verifyNmsPassword(ctx context.Context, address string) error {
+	password, err := readLine("password")
+	if err != nil {
+		return err
+	}
+
+	resp, err := nmsService.VerifyPassword(ctx, avian.NmsVerifyPasswordRequest{Address: address, Password: password})
+	if err != nil {
+		return err
+	}
+	printMatch("nms", address, resp.Match)
 	return nil
 }
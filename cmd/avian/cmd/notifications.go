@@ -0,0 +1,177 @@
+/*
+Copyright © 2020 Avian Digital Forensics <sja@avian.dk>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/configs"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// notificationsCmd represents the notifications command
+var notificationsCmd = &cobra.Command{
+	Use:   "notifications",
+	Short: "Notifications for the runners",
+	Long: `Notifications handles the rules for notifying with webhooks
+or emails when the runners finish, fail, time out or when
+a stage fails, and the log of the deliveries.`,
+}
+
+// notificationsApplyCmd represents the apply notifications command
+var notificationsApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply the notification-rules with specified config",
+	Long: `Apply the notification-rules with specified config. - For example:
+
+	avian notifications apply notifications.yml`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := applyNotifications(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not apply notifications to backend: %v\n", err)
+		}
+	},
+}
+
+// notificationsListCmd represents the list notifications command
+var notificationsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the notification-rules",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listNotifications(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "could not list notifications from backend: %v\n", err)
+		}
+	},
+}
+
+// notificationsDeleteCmd represents the delete notifications command
+var notificationsDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete the notification-rule with the specified name",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := deleteNotification(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not delete notification from backend: %v\n", err)
+		}
+	},
+}
+
+// notificationsDeliveriesCmd represents the deliveries command
+var notificationsDeliveriesCmd = &cobra.Command{
+	Use:   "deliveries",
+	Short: "List the log of the deliveries (the latest first)",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listDeliveries(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "could not list deliveries from backend: %v\n", err)
+		}
+	},
+}
+
+var (
+	notificationService *avian.NotificationService
+	deliveriesRequest   avian.NotificationDeliveriesRequest
+)
+
+func init() {
+	notificationService = avian.NewNotificationService(newClient())
+
+	rootCmd.AddCommand(notificationsCmd)
+	notificationsCmd.AddCommand(notificationsApplyCmd)
+	notificationsCmd.AddCommand(notificationsListCmd)
+	notificationsCmd.AddCommand(notificationsDeleteCmd)
+	notificationsCmd.AddCommand(notificationsDeliveriesCmd)
+	notificationsDeliveriesCmd.Flags().StringVar(&deliveriesRequest.Notification, "notification", "", "only list the deliveries for the notification-rule")
+	notificationsDeliveriesCmd.Flags().StringVar(&deliveriesRequest.Runner, "runner", "", "only list the deliveries for the runner")
+	notificationsDeliveriesCmd.Flags().StringVar(&deliveriesRequest.Status, "status", "", "only list the deliveries with the status (pending, delivered or failed)")
+	notificationsDeliveriesCmd.Flags().Int64Var(&deliveriesRequest.Limit, "limit", 0, "max amount of deliveries to list (defaults to 100)")
+	notificationsDeliveriesCmd.Flags().StringVar(&deliveriesRequest.PageToken, "page", "", "token for the page to list")
+}
+
+func applyNotifications(ctx context.Context, path string) error {
+	cfg, err := configs.Get(path)
+	if err != nil {
+		return fmt.Errorf("Couldn't parse yml-file %s : %v", path, err)
+	}
+
+	resp, err := notificationService.Apply(ctx, cfg.API.Notifications)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range resp.Notifications {
+		fmt.Fprintf(os.Stdout, "notification: %s has been applied\n", n.Name)
+	}
+	return nil
+}
+
+func listNotifications(ctx context.Context) error {
+	resp, err := notificationService.List(ctx, avian.NotificationListRequest{})
+	if err != nil {
+		return err
+	}
+
+	var headers table.Row
+	var body []table.Row
+	headers = table.Row{"ID", "Name", "Runner", "Triggers", "Type", "Target"}
+	for _, n := range resp.Notifications {
+		runner := n.Runner
+		if runner == "" {
+			runner = "*"
+		}
+		target := n.Endpoint
+		if n.Type == "email" {
+			target = n.Recipients
+		}
+		body = append(body, table.Row{n.ID, n.Name, runner, n.Triggers, n.Type, target})
+	}
+
+	fmt.Println(pretty.Format(headers, body))
+	return nil
+}
+
+func deleteNotification(ctx context.Context, name string) error {
+	if _, err := notificationService.Delete(ctx, avian.NotificationDeleteRequest{Name: name}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "notification: %s has been deleted\n", name)
+	return nil
+}
+
+func listDeliveries(ctx context.Context) error {
+	resp, err := notificationService.Deliveries(ctx, deliveriesRequest)
+	if err != nil {
+		return err
+	}
+
+	var headers table.Row
+	var body []table.Row
+	headers = table.Row{"ID", "Created", "Notification", "Runner", "Trigger", "Status", "Attempts", "Error"}
+	for _, d := range resp.Deliveries {
+		created := time.Unix(d.CTime, 0).Format("2006-01-02 15:04:05")
+		body = append(body, table.Row{d.ID, created, d.Notification, d.Runner, d.Trigger, d.Status, d.Attempts, d.LastError})
+	}
+
+	fmt.Println(pretty.Format(headers, body))
+	printNextPage(resp.NextPageToken)
+	return nil
+}
@@ -1,15 +1,22 @@
 package queue
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	"github.com/avian-digital-forensics/auto-processing/generate/script"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
 	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
+	"github.com/avian-digital-forensics/auto-processing/pkg/manifest"
 	"github.com/avian-digital-forensics/auto-processing/pkg/powershell"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
 	"go.uber.org/zap"
 
 	"github.com/jinzhu/gorm"
@@ -24,19 +31,83 @@ type Queue struct {
 	db     *gorm.DB
 	shell  ps.Shell
 	uri    string
+	ca     string
 	logger *zap.Logger
+	cipher *secrets.Cipher
+
+	// manifests creates the manifests for the evidence
+	manifests *manifest.Builder
+
+	// tick is when the queue last looped (unix-time)
+	tick int64
+
+	// runs that are starting, their powershell-sessions are
+	// closed when the queue is closed - the runs that have
+	// started their runner-script are left running
+	mu     sync.Mutex
+	runs   map[*run]bool
+	closed bool
 }
 
-// New returns a new queue
-func New(db *gorm.DB, shell ps.Shell, uri string, logger *zap.Logger) Queue {
-	return Queue{db: db, shell: shell, uri: uri, logger: logger}
+// New returns a new queue, ca is the pinned CA (pem) for the runner-scripts
+// if the service uses TLS and the cipher resolves the credentials
+func New(db *gorm.DB, shell ps.Shell, uri, ca string, logger *zap.Logger, cipher *secrets.Cipher, manifests *manifest.Builder) *Queue {
+	return &Queue{
+		db:        db,
+		shell:     shell,
+		uri:       uri,
+		ca:        ca,
+		logger:    logger,
+		cipher:    cipher,
+		manifests: manifests,
+		tick:      time.Now().Unix(),
+		runs:      make(map[*run]bool),
+	}
 }
 
-func (q *Queue) Start() {
+// Start loops the queue until the context is done,
+// the current loop is finished before it returns
+func (q *Queue) Start(ctx context.Context) {
 	q.logger.Info("Queue started")
 	for {
 		q.loop()
-		time.Sleep(time.Duration(sleepMinutes * time.Minute))
+		atomic.StoreInt64(&q.tick, time.Now().Unix())
+
+		select {
+		case <-ctx.Done():
+			q.logger.Info("Queue stopped")
+			return
+		case <-time.After(time.Duration(sleepMinutes * time.Minute)):
+		}
+	}
+}
+
+// Check returns an error if the queue
+// hasn't looped as often as expected
+func (q *Queue) Check() error {
+	tick := time.Unix(atomic.LoadInt64(&q.tick), 0)
+	if since := time.Since(tick); since > 2*sleepMinutes*time.Minute {
+		return fmt.Errorf("queue hasn't looped for %v", since.Round(time.Second))
+	}
+	return nil
+}
+
+// Close closes the powershell-sessions for the runners that are
+// starting, the runners that are running are left alone (their
+// scripts report back to the service when it has restarted)
+func (q *Queue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	for r := range q.runs {
+		if r.running {
+			q.logger.Info("Leaving runner running", zap.String("runner", r.runner.Name))
+			continue
+		}
+		if r.client != nil {
+			q.logger.Info("Closing powershell-session for runner", zap.String("runner", r.runner.Name))
+			r.client.Close()
+		}
 	}
 }
 
@@ -97,24 +168,39 @@ type run struct {
 	server *api.Server
 	nms    *api.Nms
 	client *powershell.Client
+
+	// running is set when the runner-script has been started
+	running bool
 }
 
 func (q *Queue) newRun(runner *api.Runner, server *api.Server, nms *api.Nms) *run {
-	return &run{
+	r := &run{
 		queue:  q,
 		runner: runner,
 		server: server,
 		nms:    nms,
 	}
+	q.mu.Lock()
+	q.runs[r] = true
+	q.mu.Unlock()
+	return r
 }
 
 func (r *run) setActive() error {
 	db := r.queue.db
 
+	// Create a new id for the run, the callbacks
+	// from the scripts of previous runs are rejected
+	runID, err := auth.NewRunID()
+	if err != nil {
+		return err
+	}
+
 	// Set runner to active and save to db
 	now := time.Now()
 	r.runner.HealthyAt = &now
 	r.runner.Active = true
+	r.runner.RunID = runID
 	if err := db.Save(&r.runner).Error; err != nil {
 		return fmt.Errorf("Failed to set runner to active: %v", err)
 	}
@@ -125,11 +211,12 @@ func (r *run) setActive() error {
 	}
 
 	// Set new values to NMS
-	r.nms.InUse = r.runner.Workers
-	for _, lic := range r.nms.Licences {
+	r.nms.InUse += r.runner.Workers
+	for i := range r.nms.Licences {
+		lic := &r.nms.Licences[i]
 		if lic.Type == r.runner.Licence {
 			lic.InUse += 1
-			if err := db.Save(&lic).Error; err != nil {
+			if err := db.Save(lic).Error; err != nil {
 				return fmt.Errorf("Failed to update licence: %s %s : %v", r.nms.Address, lic.Type, err)
 			}
 		}
@@ -148,9 +235,16 @@ func (r *run) start() error {
 		zap.String("runner", r.runner.Name),
 		zap.String("server", r.server.Hostname),
 	)
-	// Generate the ruby-script for the runner
-	logger.Info("Generating script for runner")
-	script, err := ruby.Generate(r.queue.uri, *r.runner)
+	// Generate the script for the runner
+	// with the engine for the runner or server
+	engine := script.Engine(*r.runner, *r.server)
+	logger.Info("Generating script for runner", zap.String("engine", engine))
+	generator, err := script.New(engine)
+	if err != nil {
+		return err
+	}
+
+	code, err := generator.Generate(r.queue.uri, r.queue.ca, *r.runner)
 	if err != nil {
 		return fmt.Errorf("failed to generate script for runner: %s - %v", r.runner.Name, err)
 	}
@@ -165,7 +259,11 @@ func (r *run) start() error {
 	if len(r.server.Username) != 0 {
 		logger.Debug("Adding credentials for powershell-session")
 		opts.Username = r.server.Username
-		opts.Password = r.server.Password
+		password, err := secrets.Resolve(r.queue.db, r.queue.cipher, r.server.Password)
+		if err != nil {
+			return fmt.Errorf("cannot resolve password for server: %s - %v", r.server.Hostname, err)
+		}
+		opts.Password = password
 	}
 
 	// create the client
@@ -173,7 +271,14 @@ func (r *run) start() error {
 	if err != nil {
 		return fmt.Errorf("failed to create remote-client for powershell: %v", err)
 	}
+	r.queue.mu.Lock()
+	if r.queue.closed {
+		r.queue.mu.Unlock()
+		client.Close()
+		return errors.New("the queue is closed")
+	}
 	r.client = client
+	r.queue.mu.Unlock()
 	logger.Debug("Powershell-client has been created for runner")
 
 	// Check for case-locks
@@ -182,6 +287,16 @@ func (r *run) start() error {
 		return err
 	}
 
+	// Create the manifest for the evidence before the processing, the
+	// evidence is hashed in its own powershell-process (not the shell
+	// that is shared with the other runners and the service)
+	if manifest.Enabled(*r.runner) {
+		if err := r.queue.manifests.Record(*r.runner, *r.server, manifest.PhaseRun, true); err != nil {
+			client.Close()
+			return err
+		}
+	}
+
 	// Set nuix username as an env-variable
 	if err := client.SetEnv("NUIX_USERNAME", r.nms.Username); err != nil {
 		client.Close()
@@ -189,12 +304,36 @@ func (r *run) start() error {
 	}
 
 	// Set nuix password as an env-variable
-	if err := client.SetEnv("NUIX_PASSWORD", r.nms.Password); err != nil {
+	nmsPassword, err := secrets.Resolve(r.queue.db, r.queue.cipher, r.nms.Password)
+	if err != nil {
+		client.Close()
+		return fmt.Errorf("cannot resolve password for nms: %s - %v", r.nms.Address, err)
+	}
+	if err := client.SetEnv("NUIX_PASSWORD", nmsPassword); err != nil {
 		client.Close()
 		return fmt.Errorf("unable to set NUIX_PASSWORD env-variable: %v", err)
 	}
 
-	scriptName := r.runner.Name + ".gen.rb"
+	// Create a token for the runner-script that is
+	// only valid for the callbacks of the runner
+	logger.Debug("Creating api-token for runner")
+	token, err := auth.NewRunnerToken(r.queue.db, r.queue.cipher, *r.runner)
+	if err != nil {
+		client.Close()
+		return err
+	}
+
+	// Set the api-token as an env-variable
+	if err := client.SetEnv("AVIAN_TOKEN", token); err != nil {
+		client.Close()
+		return fmt.Errorf("unable to set AVIAN_TOKEN env-variable: %v", err)
+	}
+
+	scriptName, err := script.FileName(*r.runner, *r.server)
+	if err != nil {
+		client.Close()
+		return err
+	}
 
 	r.queue.logger.Info("Creating runner-script to server",
 		zap.String("runner", r.runner.Name),
@@ -202,7 +341,7 @@ func (r *run) start() error {
 		zap.String("script", scriptName),
 	)
 
-	if err := client.CreateFile(r.server.NuixPath, scriptName, []byte(script)); err != nil {
+	if err := client.CreateFile(r.server.NuixPath, scriptName, []byte(code)); err != nil {
 		client.Close()
 		return fmt.Errorf("Failed to create script-file: %v", err)
 	}
@@ -234,6 +373,16 @@ func (r *run) start() error {
 
 	args = append(args, scriptName)
 
+	// the runner is left running if the queue is closed from now on
+	r.queue.mu.Lock()
+	if r.queue.closed {
+		r.queue.mu.Unlock()
+		client.Close()
+		return errors.New("the queue is closed")
+	}
+	r.running = true
+	r.queue.mu.Unlock()
+
 	return client.Run(r.server.NuixPath, args...)
 }
 
@@ -261,8 +410,13 @@ func (r *run) close() error {
 	}
 	r.queue.logger.Debug("Closing runner", zap.String("runner", r.runner.Name))
 
-	r.client.Close()
+	r.queue.mu.Lock()
+	delete(r.queue.runs, r)
+	if r.client != nil {
+		r.client.Close()
+	}
 	r.client = nil
+	r.queue.mu.Unlock()
 	r.queue = nil
 	r.runner = nil
 	r.server = nil
@@ -273,7 +427,9 @@ func (r *run) close() error {
 func getRunners(db *gorm.DB) ([]*api.Runner, error) {
 	var runners []*api.Runner
 	err := db.
-		Preload("Stages.Process.EvidenceStore").
+		Preload("Stages.Process.EvidenceStore.Paths").
+		Preload("Stages.Process.EvidenceStore.Filters").
+		Preload("Stages.Process.EvidenceStore.Metadata").
 		Preload("Stages.SearchAndTag.Files").
 		Preload("Stages.Exclude").
 		Preload("Stages.Ocr").
@@ -283,6 +439,7 @@ func getRunners(db *gorm.DB) ([]*api.Runner, error) {
 		Preload("CaseSettings.CompoundCase").
 		Preload("CaseSettings.ReviewCompound").
 		Preload("Switches").
+		Scopes(datastore.NotDeleted).
 		Where("active = ? and status = ?", false, avian.StatusWaiting).
 		Find(&runners).Error
 	return runners, err
@@ -290,7 +447,9 @@ func getRunners(db *gorm.DB) ([]*api.Runner, error) {
 
 func getRunnerByName(db *gorm.DB, name string) (*api.Runner, error) {
 	var runner api.Runner
-	err := db.Preload("Stages.Process.EvidenceStore").
+	err := db.Preload("Stages.Process.EvidenceStore.Paths").
+		Preload("Stages.Process.EvidenceStore.Filters").
+		Preload("Stages.Process.EvidenceStore.Metadata").
 		Preload("Stages.SearchAndTag.Files").
 		Preload("Stages.Exclude").
 		Preload("Stages.Ocr").
@@ -17,13 +17,18 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
 
 	"github.com/avian-digital-forensics/auto-processing/configs"
 	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
 	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
-	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 )
@@ -79,7 +84,7 @@ var runnerStagesCmd = &cobra.Command{
 // runnerDeleteCmd represents the delete runner command
 var runnerDeleteCmd = &cobra.Command{
 	Use:   "delete",
-	Short: "List the stages for the specified runner (specified by name)",
+	Short: "Move the specified runner (specified by name) to the trash, or delete it permanently with --purge",
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := deleteRunner(context.Background(), args[0]); err != nil {
@@ -88,38 +93,142 @@ var runnerDeleteCmd = &cobra.Command{
 	},
 }
 
+// runnerTrashCmd represents the trash runner command
+var runnerTrashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Trash holds the deleted runners until they are restored or purged",
+}
+
+// runnerTrashListCmd represents the list trash runner command
+var runnerTrashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the runners in the trash",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listTrash(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "could not list trash from backend: %v\n", err)
+		}
+	},
+}
+
+// runnerRestoreCmd represents the restore runner command
+var runnerRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore the specified runner (specified by name) from the trash",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := restoreRunner(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not restore runner from backend: %v\n", err)
+		}
+	},
+}
+
+// runnerPurgeCmd represents the purge runner command
+var runnerPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently delete the runners that have been in the trash for longer than --older-than",
+	Long: `Purge permanently deletes the runners that have been in the trash
+for longer than the duration. - For example:
+
+	avian runners purge --older-than 30d`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := purgeRunners(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "could not purge runners from backend: %v\n", err)
+		}
+	},
+}
+
+// runnerManifestCmd represents the manifest runner command
+var runnerManifestCmd = &cobra.Command{
+	Use:   "manifest",
+	Short: "Print or export the evidence-manifests for the specified runner (specified by name)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := manifestRunner(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not get manifests for runner from backend: %v\n", err)
+		}
+	},
+}
+
+// runnerScriptCmd represents the script runner command
+var runnerScriptCmd = &cobra.Command{
+	Use:   "script",
+	Short: "Print or export the generated script for the specified runner (specified by name or yml-file)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := scriptRunner(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not get script for runner from backend: %v\n", err)
+		}
+	},
+}
+
+// runnerWatchCmd represents the watch runner command
+var runnerWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch the events for the specified runner (specified by name), or for all runners if not specified",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var name string
+		if len(args) != 0 {
+			name = args[0]
+		}
+		if err := watchRunner(name); err != nil {
+			fmt.Fprintf(os.Stderr, "could not watch events from backend: %v\n", err)
+		}
+	},
+}
+
 var (
+	runnerClient  *avian.Client
 	runnerService *avian.RunnerService
 	forceDelete   bool
+	purgeDelete   bool
+	purgeOlder    string
 	forceApply    bool
+	manifestOut   string
+	scriptOut     string
+	watchMatter   string
+	listRequest   avian.RunnerListRequest
+	trashRequest  avian.RunnerListTrashRequest
+	listAfter     string
+	listBefore    string
 )
 
 func init() {
-	address := os.Getenv("AVIAN_ADDRESS")
-	if address == "" {
-		ip, err := utils.GetIPAddress()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Cannot get ip-address: %v", err)
-			os.Exit(1)
-		}
-		address = ip
-	}
-
-	port := os.Getenv("AVIAN_PORT")
-	if port == "" {
-		port = "8080"
-	}
-	url := fmt.Sprintf("http://%s:%s/oto/", address, port)
-
-	runnerService = avian.NewRunnerService(avian.New(url, "hej"))
+	runnerClient = newClient()
+	runnerService = avian.NewRunnerService(runnerClient)
 
 	rootCmd.AddCommand(runnersCmd)
 	runnersCmd.AddCommand(runnersApplyCmd)
 	runnersCmd.AddCommand(runnersListCmd)
 	runnersCmd.AddCommand(runnerStagesCmd)
 	runnersCmd.AddCommand(runnerDeleteCmd)
+	runnersCmd.AddCommand(runnerTrashCmd)
+	runnerTrashCmd.AddCommand(runnerTrashListCmd)
+	runnersCmd.AddCommand(runnerRestoreCmd)
+	runnersCmd.AddCommand(runnerPurgeCmd)
+	runnersCmd.AddCommand(runnerManifestCmd)
+	runnersCmd.AddCommand(runnerScriptCmd)
+	runnersCmd.AddCommand(runnerWatchCmd)
 	runnerDeleteCmd.Flags().BoolVar(&forceDelete, "force", false, "force deleting an active runner")
+	runnerDeleteCmd.Flags().BoolVar(&purgeDelete, "purge", false, "delete the runner permanently instead of moving it to the trash")
+	runnerPurgeCmd.Flags().StringVar(&purgeOlder, "older-than", "", "purge the runners that have been in the trash for longer than the duration (for example 720h or 30d)")
+	runnerPurgeCmd.MarkFlagRequired("older-than")
 	runnersApplyCmd.Flags().BoolVar(&forceApply, "force", false, "force applying a runner")
+	runnerManifestCmd.Flags().StringVar(&manifestOut, "out", "", "export the manifests as json to the specified file")
+	runnerScriptCmd.Flags().StringVar(&scriptOut, "out", "", "export the script to the specified file")
+	runnersListCmd.Flags().StringVar(&listRequest.Status, "status", "", "only list the runners with the status (waiting, running, failed, finished or timeout)")
+	runnersListCmd.Flags().StringVar(&listRequest.Hostname, "host", "", "only list the runners for the server")
+	runnersListCmd.Flags().StringVar(&listRequest.Nms, "nms", "", "only list the runners for the nms")
+	runnersListCmd.Flags().StringVar(&listRequest.Licence, "licence", "", "only list the runners with the licencetype")
+	runnersListCmd.Flags().StringVar(&listRequest.Name, "name", "", "only list the runners with names matching the glob-pattern (for example case-*)")
+	runnersListCmd.Flags().StringVar(&listAfter, "after", "", "only list the runners created after the date (2006-01-02)")
+	runnersListCmd.Flags().StringVar(&listBefore, "before", "", "only list the runners created before the date (2006-01-02)")
+	runnersListCmd.Flags().StringVar(&listRequest.Sort, "sort", "", "sort by id, name, created, status or hostname (prefix with - for descending order)")
+	runnersListCmd.Flags().Int64Var(&listRequest.Limit, "limit", 0, "max amount of runners to list (defaults to 100)")
+	runnersListCmd.Flags().StringVar(&listRequest.PageToken, "page", "", "token for the page to list")
+	runnerTrashListCmd.Flags().Int64Var(&trashRequest.Limit, "limit", 0, "max amount of runners to list (defaults to 100)")
+	runnerTrashListCmd.Flags().StringVar(&trashRequest.PageToken, "page", "", "token for the page to list")
+	runnerWatchCmd.Flags().StringVar(&watchMatter, "matter", "", "only watch the events for the matter (name of the compound-case)")
 }
 
 func applyRunner(ctx context.Context, path string) error {
@@ -144,20 +253,31 @@ func applyRunner(ctx context.Context, path string) error {
 }
 
 func listRunners(ctx context.Context) error {
-	resp, err := runnerService.List(ctx, avian.RunnerListRequest{})
+	var err error
+	if listRequest.CreatedAfter, err = parseDate(listAfter); err != nil {
+		return err
+	}
+	if listRequest.CreatedBefore, err = parseDate(listBefore); err != nil {
+		return err
+	}
+
+	resp, err := runnerService.List(ctx, listRequest)
 	if err != nil {
 		return err
 	}
 
 	var headers table.Row
 	var body []table.Row
-	headers = table.Row{"ID", "Runner", "Host", "Nms", "Licencetype", "Workers", "Status", "Stage"}
+	headers = table.Row{"ID", "Runner", "Host", "Nms", "Licencetype", "Workers", "Status", "Stage", "Progress", "ETA"}
 	for _, r := range resp.Runners {
 		var status string
 		var stage string
+		var progress, eta string
 		for _, s := range r.Stages {
 			stage = s.Name()
 			status = s.Status()
+			progress = s.Percentage()
+			eta = s.ETA()
 
 			// Break if the stage is running
 			if status == "Running" {
@@ -173,13 +293,33 @@ func listRunners(ctx context.Context) error {
 				break
 			}
 		}
-		body = append(body, table.Row{r.ID, r.Name, r.Hostname, r.Nms, r.Licence, r.Workers, avian.Status(r.Status), stage})
+		body = append(body, table.Row{r.ID, r.Name, r.Hostname, r.Nms, r.Licence, r.Workers, avian.Status(r.Status), stage, progress, eta})
 	}
 
 	fmt.Fprintf(os.Stdout, "%s\n", pretty.Format(headers, body))
+	printNextPage(resp.NextPageToken)
 	return nil
 }
 
+// parseDate returns the date (2006-01-02) as unix-time, zero if empty
+func parseDate(date string) (int64, error) {
+	if date == "" {
+		return 0, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return 0, fmt.Errorf("invalid date: %s - expected 2006-01-02", date)
+	}
+	return t.Unix(), nil
+}
+
+// printNextPage prints how to list the next page
+func printNextPage(token string) {
+	if token != "" {
+		fmt.Fprintf(os.Stdout, "more results available - use: --page %s\n", token)
+	}
+}
+
 func stagesRunner(ctx context.Context, runner string) error {
 	resp, err := runnerService.Get(ctx, avian.RunnerGetRequest{Name: runner})
 	if err != nil {
@@ -188,10 +328,10 @@ func stagesRunner(ctx context.Context, runner string) error {
 
 	var headers table.Row
 	var body []table.Row
-	headers = table.Row{"ID", "Runner", "Stage", "Status"}
+	headers = table.Row{"ID", "Runner", "Stage", "Status", "Progress", "ETA"}
 
 	for _, s := range resp.Runner.Stages {
-		body = append(body, table.Row{s.ID, resp.Runner.Name, s.Name(), s.Status()})
+		body = append(body, table.Row{s.ID, resp.Runner.Name, s.Name(), s.Status(), s.Percentage(), s.ETA()})
 	}
 
 	fmt.Fprintf(os.Stdout, "%s\n", pretty.Format(headers, body))
@@ -199,11 +339,192 @@ func stagesRunner(ctx context.Context, runner string) error {
 }
 
 func deleteRunner(ctx context.Context, runner string) error {
-	_, err := runnerService.Delete(ctx, avian.RunnerDeleteRequest{Name: runner, Force: forceDelete})
+	_, err := runnerService.Delete(ctx, avian.RunnerDeleteRequest{Name: runner, Force: forceDelete, Purge: purgeDelete})
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintf(os.Stdout, "Runner: %s has been deleted", runner)
+	if purgeDelete {
+		fmt.Fprintf(os.Stdout, "Runner: %s has been deleted", runner)
+		return nil
+	}
+	fmt.Fprintf(os.Stdout, "Runner: %s has been moved to the trash - restore it with: avian runners restore %s", runner, runner)
 	return nil
 }
+
+func listTrash(ctx context.Context) error {
+	resp, err := runnerService.ListTrash(ctx, trashRequest)
+	if err != nil {
+		return err
+	}
+
+	var headers table.Row
+	var body []table.Row
+	headers = table.Row{"ID", "Runner", "Host", "Nms", "Licencetype", "Status", "Deleted"}
+	for _, r := range resp.Runners {
+		var deleted string
+		if r.DTime != nil {
+			deleted = time.Unix(*r.DTime, 0).Format("2006-01-02 15:04:05")
+		}
+		body = append(body, table.Row{r.ID, r.Name, r.Hostname, r.Nms, r.Licence, avian.Status(r.Status), deleted})
+	}
+
+	fmt.Fprintf(os.Stdout, "%s\n", pretty.Format(headers, body))
+	printNextPage(resp.NextPageToken)
+	return nil
+}
+
+func restoreRunner(ctx context.Context, runner string) error {
+	if _, err := runnerService.Restore(ctx, avian.RunnerRestoreRequest{Name: runner}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Runner: %s has been restored", runner)
+	return nil
+}
+
+func purgeRunners(ctx context.Context) error {
+	resp, err := runnerService.Purge(ctx, avian.RunnerPurgeRequest{OlderThan: purgeOlder})
+	if err != nil {
+		return err
+	}
+
+	for _, runner := range resp.Runners {
+		fmt.Fprintf(os.Stdout, "Runner: %s has been purged\n", runner)
+	}
+	fmt.Fprintf(os.Stdout, "%d runners purged from the trash\n", len(resp.Runners))
+	return nil
+}
+
+func manifestRunner(ctx context.Context, runner string) error {
+	resp, err := runnerService.Manifest(ctx, avian.RunnerManifestRequest{Name: runner})
+	if err != nil {
+		return err
+	}
+
+	if manifestOut != "" {
+		data, err := json.MarshalIndent(resp.Manifests, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(manifestOut, data, 0644); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stdout, "Manifests for runner: %s has been exported to %s", runner, manifestOut)
+		return nil
+	}
+
+	var headers table.Row
+	var body []table.Row
+	headers = table.Row{"ID", "Runner", "Phase", "Files", "Size", "Verified", "Changes", "Created"}
+	for _, m := range resp.Manifests {
+		verified := "Yes"
+		if !m.Verified {
+			verified = "CHANGED"
+		}
+		created := time.Unix(m.CTime, 0).Format(time.RFC3339)
+		body = append(body, table.Row{m.ID, runner, m.Phase, m.FileCount, m.TotalSize, verified, len(m.Changes), created})
+	}
+	fmt.Fprintf(os.Stdout, "%s\n", pretty.Format(headers, body))
+
+	// print the changes for the manifests
+	body = nil
+	headers = table.Row{"Manifest", "Phase", "Change", "Path"}
+	for _, m := range resp.Manifests {
+		for _, change := range m.Changes {
+			body = append(body, table.Row{m.ID, m.Phase, change.Change, change.Path})
+		}
+	}
+
+	if len(body) != 0 {
+		fmt.Fprintf(os.Stdout, "\n%s\n", pretty.Format(headers, body))
+	}
+	return nil
+}
+
+func scriptRunner(ctx context.Context, runner string) error {
+	req := avian.RunnerScriptRequest{Name: runner}
+
+	// generate the script for the runner in a local
+	// yml-file, to check it before it is applied
+	if ext := filepath.Ext(runner); ext == ".yml" || ext == ".yaml" {
+		cfg, err := configs.Get(runner)
+		if err != nil {
+			return fmt.Errorf("Couldn't parse yml-file %s : %v", runner, err)
+		}
+
+		r, err := configs.SetCaseSettings(cfg.API.Runner)
+		if err != nil {
+			return err
+		}
+		req.Runner = &r
+	}
+
+	resp, err := runnerService.Script(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if scriptOut != "" {
+		if err := ioutil.WriteFile(scriptOut, []byte(resp.Script), 0644); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stdout, "Script for runner: %s has been exported to %s", runner, scriptOut)
+		return nil
+	}
+
+	fmt.Fprintf(os.Stdout, "%s\n", resp.Script)
+	return nil
+}
+
+// watchRunner prints the events for the runner until it has
+// finished or failed, or until the command is interrupted
+func watchRunner(name string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	return runnerClient.Watch(ctx, name, watchMatter, func(e events.Event) {
+		fmt.Println(formatEvent(e))
+
+		// stop watching when the watched runner is done
+		if name != "" && e.Type == events.TypeRunner && e.Status != avian.Status(avian.StatusRunning) {
+			cancel()
+		}
+	})
+}
+
+// formatEvent returns the event as a line for the console
+func formatEvent(e events.Event) string {
+	prefix := fmt.Sprintf("%s %-8s %s", e.Time.Format("2006-01-02 15:04:05"), e.Type, e.Runner)
+	switch e.Type {
+	case events.TypeRunner:
+		if e.Message != "" {
+			return fmt.Sprintf("%s: %s - %s", prefix, e.Status, e.Message)
+		}
+		return fmt.Sprintf("%s: %s", prefix, e.Status)
+	case events.TypeStage:
+		return fmt.Sprintf("%s: stage %d %s - %s", prefix, e.StageID, e.Stage, e.Status)
+	case events.TypeProgress:
+		line := fmt.Sprintf("%s: stage %d %d/%d", prefix, e.StageID, e.Progress, e.Total)
+		if e.EstimatedFinish != 0 {
+			line += fmt.Sprintf(" eta %s", time.Until(time.Unix(e.EstimatedFinish, 0)).Round(time.Second))
+		}
+		return line
+	case events.TypeLog:
+		if e.Stage != "" {
+			return fmt.Sprintf("%s: [%s] %s: %s", prefix, e.Level, e.Stage, e.Message)
+		}
+		return fmt.Sprintf("%s: [%s] %s", prefix, e.Level, e.Message)
+	}
+	return prefix
+}
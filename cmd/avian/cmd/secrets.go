@@ -0,0 +1,128 @@
+/*
+Copyright © 2020 Avian Digital Forensics <sja@avian.dk>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// secretsCmd represents the secrets command
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Named secrets for the credentials",
+	Long: `Secrets handles the named secrets that are stored (encrypted) in the
+backend, the secrets can be referenced for the credentials in the yml-configs:
+
+	password: secret:<name>`,
+}
+
+// secretsSetCmd represents the set secrets command
+var secretsSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set the secret with the specified name",
+	Long: `Set the secret with the specified name, the value is read from stdin. - For example:
+
+	avian secrets set nms-password
+	echo "$NMS_PASSWORD" | avian secrets set nms-password`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := setSecret(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not set secret: %v\n", err)
+		}
+	},
+}
+
+// secretsListCmd represents the list secrets command
+var secretsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the secrets (without the values)",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listSecrets(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "could not list secrets from backend: %v\n", err)
+		}
+	},
+}
+
+// secretsDeleteCmd represents the delete secrets command
+var secretsDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete the secret with the specified name",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := deleteSecret(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not delete secret: %v\n", err)
+		}
+	},
+}
+
+var secretService *avian.SecretService
+
+func init() {
+	secretService = avian.NewSecretService(newClient())
+
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+	secretsCmd.AddCommand(secretsDeleteCmd)
+}
+
+func setSecret(ctx context.Context, name string) error {
+	value, err := readLine("value")
+	if err != nil {
+		return err
+	}
+
+	if _, err := secretService.Set(ctx, avian.SecretSetRequest{Name: name, Value: value}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "secret: %s has been set, reference it with: secret:%s\n", name, name)
+	return nil
+}
+
+func listSecrets(ctx context.Context) error {
+	resp, err := secretService.List(ctx, avian.SecretListRequest{})
+	if err != nil {
+		return err
+	}
+
+	var headers table.Row
+	var body []table.Row
+	headers = table.Row{"ID", "Name", "Updated"}
+	for _, s := range resp.Secrets {
+		body = append(body, table.Row{s.ID, s.Name, time.Unix(s.MTime, 0).Format("2006-01-02 15:04:05")})
+	}
+
+	fmt.Println(pretty.Format(headers, body))
+	return nil
+}
+
+func deleteSecret(ctx context.Context, name string) error {
+	if _, err := secretService.Delete(ctx, avian.SecretDeleteRequest{Name: name}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "secret: %s has been deleted\n", name)
+	return nil
+}
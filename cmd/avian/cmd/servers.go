@@ -16,14 +16,16 @@ limitations under the License.
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/avian-digital-forensics/auto-processing/configs"
 	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
 	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
-	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 )
@@ -64,30 +66,67 @@ var serversListCmd = &cobra.Command{
 	},
 }
 
-var srvService *avian.ServerService
+// serversGetCmd represents the get server command
+var serversGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get the specified server (specified by hostname)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := getServer(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not get server from backend: %v\n", err)
+		}
+	},
+}
 
-func init() {
-	address := os.Getenv("AVIAN_ADDRESS")
-	if address == "" {
-		ip, err := utils.GetIPAddress()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Cannot get ip-address: %v", err)
-			os.Exit(1)
+// serversDeleteCmd represents the delete server command
+var serversDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete the specified server (specified by hostname)",
+	Long: `Delete the specified server (specified by hostname). - The server
+can't be deleted while it is used by any waiting or active runners`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := deleteServer(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not delete server from backend: %v\n", err)
 		}
-		address = ip
-	}
+	},
+}
 
-	port := os.Getenv("AVIAN_PORT")
-	if port == "" {
-		port = "8080"
-	}
-	url := fmt.Sprintf("http://%s:%s/oto/", address, port)
+// serversVerifyPasswordCmd represents the verify-password server command
+var serversVerifyPasswordCmd = &cobra.Command{
+	Use:   "verify-password",
+	Short: "Verify the stored password for the specified server (specified by hostname)",
+	Long: `Verify the stored password for the specified server (specified by hostname),
+the password to verify is read from stdin (it requires an admin-token). -
+The stored passwords are never returned by the service. - For example:
+
+	avian servers verify-password dev01 < password.txt`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := verifyServerPassword(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not verify password for server: %v\n", err)
+		}
+	},
+}
 
-	srvService = avian.NewServerService(avian.New(url, "hej"))
+var (
+	srvService     *avian.ServerService
+	srvListRequest avian.ServerListRequest
+)
+
+func init() {
+	srvService = avian.NewServerService(newClient())
 
 	rootCmd.AddCommand(serversCmd)
 	serversCmd.AddCommand(serversApplyCmd)
 	serversCmd.AddCommand(serversListCmd)
+	serversCmd.AddCommand(serversGetCmd)
+	serversCmd.AddCommand(serversDeleteCmd)
+	serversCmd.AddCommand(serversVerifyPasswordCmd)
+	serversListCmd.Flags().StringVar(&srvListRequest.Hostname, "host", "", "only list the servers with hostnames matching the glob-pattern (for example dev*)")
+	serversListCmd.Flags().StringVar(&srvListRequest.Sort, "sort", "", "sort by id, hostname or created (prefix with - for descending order)")
+	serversListCmd.Flags().Int64Var(&srvListRequest.Limit, "limit", 0, "max amount of servers to list (defaults to 100)")
+	serversListCmd.Flags().StringVar(&srvListRequest.PageToken, "page", "", "token for the page to list")
 }
 
 func applyServers(ctx context.Context, path string) error {
@@ -113,22 +152,85 @@ func applyServers(ctx context.Context, path string) error {
 }
 
 func listServers(ctx context.Context) error {
-	resp, err := srvService.List(ctx, avian.ServerListRequest{})
+	resp, err := srvService.List(ctx, srvListRequest)
 	if err != nil {
 		return err
 	}
 
-	var headers table.Row
 	var body []table.Row
-	headers = table.Row{"ID", "Hostname", "Port", "OS", "Nuix-Path", "Status"}
 	for _, s := range resp.Servers {
-		status := "Inactive"
-		if s.Active {
-			status = "Active"
-		}
-		body = append(body, table.Row{s.ID, s.Hostname, s.Port, s.OperatingSystem, s.NuixPath, status})
+		body = append(body, serverRow(s))
+	}
+
+	fmt.Println(pretty.Format(serverHeaders, body))
+	printNextPage(resp.NextPageToken)
+	return nil
+}
+
+func getServer(ctx context.Context, hostname string) error {
+	resp, err := srvService.Get(ctx, avian.ServerGetRequest{Hostname: hostname})
+	if err != nil {
+		return err
 	}
 
-	fmt.Println(pretty.Format(headers, body))
+	fmt.Println(pretty.Format(serverHeaders, []table.Row{serverRow(resp.Server)}))
 	return nil
 }
+
+func deleteServer(ctx context.Context, hostname string) error {
+	if _, err := srvService.Delete(ctx, avian.ServerDeleteRequest{Hostname: hostname}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Server: %s has been deleted\n", hostname)
+	return nil
+}
+
+func verifyServerPassword(ctx context.Context, hostname string) error {
+	password, err := readLine("password")
+	if err != nil {
+		return err
+	}
+
+	resp, err := srvService.VerifyPassword(ctx, avian.ServerVerifyPasswordRequest{Hostname: hostname, Password: password})
+	if err != nil {
+		return err
+	}
+	printMatch("server", hostname, resp.Match)
+	return nil
+}
+
+// readLine reads a value (like a password) from stdin,
+// the prompt is written to stderr
+func readLine(prompt string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("cannot read %s: %v", prompt, err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// printMatch prints if the password matched the stored password
+func printMatch(kind, name string, match bool) {
+	if match {
+		fmt.Fprintf(os.Stdout, "password matches the stored password for %s: %s\n", kind, name)
+		return
+	}
+	fmt.Fprintf(os.Stdout, "password does NOT match the stored password for %s: %s\n", kind, name)
+}
+
+var serverHeaders = table.Row{"ID", "Hostname", "Port", "OS", "Nuix-Path", "Engine", "Status"}
+
+func serverRow(s avian.Server) table.Row {
+	status := "Inactive"
+	if s.Active {
+		status = "Active"
+	}
+
+	engine := s.Engine
+	if engine == "" {
+		engine = "ruby"
+	}
+	return table.Row{s.ID, s.Hostname, s.Port, s.OperatingSystem, s.NuixPath, engine, status}
+}
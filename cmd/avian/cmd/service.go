@@ -16,17 +16,35 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/avian-digital-forensics/auto-processing/cmd/avian/cmd/heartbeat"
 	"github.com/avian-digital-forensics/auto-processing/cmd/avian/cmd/queue"
+	"github.com/avian-digital-forensics/auto-processing/pkg/audit"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/certs"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
 	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/avian-digital-forensics/auto-processing/pkg/health"
 	"github.com/avian-digital-forensics/auto-processing/pkg/logging"
+	"github.com/avian-digital-forensics/auto-processing/pkg/manifest"
+	"github.com/avian-digital-forensics/auto-processing/pkg/metrics"
+	"github.com/avian-digital-forensics/auto-processing/pkg/notify"
+	"github.com/avian-digital-forensics/auto-processing/pkg/powershell"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
 	"github.com/avian-digital-forensics/auto-processing/pkg/services"
 	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
 	"github.com/gorilla/handlers"
@@ -40,7 +58,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/jinzhu/gorm"
-	_ "github.com/jinzhu/gorm/dialects/sqlite"
 )
 
 // serviceCmd represents the service command
@@ -56,16 +73,71 @@ with the backend and the running Nuix-scripts.`,
 	},
 }
 
+// serviceMigrateCmd represents the migrate command
+var serviceMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the database to the latest schema-version",
+	Long: `Migrate applies the pending migrations for the database, the
+service also applies them when it starts. - Try the migrations on
+a copy of the database first:
+
+	avian service migrate --db avian-copy.db --dry-run
+	avian service migrate --db avian-copy.db --to 2`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := migrate(); err != nil {
+			fmt.Fprintf(os.Stderr, "could not migrate database: %v\n", err)
+		}
+	},
+}
+
+// serviceRotateKeyCmd represents the rotate-key command
+var serviceRotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "Re-encrypt the stored credentials with a new master-key",
+	Long: `Rotate-key generates a new master-key and re-encrypts the stored
+credentials for the servers and nm-servers with it. - Stop the service
+before the key is rotated, the new key is written to the key-file:
+
+	avian service rotate-key --master-key-file avian-master.key`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := rotateKey(); err != nil {
+			fmt.Fprintf(os.Stderr, "could not rotate master-key: %v\n", err)
+		}
+	},
+}
+
 // variables from flags
 var (
-	address string // Address for http to listen on
-	port    string // Port for http to listen on
-	debug   bool   // To debug the service
-	dbName  string // name for the SQLite-db
-	logPath string // path for the log-files
-	verbose bool   // Used to log to the console
+	address   string // Address for http to listen on
+	port      string // Port for http to listen on
+	debug     bool   // To debug the service
+	dbName    string // path for the SQLite-db or the DSN
+	dbDriver  string // driver for the db (sqlite3, postgres or mysql)
+	logPath   string // path for the log-files
+	verbose   bool   // Used to log to the console
+	noAuth    bool   // Used to disable the api-tokens
+	tokenFile string // path for the initial api-token
+	keyFile   string // path for the master-key
+	secretDir string // directory for the file-references to the credentials
+	tlsCert   string // path for the tls-certificate
+	tlsKey    string // path for the tls-key
+	tlsCA     string // path for the CA to pin in the runner-scripts
+	tlsClient string // path for the CA to verify the client-certificates with
+	tlsGen    bool   // Used to generate a self-signed certificate
+
+	smtpCfg notify.SMTP // smtp-server for the email-notifications
+	retries int         // attempts to deliver the notifications
+
+	shutdownTimeout time.Duration // timeout for the graceful shutdown
+	commandTimeout  time.Duration // timeout for a command in the shell before it isn't ready
+
+	migrateTo     int  // schema-version to migrate to
+	migrateDryRun bool // only list the pending migrations
 )
 
+// healthTimeout is the timeout for the health-check of the shell
+const healthTimeout = 5 * time.Second
+
 // loggers
 var (
 	accessLogger  *lumberjack.Logger
@@ -74,13 +146,34 @@ var (
 
 func init() {
 	rootCmd.AddCommand(serviceCmd)
+	serviceCmd.AddCommand(serviceRotateKeyCmd)
+	serviceCmd.AddCommand(serviceMigrateCmd)
+	serviceMigrateCmd.Flags().IntVar(&migrateTo, "to", 0, "schema-version to migrate to (defaults to the latest)")
+	serviceMigrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "only list the pending migrations")
 
 	serviceCmd.Flags().StringVar(&address, "address", "0.0.0.0", "address to listen on")
 	serviceCmd.Flags().StringVar(&port, "port", "8080", "port for HTTP to listen on")
 	serviceCmd.Flags().BoolVar(&debug, "debug", false, "for debugging")
-	serviceCmd.Flags().StringVar(&dbName, "db", "avian.db", "path to sqlite database")
+	serviceCmd.PersistentFlags().StringVar(&dbName, "db", "avian.db", "path to the sqlite-database or the DSN for postgres and mysql (or set AVIAN_DB_DSN)")
+	serviceCmd.PersistentFlags().StringVar(&dbDriver, "db-driver", datastore.DriverSQLite, "driver for the database ("+strings.Join(datastore.Drivers, ", ")+")")
+	serviceCmd.PersistentFlags().StringVar(&keyFile, "master-key-file", "avian-master.key", "path to the master-key for the stored credentials (or set "+secrets.EnvKey+")")
+	serviceCmd.Flags().StringVar(&secretDir, "secrets-dir", secrets.DefaultDir, "directory for the files that the credentials can reference with file:<path>")
 	serviceCmd.Flags().StringVar(&logPath, "log-path", "./log/", "path to log-files")
 	serviceCmd.Flags().BoolVar(&verbose, "verbose", false, "for logging to the console")
+	serviceCmd.Flags().BoolVar(&noAuth, "no-auth", false, "disable the verification of the api-tokens")
+	serviceCmd.Flags().StringVar(&tokenFile, "token-file", "avian.token", "path to write the initial api-token to")
+	serviceCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "path to the tls-certificate (enables https)")
+	serviceCmd.Flags().StringVar(&tlsKey, "tls-key", "", "path to the tls-key")
+	serviceCmd.Flags().StringVar(&tlsCA, "tls-ca", "", "path to the CA to pin in the runner-scripts (defaults to the tls-certificate)")
+	serviceCmd.Flags().StringVar(&tlsClient, "tls-client-ca", "", "path to the CA to verify the client-certificates with (mutual TLS)")
+	serviceCmd.Flags().BoolVar(&tlsGen, "tls-generate", false, "generate a self-signed certificate if the tls-certificate doesn't exist")
+	serviceCmd.Flags().StringVar(&smtpCfg.Address, "smtp-address", "", "address (host:port) to the smtp-server for the email-notifications")
+	serviceCmd.Flags().StringVar(&smtpCfg.Username, "smtp-username", "", "username for the smtp-server")
+	serviceCmd.Flags().StringVar(&smtpCfg.Password, "smtp-password", "", "password for the smtp-server (or set AVIAN_SMTP_PASSWORD)")
+	serviceCmd.Flags().StringVar(&smtpCfg.From, "smtp-from", "avian@localhost", "sender of the email-notifications")
+	serviceCmd.Flags().IntVar(&retries, "notify-retries", notify.DefaultRetries, "attempts to deliver the notifications")
+	serviceCmd.Flags().DurationVar(&commandTimeout, "shell-command-timeout", time.Hour, "how long a command can execute in the powershell-process before the service isn't ready")
+	serviceCmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "timeout for the requests, queue and heartbeat to finish when the service shuts down")
 }
 
 func run() error {
@@ -107,7 +200,7 @@ func run() error {
 	logger := zap.New(core, zap.Option(zap.WithCaller(debug)))
 	defer logger.Sync()
 
-	logger.Debug("Starting service", zap.Bool("debug", debug), zap.String("db", dbName), zap.String("log-path", logPath))
+	logger.Debug("Starting service", zap.Bool("debug", debug), zap.String("db-driver", dbDriver), zap.String("log-path", logPath))
 
 	logHandler := logging.New(logPath)
 	go func() {
@@ -138,42 +231,79 @@ func run() error {
 	}
 
 	// Connect to the database
-	logger.Info("Connecting to database")
-	db, err := gorm.Open("sqlite3", dbName)
+	logger.Info("Connecting to database", zap.String("driver", dbDriver))
+	db, err := openDB()
 	if err != nil {
-		return fmt.Errorf("failed to connect database : %v", err)
+		return err
 	}
 
 	// If debug is true, enable logmode
 	db.LogMode(debug)
 
-	// Migrate the db-tables
-	logger.Info("Migrating db-tables")
-	if err := tables.Migrate(db); err != nil {
+	// Migrate the db-tables to the latest schema-version
+	logger.Info("Migrating db-tables", zap.Int("latest", tables.Latest()))
+	applied, err := tables.MigrateTo(db, 0)
+	for _, m := range applied {
+		logger.Info("Applied migration", zap.Int("version", m.Version), zap.String("description", m.Description))
+	}
+	if err != nil {
 		return err
 	}
 
-	// Index the db-tables
-	logger.Info("Creating db-indexes")
-	if err := tables.Index(db); err != nil {
+	// Encrypt the stored credentials with the master-key
+	cipher, err := masterKey(db, logger)
+	if err != nil {
 		return err
 	}
+	secrets.Restrict(secretDir, keyFile)
+	encrypted, err := secrets.EncryptStored(db, cipher)
+	if err != nil {
+		return fmt.Errorf("cannot encrypt the stored credentials: %v", err)
+	}
+	if encrypted != 0 {
+		logger.Info("Encrypted the stored credentials", zap.Int("amount", encrypted))
+	}
 
 	// Create a powershell-shell for remote connections
 	logger.Info("Creating powershell-process for remote-connections")
-	shell, err := ps.New(&backend.Local{})
+	process, err := ps.New(&backend.Local{})
 	if err != nil {
 		return fmt.Errorf("unable to create powershell-process : %v", err)
 	}
+	shell := powershell.NewShell(process)
+
+	// Load the certificates for TLS
+	scheme := "http"
+	ca, tlsConfig, err := loadTLS(logger)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		scheme = "https"
+	}
 
 	// start the queue
 	logger.Info("Starting queue-service")
+	uri := fmt.Sprintf("%s://%s:%s/oto/", scheme, address, port)
+	// the queue, the heartbeat and the manifests that are recorded
+	// in the background are waited for when the service shuts down
+	var loops sync.WaitGroup
+	manifests := manifest.NewBuilder(db, func() (ps.Shell, error) { return ps.New(&backend.Local{}) }, cipher, logger, &loops)
 	queue := queue.New(db,
 		shell,
-		fmt.Sprintf("http://%s:%s/oto/", address, port),
+		uri,
+		ca,
 		logger,
+		cipher,
+		manifests,
 	)
-	go queue.Start()
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	loops.Add(1)
+	go func() {
+		defer loops.Done()
+		queue.Start(ctx)
+	}()
 
 	// Create a oto-server
 	logger.Debug("Creating oto http-server")
@@ -181,21 +311,86 @@ func run() error {
 
 	// Register our services
 	logger.Debug("Registering our oto http-services")
-	runnersvc := services.NewRunnerService(db, shell, logger, logHandler)
+	broker := events.NewBroker()
+	if smtpCfg.Password == "" {
+		smtpCfg.Password = os.Getenv("AVIAN_SMTP_PASSWORD")
+	}
+	notifier := notify.New(db, smtpCfg, logger)
+	notifier.Retries = retries
+	if err := notifier.Resume(); err != nil {
+		logger.Error("Cannot resume the pending deliveries", zap.String("exception", err.Error()))
+	}
+	m, err := metrics.New(db, logger)
+	if err != nil {
+		return fmt.Errorf("cannot register metrics: %v", err)
+	}
+	runnersvc := services.NewRunnerService(db, shell, uri, ca, logger, logHandler, broker, notifier, cipher, m, manifests)
 	api.RegisterRunnerService(server, runnersvc)
-	api.RegisterServerService(server, services.NewServerService(db, shell, logger))
-	api.RegisterNmsService(server, services.NewNmsService(db, logger))
+	api.RegisterServerService(server, services.NewServerService(db, shell, logger, cipher))
+	api.RegisterNmsService(server, services.NewNmsService(db, logger, cipher))
+	api.RegisterTokenService(server, services.NewTokenService(db, logger, cipher))
+	api.RegisterNotificationService(server, services.NewNotificationService(db, logger))
+	api.RegisterSecretService(server, services.NewSecretService(db, logger, cipher))
+	api.RegisterAuditService(server, services.NewAuditService(db, logger))
 
 	logger.Debug("Starting heartbeat-service")
-	heartbeat := heartbeat.New(runnersvc, logger)
-	go heartbeat.Beat()
+	heartbeat := heartbeat.New(runnersvc, m, logger)
+	loops.Add(1)
+	go func() {
+		defer loops.Done()
+		heartbeat.Beat(ctx)
+	}()
 
-	// Handle our oto-server @ /oto
+	// Handle our oto-server @ /oto, the administrative
+	// requests are recorded in the audit-log
 	logger.Debug("Handle oto @ /oto/")
-	http.Handle("/oto/", server)
+	server.OnErr = audit.OnErr(server.OnErr)
+	mux := http.NewServeMux()
+	mux.Handle("/oto/", m.Instrument(audit.Handler(db, logger, server)))
+
+	// Handle the event-stream @ /events
+	logger.Debug("Handle event-stream @ /events")
+	mux.Handle("/events", events.Handler(broker, logger))
+
+	// Verify the api-tokens for the requests
+	var handler http.Handler = mux
+	if noAuth {
+		logger.Warn("Verification of the api-tokens is disabled")
+	} else {
+		if err := initialToken(db, cipher, logger); err != nil {
+			return err
+		}
+		handler = auth.Handler(db, cipher, logger, mux)
+	}
+
+	// Handle the metrics @ /metrics, the scrapers
+	// can't sign the requests with an api-token
+	logger.Debug("Handle metrics @ /metrics")
+	public := http.NewServeMux()
+	public.Handle("/metrics", m.Handler())
+	public.Handle("/", handler)
+	handler = public
+
+	// Require client-certificates for mutual TLS
+	if tlsClient != "" {
+		handler = auth.RequireClientCert(logger, handler)
+	}
+
+	// Handle the health-checks @ /healthz and /readyz,
+	// they don't require any tokens or certificates
+	logger.Debug("Handle health-checks @ /healthz and /readyz")
+	checker := health.New()
+	checker.Live("queue", queue.Check)
+	checker.Ready("db", db.DB().Ping)
+	checker.Ready("shell", func() error { return shell.Alive(healthTimeout, commandTimeout) })
+	checks := http.NewServeMux()
+	checks.Handle("/healthz", checker.Healthz())
+	checks.Handle("/readyz", checker.Readyz())
+	checks.Handle("/", handler)
+	handler = checks
 
 	// Wrap the http-server with the accesslogger
-	loggedServer := handlers.LoggingHandler(accessLogger, server)
+	loggedServer := handlers.LoggingHandler(accessLogger, handler)
 
 	// Create our CORS-handlers
 	corsOrigins := handlers.AllowedOrigins([]string{"*"})
@@ -205,29 +400,303 @@ func run() error {
 		"Authorization",
 		"Content-Type",
 		"User-Agent",
+		auth.HeaderKey,
+		auth.HeaderSignature,
+		auth.HeaderTimestamp,
+		auth.HeaderNonce,
 	})
 
 	// Create our HTTP-server
 	srv := &http.Server{
-		Handler: handlers.CORS(corsOrigins, corsMethods, corsHeaders)(loggedServer),
-		Addr:    fmt.Sprintf("%s:%s", address, port),
+		Handler:   handlers.CORS(corsOrigins, corsMethods, corsHeaders)(loggedServer),
+		Addr:      fmt.Sprintf("%s:%s", address, port),
+		TLSConfig: tlsConfig,
 		// Good practice: enforce timeouts for servers you create!
 		WriteTimeout: 15 * time.Second,
 		ReadTimeout:  15 * time.Second,
 	}
 
-	logger.Info("http-service listening", zap.String("address", address), zap.String("port", port))
+	logger.Info("http-service listening", zap.String("address", address), zap.String("port", port), zap.String("scheme", scheme))
 	if !verbose {
-		log.Printf("http-service listening @ %s:%s", address, port)
+		log.Printf("%s-service listening @ %s:%s", scheme, address, port)
 	}
 
-	if err := srv.ListenAndServe(); err != nil {
+	listen := srv.ListenAndServe
+	if tlsConfig != nil {
+		// the certificates are set in the tls-config
+		listen = func() error { return srv.ListenAndServeTLS("", "") }
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- listen() }()
+
+	// Shut down gracefully on SIGINT or SIGTERM
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-errc:
 		logger.Error("cannot start http-server", zap.String("address", address), zap.String("port", port), zap.String("exception", err.Error()))
 		return err
+	case sig := <-signals:
+		logger.Info("Shutting down service", zap.String("signal", sig.String()))
+	}
+
+	// Stop accepting work, the service isn't ready while it is shutting down
+	checker.Stop()
+	stop()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Cannot shut down http-server", zap.String("exception", err.Error()))
+	}
+
+	// Let the queue and heartbeat finish their current pass
+	// and the manifests that are recorded in the background
+	done := make(chan struct{})
+	go func() {
+		loops.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		logger.Warn("Timed out waiting for the queue, heartbeat and manifests")
+	}
+
+	logger.Info("Closing powershell-sessions")
+	queue.Close()
+	shell.Exit()
+
+	// Let the deliveries finish their current attempt, the
+	// pending deliveries are resumed when the service starts
+	logger.Info("Waiting for the deliveries of the notifications")
+	notifier.Close()
+	if err := notifier.Wait(shutdownCtx); err != nil {
+		logger.Warn("Timed out waiting for the deliveries", zap.String("exception", err.Error()))
+	}
+
+	logger.Info("Closing database")
+	if err := db.Close(); err != nil {
+		logger.Error("Cannot close database", zap.String("exception", err.Error()))
+	}
+
+	logger.Info("Service has been shut down")
+	logger.Sync()
+	accessLogger.Close()
+	serviceLogger.Close()
+	return nil
+}
+
+// loadTLS returns the CA (pem) to pin in the runner-scripts and the
+// tls-config for the service, the config is nil if TLS isn't enabled
+func loadTLS(logger *zap.Logger) (string, *tls.Config, error) {
+	if tlsGen && tlsCert == "" {
+		tlsCert, tlsKey = "avian.crt", "avian.key"
+	}
+
+	if tlsCert == "" {
+		if tlsClient != "" {
+			return "", nil, fmt.Errorf("--tls-client-ca requires --tls-cert or --tls-generate")
+		}
+		logger.Warn("TLS is not enabled - the requests are sent in cleartext")
+		return "", nil, nil
+	}
+
+	if tlsKey == "" {
+		return "", nil, fmt.Errorf("--tls-key must be specified with --tls-cert")
+	}
+
+	// Generate a self-signed certificate on the first start
+	if tlsGen && !certs.Exists(tlsCert) {
+		hostname, _ := os.Hostname()
+		logger.Info("Generating self-signed certificate", zap.String("cert", tlsCert), zap.String("key", tlsKey))
+		if err := certs.Generate(tlsCert, tlsKey, []string{address, hostname, "localhost", "127.0.0.1"}); err != nil {
+			return "", nil, err
+		}
+	}
+
+	config, err := certs.ServerConfig(tlsCert, tlsKey, tlsClient)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if tlsCA == "" {
+		tlsCA = tlsCert
+	}
+	ca, err := ioutil.ReadFile(tlsCA)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot read the CA for the runner-scripts: %v", err)
+	}
+
+	logger.Info("TLS is enabled", zap.String("cert", tlsCert), zap.String("ca", tlsCA), zap.Bool("mutual", tlsClient != ""))
+	return string(ca), config, nil
+}
+
+// initialToken writes an api-token to the token-file
+// if the service doesn't have any tokens for the clients
+func initialToken(db *gorm.DB, cipher *secrets.Cipher, logger *zap.Logger) error {
+	token, err := auth.Initial(db, cipher)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return nil
+	}
+
+	if err := ioutil.WriteFile(tokenFile, []byte(token+"\n"), 0600); err != nil {
+		return fmt.Errorf("cannot write the initial api-token: %v", err)
+	}
+	logger.Info("Created initial api-token", zap.String("path", tokenFile))
+	log.Printf("initial api-token written to: %s", tokenFile)
+	return nil
+}
+
+// masterKey returns the cipher for the master-key, the key-file
+// is generated if there isn't any master-key - unless the db
+// already has credentials that are encrypted with a master-key
+func masterKey(db *gorm.DB, logger *zap.Logger) (*secrets.Cipher, error) {
+	key, err := secrets.LoadKey(keyFile)
+	if os.IsNotExist(err) {
+		encrypted, err := secrets.CountEncrypted(db)
+		if err != nil {
+			return nil, err
+		}
+		if encrypted != 0 {
+			return nil, fmt.Errorf("the master-key (%s) is missing, but %d stored credentials are encrypted with a master-key - restore the key-file or set %s", keyFile, encrypted, secrets.EnvKey)
+		}
+		if key, err = secrets.NewKey(); err != nil {
+			return nil, err
+		}
+		if err := secrets.WriteKey(keyFile, key); err != nil {
+			return nil, err
+		}
+		logger.Warn("Generated a new master-key - keep a backup of it, the stored credentials can't be decrypted without it", zap.String("path", keyFile))
+		log.Printf("master-key written to: %s", keyFile)
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read master-key: %v", err)
+	}
+
+	cipher, err := secrets.New(key)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Loaded master-key", zap.String("id", cipher.ID()))
+	return cipher, nil
+}
+
+// rotateKey re-encrypts the stored credentials with a new master-key,
+// the new key is written to the key-file when the credentials are
+// re-encrypted (it is kept in <key-file>.new until then)
+func rotateKey() error {
+	key, err := secrets.LoadKey(keyFile)
+	if err != nil {
+		return fmt.Errorf("cannot read the current master-key: %v", err)
+	}
+	current, err := secrets.New(key)
+	if err != nil {
+		return err
+	}
+
+	newKey, err := secrets.NewKey()
+	if err != nil {
+		return err
+	}
+	next, err := secrets.New(newKey)
+	if err != nil {
+		return err
+	}
+	pending := keyFile + ".new"
+	if err := secrets.WriteKey(pending, newKey); err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		os.Remove(pending)
+		return err
+	}
+	defer db.Close()
+	if err := tables.Migrate(db); err != nil {
+		os.Remove(pending)
+		return err
+	}
+
+	rotated, err := secrets.Rotate(db, current, next)
+	if err != nil {
+		os.Remove(pending)
+		return err
+	}
+	if err := os.Rename(pending, keyFile); err != nil {
+		return fmt.Errorf("the credentials are encrypted with the new master-key in %s, but it couldn't be moved to %s: %v", pending, keyFile, err)
+	}
+
+	fmt.Fprintf(os.Stdout, "re-encrypted %d credentials with master-key: %s (previous: %s)\n", rotated, next.ID(), current.ID())
+	if os.Getenv(secrets.EnvKey) != "" {
+		fmt.Fprintf(os.Stdout, "the new master-key is written to %s - update %s with it before the service is started\n", keyFile, secrets.EnvKey)
 	}
 	return nil
 }
 
+// migrate applies (or lists) the pending migrations for the db
+func migrate() error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	current, err := tables.Version(db)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "schema-version: %d (latest: %d)\n", current, tables.Latest())
+
+	if migrateDryRun {
+		pending, err := tables.Pending(db, migrateTo)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			fmt.Fprintln(os.Stdout, "no pending migrations")
+		}
+		for _, m := range pending {
+			fmt.Fprintf(os.Stdout, "pending: %d - %s\n", m.Version, m.Description)
+		}
+		return nil
+	}
+
+	applied, err := tables.MigrateTo(db, migrateTo)
+	for _, m := range applied {
+		fmt.Fprintf(os.Stdout, "applied: %d - %s\n", m.Version, m.Description)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Fprintln(os.Stdout, "no pending migrations")
+	}
+	return nil
+}
+
+// openDB opens the db with the driver and the dsn, the dsn is read
+// from AVIAN_DB_DSN if it is set (to keep the password off the cli)
+func openDB() (*gorm.DB, error) {
+	db, err := datastore.Open(dbDriver, dbDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect database : %v", err)
+	}
+	return db, nil
+}
+
+// dbDSN returns the dsn for the db, it is
+// read from AVIAN_DB_DSN if it is set
+func dbDSN() string {
+	if env := os.Getenv("AVIAN_DB_DSN"); env != "" {
+		return env
+	}
+	return dbName
+}
+
 func setLoggers() error {
 	// Create log-path
 	if _, err := os.Stat(logPath); os.IsNotExist(err) {
@@ -0,0 +1,139 @@
+/*
+Copyright © 2020 Avian Digital Forensics <sja@avian.dk>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/pretty"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// tokensCmd represents the tokens command
+var tokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "Api-tokens for the service",
+	Long: `Tokens handles the api-tokens for the clients of the service.
+
+The cli reads its api-token from the env-variable AVIAN_TOKEN
+or from the config-file (~/.avian/config.yml or AVIAN_CONFIG):
+
+	token: <key.secret>`,
+}
+
+// tokensCreateCmd represents the create tokens command
+var tokensCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a new api-token with the specified name",
+	Long: `Create a new api-token with the specified name, use --admin for
+an admin-token (the admin-tokens can verify the stored passwords, create
+other admin-tokens and list and revoke the tokens). - For example:
+
+	avian tokens create ci --expires 720h`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := createToken(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not create token: %v\n", err)
+		}
+	},
+}
+
+// tokensListCmd represents the list tokens command
+var tokensListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the api-tokens (requires an admin-token)",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listTokens(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "could not list tokens from backend: %v\n", err)
+		}
+	},
+}
+
+// tokensDeleteCmd represents the delete tokens command
+var tokensDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Revoke the api-token with the specified key (requires an admin-token)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := deleteToken(context.Background(), args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not revoke token: %v\n", err)
+		}
+	},
+}
+
+var tokenService *avian.TokenService
+
+// tokenExpires is the duration the created token is valid for
+var tokenExpires string
+
+// tokenAdmin - if the created token should be an admin-token
+var tokenAdmin bool
+
+func init() {
+	tokenService = avian.NewTokenService(newClient())
+
+	rootCmd.AddCommand(tokensCmd)
+	tokensCmd.AddCommand(tokensCreateCmd)
+	tokensCmd.AddCommand(tokensListCmd)
+	tokensCmd.AddCommand(tokensDeleteCmd)
+	tokensCreateCmd.Flags().StringVar(&tokenExpires, "expires", "", "duration the token is valid for (for example 720h), never expires if empty")
+	tokensCreateCmd.Flags().BoolVar(&tokenAdmin, "admin", false, "create an admin-token (requires an admin-token)")
+}
+
+func createToken(ctx context.Context, name string) error {
+	resp, err := tokenService.Create(ctx, avian.TokenCreateRequest{Name: name, Expires: tokenExpires, Admin: tokenAdmin})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "token: %s has been created, it will not be shown again\n%s\n", resp.Token.Name, resp.Value)
+	return nil
+}
+
+func listTokens(ctx context.Context) error {
+	resp, err := tokenService.List(ctx, avian.TokenListRequest{})
+	if err != nil {
+		return err
+	}
+
+	var headers table.Row
+	var body []table.Row
+	headers = table.Row{"ID", "Name", "Key", "Runner", "Admin", "Expires"}
+	for _, t := range resp.Tokens {
+		expires := "Never"
+		if t.ExpiresAt != 0 {
+			expires = time.Unix(t.ExpiresAt, 0).Format("2006-01-02 15:04:05")
+		}
+		body = append(body, table.Row{t.ID, t.Name, t.Key, t.Runner, t.Admin, expires})
+	}
+
+	fmt.Println(pretty.Format(headers, body))
+	return nil
+}
+
+func deleteToken(ctx context.Context, key string) error {
+	if _, err := tokenService.Delete(ctx, avian.TokenDeleteRequest{Key: key}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "token: %s has been revoked\n", key)
+	return nil
+}
@@ -15,9 +15,10 @@ type Config struct {
 }
 
 type API struct {
-	Servers []Servers                `yaml:"servers"`
-	Nms     avian.NmsApplyRequests   `yaml:"nmsApply"`
-	Runner  avian.RunnerApplyRequest `yaml:"runner"`
+	Servers       []Servers                       `yaml:"servers"`
+	Nms           avian.NmsApplyRequests          `yaml:"nmsApply"`
+	Runner        avian.RunnerApplyRequest        `yaml:"runner"`
+	Notifications avian.NotificationApplyRequests `yaml:"notificationApply"`
 }
 
 type Servers struct {
@@ -10,6 +10,18 @@ import (
 type ServerService interface {
 	Apply(ServerApplyRequest) ServerApplyResponse
 	List(ServerListRequest) ServerListResponse
+
+	// Get returns the requested server
+	Get(ServerGetRequest) ServerGetResponse
+
+	// Delete deletes the requested server, unless it
+	// is used by any waiting or active runners
+	Delete(ServerDeleteRequest) ServerDeleteResponse
+
+	// VerifyPassword verifies the stored password for the
+	// server, the password is never returned by the service
+	// (it requires an admin-token)
+	VerifyPassword(ServerVerifyPasswordRequest) ServerVerifyPasswordResponse
 }
 
 // Server is the main-struct for the
@@ -30,12 +42,18 @@ type Server struct {
 	// Username for connection to the server
 	Username string
 
-	// Password for connection to the server
+	// Password for connection to the server, it is
+	// write-only and masked in the responses - or a
+	// reference (env:<name>, file:<path> or secret:<name>)
 	Password string
 
 	// NuixPath to know where to run Nuix
 	NuixPath string
 
+	// Engine for the scripts of the runners
+	// on the server (ruby or python)
+	Engine string
+
 	// Active - if the server has an active job
 	Active bool
 }
@@ -49,6 +67,7 @@ type ServerApplyRequest struct {
 	Username        string
 	Password        string
 	NuixPath        string
+	Engine          string
 }
 
 // ServerApplyResponse is the output-object
@@ -59,12 +78,73 @@ type ServerApplyResponse struct {
 
 // ServerListRequest is the input-object
 // for List in the server-service
-type ServerListRequest struct{}
+type ServerListRequest struct {
+	// Hostname to filter the servers with,
+	// a glob-pattern (for example dev*)
+	Hostname string
+
+	// Sort is the field to sort by (id, hostname or created),
+	// prefixed with - for descending order
+	Sort string
+
+	// Limit is the max amount of servers
+	// to return (defaults to 100)
+	Limit int64
+
+	// PageToken is the token for the page to return,
+	// from NextPageToken of the previous page
+	PageToken string
+}
 
 // ServerListResponse is the output-object
 // for List in the server-service
 type ServerListResponse struct {
 	Servers []Server
+
+	// NextPageToken is the token for the next page,
+	// empty if it is the last page
+	NextPageToken string
+}
+
+// ServerGetRequest is the input-object
+// for Get in the server-service
+type ServerGetRequest struct {
+	// Hostname of the server
+	Hostname string
+}
+
+// ServerGetResponse is the output-object
+// for Get in the server-service
+type ServerGetResponse struct {
+	Server Server
+}
+
+// ServerDeleteRequest is the input-object
+// for Delete in the server-service
+type ServerDeleteRequest struct {
+	// Hostname of the server
+	Hostname string
+}
+
+// ServerDeleteResponse is the output-object
+// for Delete in the server-service
+type ServerDeleteResponse struct{}
+
+// ServerVerifyPasswordRequest is the input-object
+// for VerifyPassword in the server-service
+type ServerVerifyPasswordRequest struct {
+	// Hostname of the server
+	Hostname string
+
+	// Password to verify
+	Password string
+}
+
+// ServerVerifyPasswordResponse is the output-object
+// for VerifyPassword in the server-service
+type ServerVerifyPasswordResponse struct {
+	// Match - if the password matches the stored password
+	Match bool
 }
 
 // NmsService handles the Nuix Management Servers
@@ -72,6 +152,18 @@ type NmsService interface {
 	Apply(NmsApplyRequests) NmsApplyResponse
 	List(NmsListRequest) NmsListResponse
 	ListLicences(NmsListLicencesRequest) NmsListLicencesResponse
+
+	// Get returns the requested nms-server
+	Get(NmsGetRequest) NmsGetResponse
+
+	// Delete deletes the requested nms-server, unless
+	// it is used by any waiting or active runners
+	Delete(NmsDeleteRequest) NmsDeleteResponse
+
+	// VerifyPassword verifies the stored password for the
+	// nms-server, the password is never returned by the service
+	// (it requires an admin-token)
+	VerifyPassword(NmsVerifyPasswordRequest) NmsVerifyPasswordResponse
 }
 
 // Nms is the main struct for the Nuix Management Servers
@@ -89,7 +181,9 @@ type Nms struct {
 	// Username for the nms-server
 	Username string
 
-	// Password for the nms-server
+	// Password for the nms-server, it is
+	// write-only and masked in the responses - or a
+	// reference (env:<name>, file:<path> or secret:<name>)
 	Password string
 
 	// amount of workers licensed
@@ -154,7 +248,8 @@ type NmsApplyRequest struct {
 	// Username for the nms-server
 	Username string
 
-	// Password for the nms-server
+	// Password for the nms-server - or a reference
+	// (env:<name>, file:<path> or secret:<name>)
 	Password string
 
 	// amount of workers licensed
@@ -173,12 +268,32 @@ type NmsApplyResponse struct {
 
 // NmsListRequest is the input-object for
 // List in the NMS-service
-type NmsListRequest struct{}
+type NmsListRequest struct {
+	// Address to filter the nms-servers with,
+	// a glob-pattern (for example *.avian.dk)
+	Address string
+
+	// Sort is the field to sort by (id, address or created),
+	// prefixed with - for descending order
+	Sort string
+
+	// Limit is the max amount of nms-servers
+	// to return (defaults to 100)
+	Limit int64
+
+	// PageToken is the token for the page to return,
+	// from NextPageToken of the previous page
+	PageToken string
+}
 
 // NmsListResponse is the output-object for
 // List in the NMS-service
 type NmsListResponse struct {
 	Nms []Nms
+
+	// NextPageToken is the token for the next page,
+	// empty if it is the last page
+	NextPageToken string
 }
 
 // NmsListLicencesRequest is the input-object for
@@ -187,6 +302,10 @@ type NmsListLicencesRequest struct {
 	// ID for the nms-server
 	// to list the licences for
 	NmsID uint
+
+	// Address for the nms-server to list
+	// the licences for (if NmsID isn't set)
+	Address string
 }
 
 // NmsListLicencesResponse is the output-object for
@@ -195,6 +314,450 @@ type NmsListLicencesResponse struct {
 	Licences []Licence
 }
 
+// NmsGetRequest is the input-object
+// for Get in the NMS-service
+type NmsGetRequest struct {
+	// Address of the nms-server
+	Address string
+}
+
+// NmsGetResponse is the output-object
+// for Get in the NMS-service
+type NmsGetResponse struct {
+	Nms Nms
+}
+
+// NmsDeleteRequest is the input-object
+// for Delete in the NMS-service
+type NmsDeleteRequest struct {
+	// Address of the nms-server
+	Address string
+}
+
+// NmsDeleteResponse is the output-object
+// for Delete in the NMS-service
+type NmsDeleteResponse struct{}
+
+// NmsVerifyPasswordRequest is the input-object
+// for VerifyPassword in the NMS-service
+type NmsVerifyPasswordRequest struct {
+	// Address of the nms-server
+	Address string
+
+	// Password to verify
+	Password string
+}
+
+// NmsVerifyPasswordResponse is the output-object
+// for VerifyPassword in the NMS-service
+type NmsVerifyPasswordResponse struct {
+	// Match - if the password matches the stored password
+	Match bool
+}
+
+// NotificationService handles the notification-rules
+// for the runners and the log of the deliveries
+type NotificationService interface {
+	// Apply creates or updates the notification-rules
+	Apply(NotificationApplyRequests) NotificationApplyResponse
+
+	// List returns the notification-rules
+	List(NotificationListRequest) NotificationListResponse
+
+	// Delete deletes the requested notification-rule
+	Delete(NotificationDeleteRequest) NotificationDeleteResponse
+
+	// Deliveries returns the log of the deliveries
+	Deliveries(NotificationDeliveriesRequest) NotificationDeliveriesResponse
+}
+
+// Notification is a rule for notifying
+// when something happens with a runner
+type Notification struct {
+	// Base for the datastore
+	datastore.Base
+
+	// Name of the notification-rule
+	Name string
+
+	// Runner the rule is for,
+	// empty if it is for all runners
+	Runner string
+
+	// Triggers for the notification separated by comma
+	// (finish, failure, timeout and stage-failure)
+	Triggers string
+
+	// Type of the notification (webhook or email)
+	Type string
+
+	// Endpoint is the url to post the webhook to
+	Endpoint string
+
+	// Body is the template for the json-body of the webhook,
+	// a default body is posted if it is empty
+	Body string
+
+	// Recipients are the email-addresses
+	// to notify, separated by comma
+	Recipients string
+
+	// Subject is the template for the subject of the email,
+	// a default subject is used if it is empty
+	Subject string
+}
+
+// NotificationApplyRequests is the input-object
+// for Apply in the notification-service
+type NotificationApplyRequests struct {
+	Notifications []NotificationApplyRequest
+}
+
+// NotificationApplyRequest is the input-object
+// for applying a notification-rule
+type NotificationApplyRequest struct {
+	// Name of the notification-rule
+	Name string
+
+	// Runner the rule is for,
+	// empty if it is for all runners
+	Runner string
+
+	// Triggers for the notification
+	// (finish, failure, timeout and stage-failure)
+	Triggers []string
+
+	// Type of the notification (webhook or email)
+	Type string
+
+	// Endpoint is the url to post the webhook to
+	Endpoint string
+
+	// Body is the template for the json-body of the webhook
+	Body string
+
+	// Recipients are the email-addresses to notify
+	Recipients []string
+
+	// Subject is the template for the subject of the email
+	Subject string
+}
+
+// NotificationApplyResponse is the output-object
+// for Apply in the notification-service
+type NotificationApplyResponse struct {
+	Notifications []Notification
+}
+
+// NotificationListRequest is the input-object
+// for List in the notification-service
+type NotificationListRequest struct{}
+
+// NotificationListResponse is the output-object
+// for List in the notification-service
+type NotificationListResponse struct {
+	Notifications []Notification
+}
+
+// NotificationDeleteRequest is the input-object
+// for Delete in the notification-service
+type NotificationDeleteRequest struct {
+	// Name of the notification-rule
+	Name string
+}
+
+// NotificationDeleteResponse is the output-object
+// for Delete in the notification-service
+type NotificationDeleteResponse struct{}
+
+// Delivery is a delivery of a notification
+type Delivery struct {
+	// Base for the datastore
+	datastore.Base
+
+	// NotificationID and Notification (name)
+	// for the rule that was delivered
+	NotificationID uint
+	Notification   string
+
+	// Runner and Trigger for the delivery
+	Runner  string
+	Trigger string
+
+	// Status of the delivery (pending, delivered or failed)
+	Status string
+
+	// Attempts to deliver the notification
+	Attempts int64
+
+	// LastError is the error from the last failed attempt
+	LastError string
+
+	// DeliveredAt is when the notification
+	// was delivered (unix-time)
+	DeliveredAt int64
+
+	// Event that is notified (json), the pending
+	// deliveries are resumed with it on startup
+	Event string
+}
+
+// NotificationDeliveriesRequest is the input-object
+// for Deliveries in the notification-service
+type NotificationDeliveriesRequest struct {
+	// Notification (name) to filter the deliveries with
+	Notification string
+
+	// Runner to filter the deliveries with
+	Runner string
+
+	// Status to filter the deliveries with
+	// (pending, delivered or failed)
+	Status string
+
+	// Limit is the max amount of deliveries
+	// to return (defaults to 100)
+	Limit int64
+
+	// PageToken is the token for the page to return,
+	// from NextPageToken of the previous page
+	PageToken string
+}
+
+// NotificationDeliveriesResponse is the output-object
+// for Deliveries in the notification-service
+type NotificationDeliveriesResponse struct {
+	// Deliveries with the latest first
+	Deliveries []Delivery
+
+	// NextPageToken is the token for the next page,
+	// empty if it is the last page
+	NextPageToken string
+}
+
+// TokenService handles the api-tokens
+type TokenService interface {
+	// Create creates a new api-token
+	Create(TokenCreateRequest) TokenCreateResponse
+
+	// List returns the api-tokens
+	List(TokenListRequest) TokenListResponse
+
+	// Delete revokes the requested api-token
+	Delete(TokenDeleteRequest) TokenDeleteResponse
+}
+
+// Token is an api-token for the clients
+// of the service
+type Token struct {
+	// Base for the datastore
+	datastore.Base
+
+	// Name describes what the token is used for
+	Name string
+
+	// Key identifies the token in the requests
+	Key string
+
+	// Secret to verify the signatures
+	// of the requests with
+	Secret string
+
+	// RunnerID is set for the tokens of the runner-scripts,
+	// these tokens are only valid for the callbacks
+	// of the runner
+	RunnerID uint
+
+	// Runner the token is valid for
+	Runner string
+
+	// ExpiresAt is when the token expires (unix-time),
+	// zero if the token never expires
+	ExpiresAt int64
+
+	// Admin is set for the tokens that can verify the
+	// stored passwords and create other admin-tokens
+	Admin bool
+}
+
+// TokenCreateRequest is the input-object
+// for creating an api-token
+type TokenCreateRequest struct {
+	// Name describes what the token is used for
+	Name string
+
+	// Expires is the duration (for example 720h)
+	// the token is valid for, empty if it never expires
+	Expires string
+
+	// Admin creates an admin-token, the token
+	// for the request must be an admin-token
+	Admin bool
+}
+
+// TokenCreateResponse is the output-object
+// for creating an api-token
+type TokenCreateResponse struct {
+	// Token that has been created
+	Token Token
+
+	// Value of the token (key.secret) for the clients,
+	// it is only returned when the token is created
+	Value string
+}
+
+// TokenListRequest is the input-object
+// for listing the api-tokens
+type TokenListRequest struct{}
+
+// TokenListResponse is the output-object
+// for listing the api-tokens
+type TokenListResponse struct {
+	Tokens []Token
+}
+
+// TokenDeleteRequest is the input-object
+// for revoking an api-token
+type TokenDeleteRequest struct {
+	// Key for the token to revoke
+	Key string
+}
+
+// TokenDeleteResponse is the output-object
+// for revoking an api-token
+type TokenDeleteResponse struct{}
+
+// SecretService handles the named secrets, they are referenced
+// from the credentials in the configs with secret:<name>
+type SecretService interface {
+	// Set creates or updates a secret
+	Set(SecretSetRequest) SecretSetResponse
+
+	// List returns the secrets without their values
+	List(SecretListRequest) SecretListResponse
+
+	// Delete deletes the requested secret, unless
+	// it is referenced by any servers or nms-servers
+	Delete(SecretDeleteRequest) SecretDeleteResponse
+}
+
+// Secret is a named secret, the value
+// is encrypted with the master-key
+type Secret struct {
+	// Base for the datastore
+	datastore.Base
+
+	// Name of the secret
+	Name string
+
+	// Value of the secret, it is
+	// never returned by the service
+	Value string
+}
+
+// SecretSetRequest is the input-object
+// for setting a secret
+type SecretSetRequest struct {
+	// Name of the secret
+	Name string
+
+	// Value of the secret
+	Value string
+}
+
+// SecretSetResponse is the output-object
+// for setting a secret
+type SecretSetResponse struct {
+	// Secret that has been set
+	Secret Secret
+}
+
+// SecretListRequest is the input-object
+// for listing the secrets
+type SecretListRequest struct{}
+
+// SecretListResponse is the output-object
+// for listing the secrets
+type SecretListResponse struct {
+	Secrets []Secret
+}
+
+// SecretDeleteRequest is the input-object
+// for deleting a secret
+type SecretDeleteRequest struct {
+	// Name of the secret
+	Name string
+}
+
+// SecretDeleteResponse is the output-object
+// for deleting a secret
+type SecretDeleteResponse struct{}
+
+// AuditService handles the audit-log for the
+// administrative actions on the runners, servers and nms
+type AuditService interface {
+	// List returns the entries from the audit-log
+	List(AuditListRequest) AuditListResponse
+}
+
+// AuditEntry is an administrative action recorded in the audit-log,
+// the time for the action is the time the entry was created
+type AuditEntry struct {
+	// Base for the datastore
+	datastore.Base
+
+	// Actor is the name of the api-token for the caller
+	Actor string
+
+	// Key for the api-token of the caller
+	Key string
+
+	// Remote address of the caller
+	Remote string
+
+	// Method that was called (Service.Method)
+	Method string
+
+	// Request is the request-body with the secrets redacted
+	Request string
+
+	// Outcome of the request (ok or failed)
+	Outcome string
+
+	// Reason the request failed
+	Reason string
+}
+
+// AuditListRequest is the input-object
+// for listing the audit-log
+type AuditListRequest struct {
+	// Since is the time to list the entries from, either
+	// a duration back in time (like 24h or 7d) or a date
+	Since string
+
+	// Actor to list the entries for
+	Actor string
+
+	// Limit is the max amount of entries
+	// to return (defaults to 100)
+	Limit int64
+
+	// PageToken is the token for the page to return,
+	// from NextPageToken of the previous page
+	PageToken string
+}
+
+// AuditListResponse is the output-object
+// for listing the audit-log
+type AuditListResponse struct {
+	// Entries with the latest first
+	Entries []AuditEntry
+
+	// NextPageToken is the token for the next page,
+	// empty if it is the last page
+	NextPageToken string
+}
+
 // RunnerService handles all the runners
 type RunnerService interface {
 	// Apply applies the configuration to the backend
@@ -206,9 +769,18 @@ type RunnerService interface {
 	// Get returns the requested Runner
 	Get(RunnerGetRequest) RunnerGetResponse
 
-	// Delete deletes the requested Runner
+	// Delete moves the requested Runner to the trash
 	Delete(RunnerDeleteRequest) RunnerDeleteResponse
 
+	// ListTrash returns the runners in the trash
+	ListTrash(RunnerListTrashRequest) RunnerListTrashResponse
+
+	// Restore restores the requested Runner from the trash
+	Restore(RunnerRestoreRequest) RunnerRestoreResponse
+
+	// Purge permanently deletes the runners in the trash
+	Purge(RunnerPurgeRequest) RunnerPurgeResponse
+
 	// Start sets a runner to started
 	Start(RunnerStartRequest) RunnerStartResponse
 
@@ -227,9 +799,15 @@ type RunnerService interface {
 	// FinishStage sets a stage to Finished
 	FinishStage(StageRequest) StageResponse
 
+	// ProgressStage sets the progress for a stage
+	ProgressStage(StageProgressRequest) StageResponse
+
 	// LogItem logs an item
 	LogItem(LogItemRequest) LogResponse
 
+	// LogItems logs a batch of items
+	LogItems(LogItemsRequest) LogResponse
+
 	// LogDebug logs a debug-message
 	LogDebug(LogRequest) LogResponse
 
@@ -241,6 +819,12 @@ type RunnerService interface {
 
 	// Heartbeat sends a heartbeat for the api
 	Heartbeat(RunnerStartRequest) RunnerStartResponse
+
+	// Manifest returns the evidence-manifests for the requested Runner
+	Manifest(RunnerManifestRequest) RunnerManifestResponse
+
+	// Script returns the generated script for the requested Runner
+	Script(RunnerScriptRequest) RunnerScriptResponse
 }
 
 // Runner holds the information for a specific runner
@@ -275,6 +859,10 @@ type Runner struct {
 	// HealthyAt - last time the runner was healthy
 	HealthyAt *time.Time
 
+	// RunID is the id for the current run of the runner,
+	// it is set every time the runner is started by the queue
+	RunID string
+
 	// CaseSettings for the cases to use
 	CaseSettingsID uint
 	CaseSettings   *CaseSettings
@@ -284,6 +872,10 @@ type Runner struct {
 
 	// Switches to use for nuix-console
 	Switches []*NuixSwitch
+
+	// Engine for the script of the runner (ruby or
+	// python), the engine for the server is used if empty
+	Engine string
 }
 
 // RunnerApplyRequest is the input-object for
@@ -317,6 +909,9 @@ type RunnerApplyRequest struct {
 	// Switches to use for nuix-console
 	Switches []string
 
+	// Engine for the script of the runner (ruby or python)
+	Engine string
+
 	// Update - if the runner should be updated
 	Update bool
 }
@@ -329,12 +924,50 @@ type RunnerApplyResponse struct {
 
 // RunnerListRequest is the input-object for
 // listing the runners from the backend
-type RunnerListRequest struct{}
+type RunnerListRequest struct {
+	// Status to filter the runners with
+	// (waiting, running, failed, finished or timeout)
+	Status string
+
+	// Hostname of the server to filter the runners with
+	Hostname string
+
+	// Nms to filter the runners with
+	Nms string
+
+	// Licence to filter the runners with
+	Licence string
+
+	// Name to filter the runners with,
+	// a glob-pattern (for example case-*)
+	Name string
+
+	// CreatedAfter and CreatedBefore filters the
+	// runners by when they were created (unix-time)
+	CreatedAfter  int64
+	CreatedBefore int64
+
+	// Sort is the field to sort by (id, name, created, status or hostname),
+	// prefixed with - for descending order
+	Sort string
+
+	// Limit is the max amount of runners
+	// to return (defaults to 100)
+	Limit int64
+
+	// PageToken is the token for the page to return,
+	// from NextPageToken of the previous page
+	PageToken string
+}
 
 // RunnerListResponse is the input-object for
 // listing the runners from the backend
 type RunnerListResponse struct {
 	Runners []Runner
+
+	// NextPageToken is the token for the next page,
+	// empty if it is the last page
+	NextPageToken string
 }
 
 // RunnerGetRequest is the input-object
@@ -365,17 +998,74 @@ type RunnerDeleteRequest struct {
 
 	// Force - if the delete should be forced
 	Force bool
+
+	// Purge - if the runner should be permanently
+	// deleted instead of moved to the trash
+	Purge bool
 }
 
 // RunnerDeleteResponse is the output-object
 // for deleting a runner by name
 type RunnerDeleteResponse struct{}
 
+// RunnerListTrashRequest is the input-object
+// for listing the runners in the trash
+type RunnerListTrashRequest struct {
+	// Limit is the max amount of runners
+	// to return (defaults to 100)
+	Limit int64
+
+	// PageToken is the token for the page to return,
+	// from NextPageToken of the previous page
+	PageToken string
+}
+
+// RunnerListTrashResponse is the output-object
+// for listing the runners in the trash
+type RunnerListTrashResponse struct {
+	Runners []Runner
+
+	// NextPageToken is the token for the next page,
+	// empty if it is the last page
+	NextPageToken string
+}
+
+// RunnerRestoreRequest is the input-object
+// for restoring a runner from the trash by name
+type RunnerRestoreRequest struct {
+	// Name of the runner
+	Name string
+}
+
+// RunnerRestoreResponse is the output-object
+// for restoring a runner from the trash by name
+type RunnerRestoreResponse struct {
+	Runner Runner
+}
+
+// RunnerPurgeRequest is the input-object for
+// permanently deleting the runners in the trash
+type RunnerPurgeRequest struct {
+	// OlderThan is the duration (for example 720h or 30d)
+	// the runners must have been in the trash to be purged
+	OlderThan string
+}
+
+// RunnerPurgeResponse is the output-object for
+// permanently deleting the runners in the trash
+type RunnerPurgeResponse struct {
+	// Runners are the names of the purged runners
+	Runners []string
+}
+
 // RunnerStartRequest is the input-object
 // for starting a runner by id
 type RunnerStartRequest struct {
 	ID     uint
 	Runner string
+
+	// RunID for the run of the runner-script
+	RunID string
 }
 
 // RunnerStartResponse is the output-object
@@ -387,6 +1077,7 @@ type RunnerStartResponse struct{}
 type RunnerFailedRequest struct {
 	ID        uint
 	Runner    string
+	RunID     string
 	Exception string
 }
 
@@ -399,12 +1090,139 @@ type RunnerFailedResponse struct{}
 type RunnerFinishRequest struct {
 	ID     uint
 	Runner string
+
+	// RunID for the run of the runner-script
+	RunID string
 }
 
 // RunnerFinishResponse is the output-object
 // for finishing a runner by id
 type RunnerFinishResponse struct{}
 
+// RunnerScriptRequest is the input-object
+// for generating the script for a runner
+type RunnerScriptRequest struct {
+	// Name of the stored runner
+	Name string
+
+	// Runner to generate the script for
+	// instead of a stored runner (from a local yml-file)
+	Runner *RunnerApplyRequest
+}
+
+// RunnerScriptResponse is the output-object
+// for generating the script for a runner
+type RunnerScriptResponse struct {
+	// Script that would be run for the runner
+	Script string
+}
+
+// RunnerManifestRequest is the input-object
+// for requesting the manifests for a runner
+type RunnerManifestRequest struct {
+	// Name of the runner
+	Name string
+}
+
+// RunnerManifestResponse is the output-object
+// for requesting the manifests for a runner
+type RunnerManifestResponse struct {
+	Manifests []Manifest
+}
+
+// Manifest is a chain-of-custody manifest
+// for the evidence of a runner
+type Manifest struct {
+	// Base for the datastore
+	datastore.Base
+
+	// RunnerID foreign-key for runner-table
+	RunnerID uint
+
+	// Phase for when the manifest was
+	// created (apply, run or finish)
+	Phase string
+
+	// FileCount is the amount of files in the manifest
+	FileCount int64
+
+	// TotalSize is the total size in bytes
+	// for the files in the manifest
+	TotalSize int64
+
+	// Verified - if the manifest matches
+	// the previous manifest for the runner
+	Verified bool
+
+	// Evidence holds the evidence-paths in the manifest
+	Evidence []*ManifestEvidence
+
+	// Changes holds the changes from
+	// the previous manifest for the runner
+	Changes []*ManifestChange
+}
+
+// ManifestEvidence holds the files for
+// an evidence-path in a manifest
+type ManifestEvidence struct {
+	// Base for the datastore
+	datastore.Base
+
+	// ManifestID foreign-key for manifest-table
+	ManifestID uint
+
+	// Name of the evidence
+	Name string
+
+	// Path for the evidence
+	Path string
+
+	// FileCount is the amount of files for the evidence-path
+	FileCount int64
+
+	// TotalSize is the total size in bytes
+	// for the files in the evidence-path
+	TotalSize int64
+
+	// Files in the evidence-path
+	Files []*ManifestFile
+}
+
+// ManifestFile holds the information for
+// a file in an evidence-path
+type ManifestFile struct {
+	// Base for the datastore
+	datastore.Base
+
+	// ManifestEvidenceID foreign-key for manifestevidence-table
+	ManifestEvidenceID uint
+
+	// Path for the file
+	Path string
+
+	// Size of the file in bytes
+	Size int64
+
+	// Hash is the SHA256-hash for the file
+	Hash string
+}
+
+// ManifestChange holds a change for a file
+// compared to the previous manifest
+type ManifestChange struct {
+	// Base for the datastore
+	datastore.Base
+
+	// ManifestID foreign-key for manifest-table
+	ManifestID uint
+
+	// Path for the changed file
+	Path string
+
+	// Change for the file (added, removed or modified)
+	Change string
+}
+
 // NuixSwitch is a command argument for
 // nuix-console
 type NuixSwitch struct {
@@ -415,6 +1233,7 @@ type NuixSwitch struct {
 
 type LogItemRequest struct {
 	Runner       string
+	RunID        string
 	Stage        string
 	StageID      int
 	Message      string
@@ -424,8 +1243,22 @@ type LogItemRequest struct {
 	ProcessStage string
 }
 
+// LogItemsRequest is the input-object
+// for logging a batch of items
+type LogItemsRequest struct {
+	// Runner the items are logged for
+	Runner string
+
+	// RunID for the run of the runner-script
+	RunID string
+
+	// Items to log
+	Items []LogItemRequest
+}
+
 type LogRequest struct {
 	Runner    string
+	RunID     string
 	Stage     string
 	StageID   int
 	Message   string
@@ -477,6 +1310,7 @@ type Case struct {
 
 type StageRequest struct {
 	Runner  string
+	RunID   string
 	StageID uint
 }
 
@@ -484,6 +1318,21 @@ type StageResponse struct {
 	Stage Stage
 }
 
+// StageProgressRequest is the input-object
+// for setting the progress for a stage
+type StageProgressRequest struct {
+	Runner  string
+	RunID   string
+	StageID uint
+
+	// Total is the estimated amount of
+	// items, search-hits or batches for the stage
+	Total int64
+
+	// Count is the amount that is done for the stage
+	Count int64
+}
+
 // Stage holds different types of stages for a Runner
 type Stage struct {
 	// Base for the datastore
@@ -512,6 +1361,20 @@ type Stage struct {
 
 	// Reload reloads items in a Nuix-case based on a search
 	Reload *Reload
+
+	// Total is the estimated amount of work for
+	// the stage (items, search-hits or batches)
+	Total int64
+
+	// Progress is the amount of work done for the stage
+	Progress int64
+
+	// StartedAt is the time (unix) for when the stage was started
+	StartedAt int64
+
+	// EstimatedFinish is the estimated time (unix) for when the
+	// stage will finish, based on the throughput of the stage
+	EstimatedFinish int64
 }
 
 // Process -stage processes data into a Nuix-case
@@ -529,6 +1392,10 @@ type Process struct {
 	// EvidenceStore to process to the nuix-case
 	EvidenceStore []*Evidence
 
+	// Manifest - if a chain-of-custody manifest
+	// should be created for the evidence
+	Manifest bool
+
 	// Status for the stage
 	Status int64
 }
@@ -547,6 +1414,24 @@ type Evidence struct {
 	// Directory of where the evidence is located
 	Directory string
 
+	// Paths holds additional paths for the evidence
+	Paths []*EvidencePath
+
+	// PathList is a text-file listing paths
+	// for the evidence (one path per line)
+	PathList string
+
+	// LoadFile is a Nuix load-file to
+	// ingest into the evidence-container
+	LoadFile string
+
+	// Filters holds glob-patterns to include or
+	// exclude files from the paths of the evidence
+	Filters []*EvidenceFilter
+
+	// Metadata holds custom metadata for the evidence
+	Metadata []*EvidenceMetadata
+
 	// Description of the evidence
 	Description string
 
@@ -563,6 +1448,52 @@ type Evidence struct {
 	Locale string
 }
 
+// EvidencePath holds a path for an evidence
+type EvidencePath struct {
+	// Base for the datastore
+	datastore.Base
+
+	// EvidenceID foreign-key for evidence-table
+	EvidenceID uint
+
+	// Path for where the evidence is located at
+	Path string
+}
+
+// EvidenceFilter holds a glob-pattern for
+// the files to add from an evidence
+type EvidenceFilter struct {
+	// Base for the datastore
+	datastore.Base
+
+	// EvidenceID foreign-key for evidence-table
+	EvidenceID uint
+
+	// Pattern to match the files with,
+	// relative to the paths of the evidence
+	Pattern string
+
+	// Exclude - if the matched files should
+	// be excluded instead of included
+	Exclude bool
+}
+
+// EvidenceMetadata holds a custom
+// metadata-field for an evidence
+type EvidenceMetadata struct {
+	// Base for the datastore
+	datastore.Base
+
+	// EvidenceID foreign-key for evidence-table
+	EvidenceID uint
+
+	// Key for the metadata-field
+	Key string
+
+	// Value for the metadata-field
+	Value string
+}
+
 // SearchAndTag searches and tags data in a Nuix-case
 type SearchAndTag struct {
 	// Base for the datastore
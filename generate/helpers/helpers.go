@@ -0,0 +1,75 @@
+// Package helpers holds the helpers that are shared
+// by the templates for the different script-engines
+package helpers
+
+import (
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/gobuffalo/plush"
+)
+
+// NewContext returns a new plush-context with the
+// helpers and values to render the script for the runner,
+// ca is the pinned CA (pem) for the TLS-connection to the service
+func NewContext(remoteAddress, ca string, runner api.Runner) *plush.Context {
+	ctx := plush.NewContext()
+
+	// hasProcess returns true if the runner has
+	// any process-stages left to run
+	ctx.Set("hasProcess", func(r api.Runner) bool {
+		for _, s := range r.Stages {
+			if s.Process != nil && !avian.Finished(s.Process.Status) {
+				return true
+			}
+		}
+		return false
+	})
+
+	// openCompounds returns true if the compound-cases should be opened,
+	// a failed process-stage is rescanned into the single-case only
+	ctx.Set("openCompounds", func(r api.Runner) bool {
+		for _, s := range r.Stages {
+			if s.Process != nil && !avian.Finished(s.Process.Status) && s.Process.Status != avian.StatusFailed {
+				return true
+			}
+		}
+		return false
+	})
+
+	// includes and excludes returns the glob-patterns
+	// to filter the files of an evidence with
+	ctx.Set("includes", func(e *api.Evidence) []string {
+		var patterns []string
+		for _, f := range e.Filters {
+			if !f.Exclude {
+				patterns = append(patterns, f.Pattern)
+			}
+		}
+		return patterns
+	})
+
+	ctx.Set("excludes", func(e *api.Evidence) []string {
+		var patterns []string
+		for _, f := range e.Filters {
+			if f.Exclude {
+				patterns = append(patterns, f.Pattern)
+			}
+		}
+		return patterns
+	})
+
+	ctx.Set("getStages", func(r api.Runner) []*api.Stage { return r.Stages })
+	ctx.Set("process", func(s *api.Stage) bool { return s.Process != nil && !avian.Finished(s.Process.Status) })
+	ctx.Set("processFailed", func(s *api.Stage) bool { return s.Process.Status == avian.StatusFailed })
+	ctx.Set("searchAndTag", func(s *api.Stage) bool { return s.SearchAndTag != nil && !avian.Finished(s.SearchAndTag.Status) })
+	ctx.Set("exclude", func(s *api.Stage) bool { return s.Exclude != nil && !avian.Finished(s.Exclude.Status) })
+	ctx.Set("ocr", func(s *api.Stage) bool { return s.Ocr != nil && !avian.Finished(s.Ocr.Status) })
+	ctx.Set("populate", func(s *api.Stage) bool { return s.Populate != nil && !avian.Finished(s.Populate.Status) })
+	ctx.Set("reload", func(s *api.Stage) bool { return s.Reload != nil && !avian.Finished(s.Reload.Status) })
+	ctx.Set("stageName", func(s *api.Stage) string { return avian.Name(s) })
+
+	ctx.Set("remoteAddress", remoteAddress)
+	ctx.Set("ca", ca)
+	ctx.Set("runner", runner)
+	return ctx
+}
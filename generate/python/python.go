@@ -0,0 +1,75 @@
+package python
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"unicode"
+
+	"github.com/avian-digital-forensics/auto-processing/generate/helpers"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/gobuffalo/plush"
+)
+
+// Generator generates python-scripts (jython) for the runners
+type Generator struct{}
+
+// Generate returns the python-script for the runner
+func (Generator) Generate(remoteAddress, ca string, runner api.Runner) (string, error) {
+	return Generate(remoteAddress, ca, runner)
+}
+
+// Extension returns the file-extension for python-scripts
+func (Generator) Extension() string { return ".py" }
+
+// Generate returns the python-script for the runner
+func Generate(remoteAddress, ca string, runner api.Runner) (string, error) {
+	ctx := helpers.NewContext(remoteAddress, ca, runner)
+
+	// literal and comment are used for every value from the
+	// config, to not let a value break (or inject code into) the script
+	ctx.Set("literal", func(s string) template.HTML { return template.HTML(Literal(s)) })
+	ctx.Set("comment", func(s string) template.HTML { return template.HTML(Comment(s)) })
+	return plush.Render(pythonTemplate, ctx)
+}
+
+// Literal returns s encoded as a double-quoted python unicode-literal,
+// quotes, backslashes and control-characters are escaped
+func Literal(s string) string {
+	var b strings.Builder
+	b.WriteString(`u"`)
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			switch {
+			case unicode.IsPrint(r):
+				b.WriteRune(r)
+			case r > 0xffff:
+				fmt.Fprintf(&b, `\U%08x`, r)
+			default:
+				fmt.Fprintf(&b, `\u%04x`, r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// Comment returns s as a single line to be used in a python comment
+func Comment(s string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsPrint(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,30 @@
+package python_test
+
+import (
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/generate/python"
+	"github.com/matryer/is"
+)
+
+func TestLiteral(t *testing.T) {
+	is := is.New(t)
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"kind:email", `u"kind:email"`},
+		{"name:'O'Brien'", `u"name:'O'Brien'"`},
+		{`say "hello"`, `u"say \"hello\""`},
+		{`C:\Evidence\`, `u"C:\\Evidence\\"`},
+		{"#{system('calc')} %s", `u"#{system('calc')} %s"`},
+		{"line\r\nbreak\ttab", `u"line\r\nbreak\ttab"`},
+		{"null\x00byte", `u"null\u0000byte"`},
+		{"Åsa & <Örjan>", `u"Åsa & <Örjan>"`},
+	}
+
+	for _, tt := range tests {
+		is.Equal(python.Literal(tt.value), tt.want)
+	}
+}
@@ -0,0 +1,716 @@
+package python
+
+var pythonTemplate = `# -*- coding: utf-8 -*-
+# Code generated by Avian; DO NOT EDIT.
+import base64
+import binascii
+import fnmatch
+import hashlib
+import hmac
+import json
+import math
+import os
+import shutil
+import ssl
+import sys
+import tempfile
+import threading
+import time
+import urllib2
+import urlparse
+
+from java.io import File
+from java.lang import Throwable
+
+print('STARTING RUNNER')
+
+# create http-client to the server
+url = <%= literal(remoteAddress) %><%= if (ca != "") { %>
+
+# verify the service with the pinned CA
+ssl_context = ssl.create_default_context(cadata=<%= literal(ca) %>)<% } %>
+
+# api-token (key.secret) for the runner to sign the requests with
+api_key, _, api_secret = os.environ.get('AVIAN_TOKEN', '').partition('.')
+
+# id for this run of the runner, the service rejects
+# the callbacks from the scripts of the previous runs
+RUN_ID = <%= literal(runner.RunID) %>
+
+# Sign the request with the secret of the api-token, the method, path,
+# timestamp, nonce and body are signed (separated by newlines)
+def sign(method, path, timestamp, nonce, body):
+    message = '\n'.join([method, path, timestamp, nonce, body])
+    return base64.b64encode(hmac.new(str(api_secret), message, hashlib.sha256).digest())
+
+def send_request(method, body):
+    try:
+        address = '%sRunnerService.%s' % (url, method)
+        data = str(json.dumps(body, default=str))
+        timestamp = str(int(time.time()))
+        nonce = binascii.hexlify(os.urandom(16))
+        request = urllib2.Request(address, data)
+        request.add_header('Content-Type', 'application/json')
+        request.add_header('X-API-KEY', str(api_key))
+        request.add_header('X-API-TIMESTAMP', timestamp)
+        request.add_header('X-API-NONCE', nonce)
+        request.add_header('X-API-SIGNATURE', sign('POST', urlparse.urlparse(address).path, timestamp, nonce, data))
+        return urllib2.urlopen(request<%= if (ca != "") { %>, context=ssl_context<% } %>).read()
+
+    except (Exception, Throwable) as e:
+        # Handle the exception
+        if method == 'Start':
+            print('FINISHED RUNNER')
+            sys.stderr.write('no connection to avian-service : %s\n' % e)
+            sys.exit(1)
+        sys.stderr.write('failed to send request to: %s case: %s\n' % (method, e))
+
+# Set runner to running
+def start_runner():
+    send_request('Start', {'runner': <%= literal(runner.Name) %>, 'runID': RUN_ID, 'id': <%= runner.ID %>})
+
+# Set runner to failed
+def failed_runner(exception):
+    flush_items()
+    send_request('Failed', {'runner': <%= literal(runner.Name) %>, 'runID': RUN_ID, 'id': <%= runner.ID %>, 'exception': exception})
+
+# Set runner to finished
+def finish_runner():
+    flush_items()
+    send_request('Finish', {'runner': <%= literal(runner.Name) %>, 'runID': RUN_ID, 'id': <%= runner.ID %>})
+
+# Set stage to finished
+def finish(id):
+    flush_items()
+    send_request('FinishStage', {'runner': <%= literal(runner.Name) %>, 'runID': RUN_ID, 'stageID': id})
+
+# Set stage to running
+def start(id):
+    send_request('StartStage', {'runner': <%= literal(runner.Name) %>, 'runID': RUN_ID, 'stageID': id})
+
+# Set stage to failed
+def failed(id):
+    flush_items()
+    send_request('FailedStage', {'runner': <%= literal(runner.Name) %>, 'runID': RUN_ID, 'stageID': id})
+
+# The items are buffered and sent in batches to the service, the buffer
+# is flushed every LOG_ITEMS_BATCH_SIZE items or LOG_ITEMS_INTERVAL seconds
+LOG_ITEMS_BATCH_SIZE = 1000
+LOG_ITEMS_INTERVAL = 5
+log_items = []
+log_items_signal = threading.Condition()
+log_items_flush = threading.Lock()
+
+# The progress for the stages is sent with the buffered items
+stage_progress = {}
+
+# Send the buffered items and progress to the service
+def flush_items():
+    with log_items_flush:
+        with log_items_signal:
+            items = log_items[:]
+            del log_items[:]
+            stages = dict(stage_progress)
+            stage_progress.clear()
+        if items:
+            send_request('LogItems', {'runner': <%= literal(runner.Name) %>, 'runID': RUN_ID, 'items': items})
+        for id, (total, count) in sorted(stages.items()):
+            send_request('ProgressStage', {'runner': <%= literal(runner.Name) %>, 'runID': RUN_ID, 'stageID': id, 'total': total, 'count': count})
+
+# Set the progress for a stage, total is the
+# estimated amount and count is the amount done
+def progress(id, total, count):
+    with log_items_signal:
+        stage_progress[id] = (total, count)
+
+def log_item(stage, stage_id, message, count, mime_type, guid, process_stage):
+    item = {
+        'runner': <%= literal(runner.Name) %>,
+        'stage': stage,
+        'stageID': stage_id,
+        'message': message,
+        'count': count,
+        'mimeType': mime_type,
+        'gUID': guid,
+        'processStage': process_stage,
+    }
+    with log_items_signal:
+        log_items.append(item)
+        if len(log_items) >= LOG_ITEMS_BATCH_SIZE:
+            log_items_signal.notify()
+
+def flush_items_loop():
+    while True:
+        with log_items_signal:
+            if len(log_items) < LOG_ITEMS_BATCH_SIZE:
+                log_items_signal.wait(LOG_ITEMS_INTERVAL)
+        flush_items()
+
+flush_items_thread = threading.Thread(target=flush_items_loop)
+flush_items_thread.setDaemon(True)
+flush_items_thread.start()
+
+def log_debug(stage, stage_id, message):
+    send_request('LogDebug', {
+        'runner': <%= literal(runner.Name) %>,
+        'runID': RUN_ID,
+        'stage': stage,
+        'stageID': stage_id,
+        'message': message,
+    })
+
+def log_info(stage, stage_id, message):
+    send_request('LogInfo', {
+        'runner': <%= literal(runner.Name) %>,
+        'runID': RUN_ID,
+        'stage': stage,
+        'stageID': stage_id,
+        'message': message,
+    })
+
+def log_error(stage, stage_id, message, exception):
+    send_request('LogError', {
+        'runner': <%= literal(runner.Name) %>,
+        'runID': RUN_ID,
+        'stage': stage,
+        'stageID': stage_id,
+        'message': message,
+        'exception': exception,
+    })
+
+def heartbeat():
+    while True:
+        time.sleep(90)
+        send_request('Heartbeat', {'runner': <%= literal(runner.Name) %>, 'runID': RUN_ID, 'id': <%= runner.ID %>})
+
+heartbeat_thread = threading.Thread(target=heartbeat)
+heartbeat_thread.setDaemon(True)
+heartbeat_thread.start()
+
+# start the runner
+start_runner()
+
+case_factory = utilities.getCaseFactory()
+
+def open_case(settings):
+    try:
+        if not File(settings['directory'] + '\\case.fbi2').exists():
+            log_info('', 0, 'Creating case in directory: %s' % settings['directory'])
+            caze = case_factory.create(settings['directory'], settings)
+        else:
+            log_info('', 0, 'Opening case in directory: %s' % settings['directory'])
+            caze = case_factory.open(settings['directory'])
+    except (Exception, Throwable) as e:
+        log_error('', 0, 'Cannot create/open case, case might already be open', e)
+        sys.stderr.write('problem creating new case, case might already be open: %s\n' % e)
+        failed_runner('problem creating new case, case might already be open: %s' % e)
+        print('FINISHED RUNNER')
+        sys.exit(1)
+    return caze
+
+# read_path_list reads the paths listed in a text-file (one path per line)
+def read_path_list(path):
+    with open(path) as f:
+        return [line.strip() for line in f if line.strip()]
+
+# match_path matches the relative path with the glob-pattern,
+# a leading **/ also matches the files in the top-directory
+def match_path(pattern, path):
+    if pattern.startswith('**/') and match_path(pattern[3:], path):
+        return True
+    return fnmatch.fnmatch(path, pattern)
+
+# filter_paths expands the directories in paths to the files
+# matching the include-patterns that are not matching the exclude-patterns
+def filter_paths(paths, includes, excludes):
+    if not includes and not excludes:
+        return paths
+    if not includes:
+        includes = ['**/*']
+    files = []
+    for path in paths:
+        base = path.replace('\\', '/').rstrip('/')
+        if not os.path.isdir(base):
+            files.append(path)
+            continue
+        for root, dirs, names in os.walk(base):
+            for name in names:
+                file = os.path.join(root, name).replace('\\', '/')
+                relative = file[len(base) + 1:]
+                if not any(match_path(pattern, relative) for pattern in includes):
+                    continue
+                if any(match_path(exclude, relative) for exclude in excludes):
+                    continue
+                if file not in files:
+                    files.append(file)
+    return files
+
+# count_files returns the amount of files in the paths,
+# used to estimate the amount of items for a process-stage
+def count_files(paths):
+    count = 0
+    for path in paths:
+        base = path.replace('\\', '/')
+        if not os.path.isdir(base):
+            count += 1
+            continue
+        for root, dirs, names in os.walk(base):
+            count += len(names)
+    return count
+
+# tear down the cases
+def tear_down(single_case, compound_case, review_compound):
+    try:
+        log_debug('', 0, 'Starting case tear-down')
+        if compound_case is not None:
+            if compound_case.isCompound():
+                if not compound_case.getChildCases().contains(single_case):
+                    log_info('', 0, 'Adding single-case to compound')
+                    compound_case.addChildCase(single_case) # Add the newly processed case to the compound-case
+                    log_debug('', 0, 'Added single-case to compound-case')
+
+            if not compound_case.isClosed():
+                log_info('', 0, 'Closing compound-case')
+                compound_case.close()
+                log_debug('', 0, 'Closed compound-case')
+        else:
+            log_debug('', 0, 'No compound-case to tear down')
+
+        if review_compound is not None:
+            if review_compound.isCompound():
+                if not review_compound.getChildCases().contains(single_case):
+                    log_info('', 0, 'Adding single-case to review-compound')
+                    review_compound.addChildCase(single_case) # Add the newly processed case to the compound-case
+                    log_debug('', 0, 'Added single-case to review-compound')
+
+            if not review_compound.isClosed():
+                log_info('', 0, 'Closing review-compound')
+                review_compound.close()
+                log_debug('', 0, 'Closed review-compound')
+        else:
+            log_debug('', 0, 'No review-compound to tear down')
+
+        if not single_case.isClosed():
+            log_info('', 0, 'Closing single-case')
+            single_case.close()
+            log_debug('', 0, 'Closed single-case')
+        else:
+            log_debug('', 0, 'Single-case already closed')
+        log_debug('', 0, 'Case tear-down finished')
+    except (Exception, Throwable) as e:
+        # Handle the exception
+        log_error('', 0, 'Failed to tear-down cases', e)
+
+# Create or open the single-case
+log_info('', 0, 'Opening single-case: ' + <%= literal(runner.CaseSettings.Case.Name) %>)
+single_case = open_case({
+    'name': <%= literal(runner.CaseSettings.Case.Name) %>,
+    'directory': <%= literal(runner.CaseSettings.Case.Directory) %>,
+    'description': <%= literal(runner.CaseSettings.Case.Description) %>,
+    'investigator': <%= literal(runner.CaseSettings.Case.Investigator) %>,
+    'compound': False,
+})
+
+# The compound-cases are only opened when there are process-stages to run
+compound_case = None
+review_compound = None
+<%= if (openCompounds(runner)) { %>
+# Create or open the compound-case
+log_info('', 0, 'Opening compound-case: ' + <%= literal(runner.CaseSettings.CompoundCase.Name) %>)
+compound_case = open_case({
+    'name': <%= literal(runner.CaseSettings.CompoundCase.Name) %>,
+    'directory': <%= literal(runner.CaseSettings.CompoundCase.Directory) %>,
+    'description': <%= literal(runner.CaseSettings.CompoundCase.Description) %>,
+    'investigator': <%= literal(runner.CaseSettings.CompoundCase.Investigator) %>,
+    'compound': True,
+})
+
+# Create or open the review-compound
+log_info('', 0, 'Opening review-compound: ' + <%= literal(runner.CaseSettings.ReviewCompound.Name) %>)
+review_compound = open_case({
+    'name': <%= literal(runner.CaseSettings.ReviewCompound.Name) %>,
+    'directory': <%= literal(runner.CaseSettings.ReviewCompound.Directory) %>,
+    'description': <%= literal(runner.CaseSettings.ReviewCompound.Description) %>,
+    'investigator': <%= literal(runner.CaseSettings.ReviewCompound.Investigator) %>,
+    'compound': True,
+})<% } %>
+<%= for (i, s) in getStages(runner) { %><%= if (process(s)) { %>
+# Start stage: <%= i %>
+try:
+    # Check if the profile exists in the profile-store
+    if not utilities.getProcessingProfileStore().containsProfile(<%= literal(s.Process.Profile) %>):
+        # Import the profile
+        log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Did not find the requested processing-profile in the profile-store')
+        log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Importing new processing-profile from ' + <%= literal(s.Process.ProfilePath) %>)
+        utilities.getProcessingProfileStore().importProfile(<%= literal(s.Process.ProfilePath) %>, <%= literal(s.Process.Profile) %>)
+        log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Processing-profile has been imported')
+
+    # Create a processor to process the evidence for the case
+    evidence_total = 0
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Creating processor for case-processing')
+    case_processor = single_case.createProcessor()
+    case_processor.setProcessingProfile(<%= literal(s.Process.Profile) %>)
+    <%= if (processFailed(s)) { %>case_processor.rescanEvidenceRepositories(True)<% } else { %>
+    <%= for (j, evidence) in s.Process.EvidenceStore { %>
+    # Create container for evidence: <%= comment(evidence.Name) %>
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Adding evidence-container to case')
+    container_<%= s.ID %>_<%= j %> = case_processor.newEvidenceContainer(<%= literal(evidence.Name) %>)
+    evidence_paths = [<%= for (path) in evidence.SourcePaths() { %><%= literal(path) %>, <% } %>]<%= if (evidence.PathList != "") { %>
+    evidence_paths += read_path_list(<%= literal(evidence.PathList) %>)<% } %>
+    evidence_files = filter_paths(evidence_paths, [<%= for (pattern) in includes(evidence) { %><%= literal(pattern) %>, <% } %>], [<%= for (pattern) in excludes(evidence) { %><%= literal(pattern) %>, <% } %>])
+    for path in evidence_files:
+        container_<%= s.ID %>_<%= j %>.addFile(path)
+    evidence_total += count_files(evidence_files)<%= if (evidence.LoadFile != "") { %>
+    container_<%= s.ID %>_<%= j %>.addLoadFile({'metadata': <%= literal(evidence.LoadFile) %>})<% } %><%= if (len(evidence.Metadata) != 0) { %>
+    container_<%= s.ID %>_<%= j %>.setCustomMetadata({<%= for (metadata) in evidence.Metadata { %>
+        <%= literal(metadata.Key) %>: <%= literal(metadata.Value) %>,<% } %>
+    })<% } %>
+    container_<%= s.ID %>_<%= j %>.setDescription(<%= literal(evidence.Description) %>)
+    container_<%= s.ID %>_<%= j %>.setEncoding(<%= literal(evidence.Encoding) %>)
+    container_<%= s.ID %>_<%= j %>.setTimeZone(<%= literal(evidence.TimeZone) %>)
+    container_<%= s.ID %>_<%= j %>.setInitialCustodian(<%= literal(evidence.Custodian) %>)
+    container_<%= s.ID %>_<%= j %>.setLocale(<%= literal(evidence.Locale) %>)
+    container_<%= s.ID %>_<%= j %>.save()
+    <% } %><% } %>
+except (Exception, Throwable) as e:
+    # handle exception
+    log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Cannot initialize processor', e)
+    print('FINISHED RUNNER')
+    sys.stderr.write('error initializing processor %s\n' % e)
+    tear_down(single_case, compound_case, review_compound)
+    failed_runner(e)
+    sys.exit(1)
+
+# Start the processing
+try:
+    # Start the process-stage (update api)
+    start(<%= s.ID %>)
+    progress(<%= s.ID %>, evidence_total, 0)
+
+    # Handle the items being processed
+    semaphore = threading.Lock()
+    processed_count = [0]
+    def when_item_processed(info):
+        with semaphore:
+            processed_count[0] += 1
+            progress(<%= s.ID %>, evidence_total, processed_count[0])
+            log_item(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Processed item', processed_count[0], info.getMimeType(), info.getGuidPath(), '')
+    case_processor.whenItemProcessed(when_item_processed)
+
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Start case-processing')
+    case_processor.process()
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished case-processing')
+
+    # Finish the process-stage (update api)
+    finish(<%= s.ID %>)
+except (Exception, Throwable) as e:
+    # Handle the exception
+    # Set the process-stage to failed (update api)
+    failed(<%= s.ID %>)
+    tear_down(single_case, compound_case, review_compound)
+    log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Processing failed', e)
+    print('FINISHED RUNNER')
+    sys.stderr.write('Processing failed: %s\n' % e)
+    failed_runner(e)
+    sys.exit(1)
+<% } else if (searchAndTag(s)) { %>
+# Start stage: <%= i %>
+try:
+    # Start SearchAndTag-stage (update api)
+    start(<%= s.ID %>)
+
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting SearchAndTag-stage')<%= if (len(s.SearchAndTag.Files) != 0) { %>
+    # Search And Tag with files
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Creating bulk-searcher')
+    bulk_searcher = single_case.createBulkSearcher()
+    <%= for (file) in s.SearchAndTag.Files { %>
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Adding file: ' + <%= literal(file.Path) %> + ' to bulk-searcher')
+    bulk_searcher.importFile(<%= literal(file.Path) %>)
+    <% } %>
+    num_rows = bulk_searcher.getRowCount()
+    row_num = [0]
+    progress(<%= s.ID %>, num_rows, 0)
+    # Perform search and handle info
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting search')
+    def when_row_searched(info):
+        row_num[0] += 1
+        progress(<%= s.ID %>, num_rows, row_num[0])
+        log_item(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Searching through row - current size: %s - total size: %s' % (info.getCurrentSize(), info.getTotalSize()), row_num[0], '', '', '')
+    bulk_searcher.run(when_row_searched)
+<% } else { %>
+    # Search And Tag with search-query
+    items = single_case.search(<%= literal(s.SearchAndTag.Search) %>)
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Found %d from search %s - starts tagging' % (len(items), <%= literal(s.SearchAndTag.Search) %>))
+    item_count = 0
+    progress(<%= s.ID %>, len(items), 0)
+    for item in items:
+        item.addTag(<%= literal(s.SearchAndTag.Tag) %>)
+        item_count += 1
+        progress(<%= s.ID %>, len(items), item_count)
+        log_item(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Tagged item', item_count, item.getType().getName(), item.getGuid(), '')
+<% } %>
+    # Finish the SearchAndTag-stage (update api)
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished')
+    finish(<%= s.ID %>)
+except (Exception, Throwable) as e:
+    # Handle the exception for stage
+
+    # Set the SearchAndTag-stage to failed (update api)
+    failed(<%= s.ID %>)
+    <%= if (hasProcess(runner)) { %>
+    # Tear down the cases
+    tear_down(single_case, compound_case, review_compound)
+    <% } else { %>
+    # Tear down the single-case
+    tear_down(single_case, None, None)
+    <% } %>
+    log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Failed', e)
+    print('FINISHED RUNNER')
+    sys.stderr.write('Failed to run stage %s id %d : %s\n' % (<%= literal(stageName(s)) %>, <%= s.ID %>, e))
+    failed_runner(e)
+    sys.exit(1)
+<% } else if (exclude(s)) { %>
+# Start stage: <%= i %>
+try:
+    # Start Exclude-stage (update api)
+    start(<%= s.ID %>)
+
+    # Exclude with reason
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting Exclude-stage')
+    items = single_case.search(<%= literal(s.Exclude.Search) %>)
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Found %d from search %s - starts excluding' % (len(items), <%= literal(s.Exclude.Search) %>))
+    item_count = 0
+    progress(<%= s.ID %>, len(items), 0)
+    for item in items:
+        item.exclude(<%= literal(s.Exclude.Reason) %>)
+        item_count += 1
+        progress(<%= s.ID %>, len(items), item_count)
+        log_item(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Excluded item', item_count, item.getType().getName(), item.getGuid(), '')
+    # Finish the Exclude-stage (update api)
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished')
+    finish(<%= s.ID %>)
+except (Exception, Throwable) as e:
+    # Handle the exception for stage
+
+    # Set the Exclude-stage to failed (update api)
+    failed(<%= s.ID %>)
+    <%= if (hasProcess(runner)) { %>
+    # Tear down the cases
+    tear_down(single_case, compound_case, review_compound)
+    <% } else { %>
+    # Tear down the single-case
+    tear_down(single_case, None, None)
+    <% } %>
+    log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Failed', e)
+    print('FINISHED RUNNER')
+    sys.stderr.write('Failed to run stage %s id %d : %s\n' % (<%= literal(stageName(s)) %>, <%= s.ID %>, e))
+    failed_runner(e)
+    sys.exit(1)
+<% } else if (ocr(s)) { %>
+# Start stage: <%= i %>
+try:
+    # Start OCR-stage (update api)
+    start(<%= s.ID %>)
+
+    # Ocr
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting OCR-stage')
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Creating OCR-processor')
+    ocr_processor = utilities.createOcrProcessor()
+
+    # Check if the profile exists in the store
+    if not utilities.getOcrProfileStore().containsProfile(<%= literal(s.Ocr.Profile) %>):
+        # Import the profile
+        log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Did not find the requested ocr-profile in the profile-store')
+        log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Importing new ocr-profile from path ' + <%= literal(s.Ocr.ProfilePath) %>)
+        utilities.getOcrProfileStore().importProfile(<%= literal(s.Ocr.ProfilePath) %>, <%= literal(s.Ocr.Profile) %>)
+        log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'OCR-profile has been imported')
+
+    ocr_profile = utilities.getOcrProfileStore().getProfile(<%= literal(s.Ocr.Profile) %>)
+    ocr_items = single_case.search(<%= literal(s.Ocr.Search) %>)
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Found %d from search: %s - starts ocr' % (len(ocr_items), <%= literal(s.Ocr.Search) %>))
+    if len(ocr_items) == 0:
+        log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'No OCR items to process - skipping stage')
+    else:
+        # Log the info for the items
+        ocr_semaphore = threading.Lock()
+        def when_ocr_item_event_occurs(info):
+            with ocr_semaphore:
+                log_item(<%= literal(stageName(s)) %>, <%= s.ID %>, 'OCR item', info.getStageCount(), info.getItem().getType().getName(), info.getItem().getGuid(), info.getStage())
+        ocr_processor.whenItemEventOccurs(when_ocr_item_event_occurs)
+
+        # variables to use for batched ocr
+        target_batch_size = 100
+        total_batches = int(math.ceil(len(ocr_items) / float(target_batch_size)))
+
+        for batch_index in range(total_batches):
+            progress(<%= s.ID %>, total_batches, batch_index)
+            log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Start ocr-processing batch : %d/%d' % (batch_index + 1, total_batches))
+            batch_start = batch_index * target_batch_size
+            slice_items = ocr_items.subList(batch_start, min(batch_start + target_batch_size, len(ocr_items)))
+            ocr_processor.process(slice_items, ocr_profile)
+        progress(<%= s.ID %>, total_batches, total_batches)
+
+    # Finish the OCR-stage (update api)
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished')
+    finish(<%= s.ID %>)
+except (Exception, Throwable) as e:
+    # Handle the exception for stage
+
+    # Set the OCR-stage to failed (update api)
+    failed(<%= s.ID %>)
+    <%= if (hasProcess(runner)) { %>
+    # Tear down the cases
+    tear_down(single_case, compound_case, review_compound)
+    <% } else { %>
+    # Tear down the single-case
+    tear_down(single_case, None, None)
+    <% } %>
+    log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Failed', e)
+    print('FINISHED RUNNER')
+    sys.stderr.write('Failed to run stage %s id %d : %s\n' % (<%= literal(stageName(s)) %>, <%= s.ID %>, e))
+    failed_runner(e)
+    sys.exit(1)
+<% } else if (populate(s)) { %>
+# Start stage: <%= i %>
+try:
+    # Start Populate-stage (update api)
+    start(<%= s.ID %>)
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting stage')
+
+    # Populate stage
+    tmpdir = tempfile.gettempdir()
+    dir = '%s/populate' % tmpdir
+    if not os.path.exists(dir):
+        log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Creating tmp-dir: %s for export' % dir)
+        os.makedirs(dir)
+
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Creating batch-exporter with tmp-dir for populate')
+    exporter = utilities.createBatchExporter(dir)
+    <%= for (t) in s.Populate.Types { %>
+    <%= if (t.Type == "native") { %>
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Adding Native-product to exporter')
+    exporter.addProduct('native', {
+        'naming': 'guid',
+        'path': 'Natives',
+        'regenerateStored': True,
+    })
+    <% } %><%= if (t.Type == "pdf") { %>
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Adding PDF-product to exporter')
+    exporter.addProduct('pdf', {
+        'naming': 'guid',
+        'path': 'PDFs',
+        'regenerateStored': True,
+    })
+    <% } %><% } %>
+    items = single_case.search(<%= literal(s.Populate.Search) %>)
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Found %d items from search: %s - starts export for populate' % (len(items), <%= literal(s.Populate.Search) %>))
+
+    # Used to synchronize thread access in batch exported callback
+    semaphore = threading.Lock()
+    progress(<%= s.ID %>, len(items), 0)
+
+    # Setup batch exporter callback
+    def when_export_item_event_occurs(info):
+        if info.getFailure() is not None:
+            log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Export failure for item: %s : %s' % (info.getItem().getGuid(), info.getItem().getLocalisedName()), '')
+        # Make the progress reporting have some thread safety
+        with semaphore:
+            progress(<%= s.ID %>, len(items), info.getStageCount())
+            log_item('Populate', <%= s.ID %>, 'Exporting item', info.getStageCount(), info.getItem().getType().getName(), info.getItem().getGuid(), info.getStage())
+    exporter.whenItemEventOccurs(when_export_item_event_occurs)
+
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting export of items')
+    exporter.exportItems(items)
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished export of items')
+
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Removing tmp-dir')
+    shutil.rmtree(dir)
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Removed tmp-dir')
+
+    # Finish the Populate-stage (update api)
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished')
+    finish(<%= s.ID %>)
+except (Exception, Throwable) as e:
+    # Handle the exception for stage
+
+    # Set the Populate-stage to failed (update api)
+    failed(<%= s.ID %>)
+    <%= if (hasProcess(runner)) { %>
+    # Tear down the cases
+    tear_down(single_case, compound_case, review_compound)
+    <% } else { %>
+    # Tear down the single-case
+    tear_down(single_case, None, None)
+    <% } %>
+    log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Failed', e)
+    print('FINISHED RUNNER')
+    sys.stderr.write('Failed to run stage %s id %d : %s\n' % (<%= literal(stageName(s)) %>, <%= s.ID %>, e))
+    failed_runner(e)
+    sys.exit(1)
+<% } else if (reload(s)) { %>
+# Start stage: <%= i %>
+try:
+    # Start Reload-stage (update api)
+    start(<%= s.ID %>)
+
+    # Reload stage
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting Reload-stage')
+
+    # Check if the profile exists in the profile-store
+    if not utilities.getProcessingProfileStore().containsProfile(<%= literal(s.Reload.Profile) %>):
+        # Import the profile
+        log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Did not find the requested processing-profile for reload in the profile-store')
+        log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Importing new processing-profile from ' + <%= literal(s.Reload.ProfilePath) %>)
+        utilities.getProcessingProfileStore().importProfile(<%= literal(s.Reload.ProfilePath) %>, <%= literal(s.Reload.Profile) %>)
+        log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Processing-profile has been imported')
+
+    items = single_case.search(<%= literal(s.Reload.Search) %>)
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Found %d items from search: %s' % (len(items), <%= literal(s.Reload.Search) %>))
+
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Creating reload_processor')
+    reload_processor = single_case.createProcessor()
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Created reload_processor')
+    reload_processor.setProcessingProfile(<%= literal(s.Reload.Profile) %>)
+    reload_processor.reloadItemsFromSourceData(items)
+
+    # Handle item-information from reload-processor
+    semaphore = threading.Lock()
+    reload_count = [0]
+    progress(<%= s.ID %>, len(items), 0)
+    def when_item_reloaded(info):
+        with semaphore:
+            reload_count[0] += 1
+            progress(<%= s.ID %>, len(items), reload_count[0])
+            log_item('Reload', <%= s.ID %>, 'Reloaded item', reload_count[0], info.getMimeType(), info.getGuidPath(), '')
+    reload_processor.whenItemProcessed(when_item_reloaded)
+
+    # Start the processing
+    if len(items) > 0:
+        log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starts the reload-processing')
+        reload_processor.process()
+        log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished the reload-processing')
+    else:
+        log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'No items to process for reload')
+
+    # Finish the Reload-stage (update api)
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished')
+    finish(<%= s.ID %>)
+except (Exception, Throwable) as e:
+    # Handle the exception for stage
+
+    # Set the Reload-stage to failed (update api)
+    failed(<%= s.ID %>)
+    <%= if (hasProcess(runner)) { %>
+    # Tear down the cases
+    tear_down(single_case, compound_case, review_compound)
+    <% } else { %>
+    # Tear down the single-case
+    tear_down(single_case, None, None)
+    <% } %>
+    log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Failed', e)
+    print('FINISHED RUNNER')
+    sys.stderr.write('Failed to run stage %s id %d : %s\n' % (<%= literal(stageName(s)) %>, <%= s.ID %>, e))
+    failed_runner(e)
+    sys.exit(1)<% } %><% } %>
+print('FINISHED RUNNER')
+finish_runner()`
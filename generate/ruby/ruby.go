@@ -1,80 +1,72 @@
 package ruby
 
 import (
+	"fmt"
 	"html/template"
+	"strings"
+	"unicode"
 
+	"github.com/avian-digital-forensics/auto-processing/generate/helpers"
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
-	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
 	"github.com/gobuffalo/plush"
 )
 
-func Generate(remoteAddress string, runner api.Runner) (string, error) {
-	ctx := plush.NewContext()
+// Generator generates ruby-scripts for the runners
+type Generator struct{}
 
-	ctx.Set("process", func(r api.Runner) bool {
-		for _, s := range r.Stages {
-			if s.Process != nil && !avian.Finished(s.Process.Status) {
-				return true
-			}
-		}
-		return false
-	})
+// Generate returns the ruby-script for the runner
+func (Generator) Generate(remoteAddress, ca string, runner api.Runner) (string, error) {
+	return Generate(remoteAddress, ca, runner)
+}
 
-	ctx.Set("getProcessingProfile", func(r api.Runner) string {
-		for _, s := range r.Stages {
-			if s.Process != nil {
-				return s.Process.Profile
-			}
-		}
-		return ""
-	})
+// Extension returns the file-extension for ruby-scripts
+func (Generator) Extension() string { return ".rb" }
 
-	ctx.Set("getProcessingStageID", func(r api.Runner) uint {
-		for _, s := range r.Stages {
-			if s.Process != nil {
-				return s.ID
-			}
-		}
-		return 0
-	})
+// Generate returns the ruby-script for the runner
+func Generate(remoteAddress, ca string, runner api.Runner) (string, error) {
+	ctx := helpers.NewContext(remoteAddress, ca, runner)
 
-	ctx.Set("getProcessingFailed", func(r api.Runner) bool {
-		for _, s := range r.Stages {
-			if s.Process != nil {
-				return (s.Process.Status == avian.StatusFailed)
-			}
-		}
-		return false
-	})
+	// literal and comment are used for every value from the
+	// config, to not let a value break (or inject code into) the script
+	ctx.Set("literal", func(s string) template.HTML { return template.HTML(Literal(s)) })
+	ctx.Set("comment", func(s string) template.HTML { return template.HTML(Comment(s)) })
+	return plush.Render(rubyTemplate, ctx)
+}
 
-	ctx.Set("getProcessingProfilePath", func(r api.Runner) string {
-		for _, s := range r.Stages {
-			if s.Process != nil {
-				return s.Process.ProfilePath
+// Literal returns s encoded as a double-quoted ruby string-literal,
+// interpolation, quotes, backslashes and control-characters are escaped
+func Literal(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\', '#':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if !unicode.IsPrint(r) {
+				fmt.Fprintf(&b, `\u{%x}`, r)
+				continue
 			}
+			b.WriteRune(r)
 		}
-		return ""
-	})
+	}
+	b.WriteByte('"')
+	return b.String()
+}
 
-	ctx.Set("getEvidence", func(r api.Runner) []*api.Evidence {
-		for _, s := range r.Stages {
-			if s.Process != nil {
-				return s.Process.EvidenceStore
-			}
+// Comment returns s as a single line to be used in a ruby comment
+func Comment(s string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsPrint(r) {
+			return ' '
 		}
-		return nil
-	})
-
-	ctx.Set("getStages", func(r api.Runner) []*api.Stage { return r.Stages })
-	ctx.Set("searchAndTag", func(s *api.Stage) bool { return s.SearchAndTag != nil && !avian.Finished(s.SearchAndTag.Status) })
-	ctx.Set("exclude", func(s *api.Stage) bool { return s.Exclude != nil && !avian.Finished(s.Exclude.Status) })
-	ctx.Set("ocr", func(s *api.Stage) bool { return s.Ocr != nil && !avian.Finished(s.Ocr.Status) })
-	ctx.Set("populate", func(s *api.Stage) bool { return s.Populate != nil && !avian.Finished(s.Populate.Status) })
-	ctx.Set("reload", func(s *api.Stage) bool { return s.Reload != nil && !avian.Finished(s.Reload.Status) })
-	ctx.Set("stageName", func(s *api.Stage) string { return avian.Name(s) })
-	ctx.Set("formatQuotes", func(s string) template.HTML { return template.HTML(s) })
-
-	ctx.Set("remoteAddress", remoteAddress)
-	ctx.Set("runner", runner)
-	return plush.Render(rubyTemplate, ctx)
+		return r
+	}, s)
 }
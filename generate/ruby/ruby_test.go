@@ -0,0 +1,30 @@
+package ruby_test
+
+import (
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	"github.com/matryer/is"
+)
+
+func TestLiteral(t *testing.T) {
+	is := is.New(t)
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"kind:email", `"kind:email"`},
+		{"name:'O'Brien'", `"name:'O'Brien'"`},
+		{`say "hello"`, `"say \"hello\""`},
+		{`C:\Evidence\`, `"C:\\Evidence\\"`},
+		{"#{system('calc')}", `"\#{system('calc')}"`},
+		{"line\r\nbreak\ttab", `"line\r\nbreak\ttab"`},
+		{"null\x00byte", `"null\u{0}byte"`},
+		{"Åsa & <Örjan>", `"Åsa & <Örjan>"`},
+	}
+
+	for _, tt := range tests {
+		is.Equal(ruby.Literal(tt.value), tt.want)
+	}
+}
@@ -6,21 +6,52 @@ require 'fileutils'
 require 'net/http'
 require 'uri'
 require 'json'
+require 'openssl'
+require 'base64'
+require 'securerandom'
 require 'thread'
 require 'time'
 
 STDOUT.puts('STARTING RUNNER')
 
 # create http-client to the server
-@url = URI('<%= remoteAddress %>')
-@http = Net::HTTP.new(@url.host, @url.port);
+@url = URI(<%= literal(remoteAddress) %>)
+@http = Net::HTTP.new(@url.host, @url.port);<%= if (ca != "") { %>
+
+# verify the service with the pinned CA
+@http.use_ssl = true
+@http.verify_mode = OpenSSL::SSL::VERIFY_PEER
+@http.cert_store = OpenSSL::X509::Store.new
+<%= literal(ca) %>.scan(/-----BEGIN CERTIFICATE-----.+?-----END CERTIFICATE-----/m).each do |pem|
+  @http.cert_store.add_cert(OpenSSL::X509::Certificate.new(pem))
+end<% } %>
+
+# api-token (key.secret) for the runner to sign the requests with
+@api_key, @api_secret = ENV['AVIAN_TOKEN'].to_s.split('.', 2)
+
+# id for this run of the runner, the service rejects
+# the callbacks from the scripts of the previous runs
+RUN_ID = <%= literal(runner.RunID) %>
+
+# Sign the request with the secret of the api-token, the method, path,
+# timestamp, nonce and body are signed (separated by newlines)
+def sign(method, path, timestamp, nonce, body)
+  message = [method, path, timestamp, nonce, body].join("\n")
+  Base64.strict_encode64(OpenSSL::HMAC.digest('sha256', @api_secret.to_s, message))
+end
 
 def send_request(method, body)
   begin
-    uri = "%sRunnerService.%s" % [@url, method]
+    uri = URI("%sRunnerService.%s" % [@url, method])
+    timestamp = Time.now.to_i.to_s
+    nonce = SecureRandom.hex(16)
     request = Net::HTTP::Post.new(uri)
     request.body = body.to_json
     request["Content-Type"] = "application/json"
+    request["X-API-KEY"] = @api_key.to_s
+    request["X-API-TIMESTAMP"] = timestamp
+    request["X-API-NONCE"] = nonce
+    request["X-API-SIGNATURE"] = sign('POST', uri.request_uri, timestamp, nonce, request.body)
     @http.request(request)
 
   rescue => e
@@ -36,37 +67,79 @@ end
 
 # Set runner to running
 def start_runner
-  send_request('Start', {runner: '<%= runner.Name %>', id: <%= runner.ID %>})
+  send_request('Start', {runner: <%= literal(runner.Name) %>, runID: RUN_ID, id: <%= runner.ID %>})
 end
 
 # Set runner to failed
 def failed_runner(exception)
-  send_request('Failed', {runner: '<%= runner.Name %>', id: <%= runner.ID %>, exception: exception})
+  flush_items
+  send_request('Failed', {runner: <%= literal(runner.Name) %>, runID: RUN_ID, id: <%= runner.ID %>, exception: exception})
 end
 
 # Set runner to finished
 def finish_runner
-  send_request('Finish', {runner: '<%= runner.Name %>', id: <%= runner.ID %>})
+  flush_items
+  send_request('Finish', {runner: <%= literal(runner.Name) %>, runID: RUN_ID, id: <%= runner.ID %>})
 end
 
-# Set stage to failed
+# Set stage to finished
 def finish(id)
-  send_request('FinishStage', {runner: '<%= runner.Name %>', stageID: id})
+  flush_items
+  send_request('FinishStage', {runner: <%= literal(runner.Name) %>, runID: RUN_ID, stageID: id})
 end
 
 # Set stage to running
 def start(id)
-  send_request('StartStage', {runner: '<%= runner.Name %>', stageID: id})
+  send_request('StartStage', {runner: <%= literal(runner.Name) %>, runID: RUN_ID, stageID: id})
 end
 
 # Set stage to failed
 def failed(id)
-  send_request('FailedStage', {runner: '<%= runner.Name %>', stageID: id})
+  flush_items
+  send_request('FailedStage', {runner: <%= literal(runner.Name) %>, runID: RUN_ID, stageID: id})
+end
+
+# The items are buffered and sent in batches to the service, the buffer
+# is flushed every LOG_ITEMS_BATCH_SIZE items or LOG_ITEMS_INTERVAL seconds
+LOG_ITEMS_BATCH_SIZE = 1000
+LOG_ITEMS_INTERVAL = 5
+@log_items = []
+@log_items_lock = Mutex.new
+@log_items_signal = ConditionVariable.new
+@log_items_flush = Mutex.new
+
+# The progress for the stages is sent with the buffered items
+@progress = {}
+
+# Send the buffered items and progress to the service
+def flush_items
+  @log_items_flush.synchronize {
+    items = nil
+    stages = nil
+    @log_items_lock.synchronize {
+      items = @log_items
+      @log_items = []
+      stages = @progress
+      @progress = {}
+    }
+    send_request('LogItems', {runner: <%= literal(runner.Name) %>, runID: RUN_ID, items: items}) unless items.empty?
+    stages.each do |id, stage|
+      send_request('ProgressStage', {runner: <%= literal(runner.Name) %>, runID: RUN_ID, stageID: id, total: stage[:total], count: stage[:count]})
+    end
+  }
+end
+
+# Set the progress for a stage, total is the
+# estimated amount and count is the amount done
+def progress(id, total, count)
+  @log_items_lock.synchronize {
+    @progress[id] = {total: total, count: count}
+  }
 end
 
 def log_item(stage, stage_id, message, count, mime_type, guid, processStage)
   item = {
-    runner: '<%= runner.Name %>', 
+    runner: <%= literal(runner.Name) %>, 
     stage: stage, 
     stageID: stage_id,
     message: message,
@@ -75,12 +148,25 @@ def log_item(stage, stage_id, message, count, mime_type, guid, processStage)
     gUID: guid, 
     processStage: processStage,
   }
-  send_request('LogItem', item)
+  @log_items_lock.synchronize {
+    @log_items << item
+    @log_items_signal.signal if @log_items.length >= LOG_ITEMS_BATCH_SIZE
+  }
 end
 
+Thread.new {
+  loop do
+    @log_items_lock.synchronize {
+      @log_items_signal.wait(@log_items_lock, LOG_ITEMS_INTERVAL) if @log_items.length < LOG_ITEMS_BATCH_SIZE
+    }
+    flush_items
+  end
+}
+
 def log_debug(stage, stage_id, message)
   send_request('LogDebug', {
-    runner: '<%= runner.Name %>', 
+    runner: <%= literal(runner.Name) %>, 
+    runID: RUN_ID,
     stage: stage, 
     stageID: stage_id,
     message: message,
@@ -89,7 +175,8 @@ end
 
 def log_info(stage, stage_id, message)
   send_request('LogInfo', {
-    runner: '<%= runner.Name %>', 
+    runner: <%= literal(runner.Name) %>, 
+    runID: RUN_ID,
     stage: stage, 
     stageID: stage_id,
     message: message,
@@ -98,7 +185,8 @@ end
 
 def log_error(stage, stage_id, message, exception)
   send_request('LogError', {
-    runner: '<%= runner.Name %>', 
+    runner: <%= literal(runner.Name) %>, 
+    runID: RUN_ID,
     stage: stage, 
     stageID: stage_id,
     message: message,
@@ -109,7 +197,7 @@ end
 Thread.new {
   loop do
     sleep 90
-    send_request('Heartbeat', {runner: '<%= runner.Name %>', id: <%= runner.ID %>})
+    send_request('Heartbeat', {runner: <%= literal(runner.Name) %>, runID: RUN_ID, id: <%= runner.ID %>})
   end
 }
 
@@ -137,6 +225,45 @@ def open_case(settings)
   return caze
 end
 
+# read_path_list reads the paths listed in a text-file (one path per line)
+def read_path_list(path)
+  File.readlines(path).map(&:strip).reject(&:empty?)
+end
+
+# filter_paths expands the directories in paths to the files
+# matching the include-patterns that are not matching the exclude-patterns
+def filter_paths(paths, includes, excludes)
+  return paths if includes.empty? && excludes.empty?
+  includes = ['**/*'] if includes.empty?
+  files = []
+  paths.each do |path|
+    base = path.gsub('\\', '/').chomp('/')
+    unless File.directory?(base)
+      files << path
+      next
+    end
+    includes.each do |pattern|
+      Dir.glob(File.join(base, pattern)).each do |file|
+        next unless File.file?(file)
+        relative = file.sub("#{base}/", '')
+        next if excludes.any? { |exclude| File.fnmatch(exclude, relative, File::FNM_PATHNAME | File::FNM_EXTGLOB) }
+        files << file
+      end
+    end
+  end
+  files.uniq
+end
+
+# count_files returns the amount of files in the paths,
+# used to estimate the amount of items for a process-stage
+def count_files(paths)
+  paths.inject(0) do |count, path|
+    base = path.gsub('\\', '/')
+    next count + 1 unless File.directory?(base)
+    count + Dir.glob(File.join(base, '**', '*')).count { |file| File.file?(file) }
+  end
+end
+
 # tear down the cases 
 def tear_down(single_case, compound_case, review_compound)
   begin
@@ -192,65 +319,81 @@ def tear_down(single_case, compound_case, review_compound)
 end
 
 # Create or open the single-case
-log_info('', 0, 'Opening single-case: <%= runner.CaseSettings.Case.Name %>')
+log_info('', 0, 'Opening single-case: ' + <%= literal(runner.CaseSettings.Case.Name) %>)
 single_case = open_case({ 
-  'name' => '<%= runner.CaseSettings.Case.Name %>',
-  'directory' => '<%= runner.CaseSettings.Case.Directory %>',
-  'description' => '<%= runner.CaseSettings.Case.Description %>',
-  'investigator' => '<%= runner.CaseSettings.Case.Investigator %>',
+  'name' => <%= literal(runner.CaseSettings.Case.Name) %>,
+  'directory' => <%= literal(runner.CaseSettings.Case.Directory) %>,
+  'description' => <%= literal(runner.CaseSettings.Case.Description) %>,
+  'investigator' => <%= literal(runner.CaseSettings.Case.Investigator) %>,
   'compound' => false,
 })
-<%= if (process(runner)) { %><%= if (!getProcessingFailed(runner)) { %>
+
+# The compound-cases are only opened when there are process-stages to run
+compound_case = nil
+review_compound = nil
+<%= if (openCompounds(runner)) { %>
 # Create or open the compound-case
-log_info('', 0, 'Opening compound-case: <%= runner.CaseSettings.CompoundCase.Name %>')
+log_info('', 0, 'Opening compound-case: ' + <%= literal(runner.CaseSettings.CompoundCase.Name) %>)
 compound_case = open_case({ 
-  'name' => '<%= runner.CaseSettings.CompoundCase.Name %>',
-  'directory' => '<%= runner.CaseSettings.CompoundCase.Directory %>',
-  'description' => '<%= runner.CaseSettings.CompoundCase.Description %>',
-  'investigator' => '<%= runner.CaseSettings.CompoundCase.Investigator %>',
+  'name' => <%= literal(runner.CaseSettings.CompoundCase.Name) %>,
+  'directory' => <%= literal(runner.CaseSettings.CompoundCase.Directory) %>,
+  'description' => <%= literal(runner.CaseSettings.CompoundCase.Description) %>,
+  'investigator' => <%= literal(runner.CaseSettings.CompoundCase.Investigator) %>,
   'compound' => true,
 })
 
 # Create or open the review-compound
-log_info('', 0, 'Opening review-compound: <%= runner.CaseSettings.ReviewCompound.Name %>')
+log_info('', 0, 'Opening review-compound: ' + <%= literal(runner.CaseSettings.ReviewCompound.Name) %>)
 review_compound = open_case({ 
-  'name' => '<%= runner.CaseSettings.ReviewCompound.Name %>',
-  'directory' => '<%= runner.CaseSettings.ReviewCompound.Directory %>',
-  'description' => '<%= runner.CaseSettings.ReviewCompound.Description %>',
-  'investigator' => '<%= runner.CaseSettings.ReviewCompound.Investigator %>',
+  'name' => <%= literal(runner.CaseSettings.ReviewCompound.Name) %>,
+  'directory' => <%= literal(runner.CaseSettings.ReviewCompound.Directory) %>,
+  'description' => <%= literal(runner.CaseSettings.ReviewCompound.Description) %>,
+  'investigator' => <%= literal(runner.CaseSettings.ReviewCompound.Investigator) %>,
   'compound' => true,
 })<% } %>
-
+<%= for (i, s) in getStages(runner) { %><%= if (process(s)) { %>
+# Start stage: <%= i %>
 begin
   # Check if the profile exists in the profile-store
-  unless $utilities.get_processing_profile_store.contains_profile('<%= getProcessingProfile(runner) %>')
+  unless $utilities.get_processing_profile_store.contains_profile(<%= literal(s.Process.Profile) %>)
     # Import the profile
-    log_debug('', 0, 'Did not find the requested processing-profile in the profile-store')
-    log_info('', 0, 'Importing new processing-profile from <%= getProcessingProfilePath(runner) %>')
-    $utilities.get_processing_profile_store.import_profile('<%= getProcessingProfilePath(runner) %>', '<%= getProcessingProfile(runner) %>')
-    log_debug('', 0, 'Processing-profile has been imported')
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Did not find the requested processing-profile in the profile-store')
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Importing new processing-profile from ' + <%= literal(s.Process.ProfilePath) %>)
+    $utilities.get_processing_profile_store.import_profile(<%= literal(s.Process.ProfilePath) %>, <%= literal(s.Process.Profile) %>)
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Processing-profile has been imported')
   end
 
   # Create a processor to process the evidence for the case
-  log_info('', 0, 'Creating processor for case-processing')
+  evidence_total = 0
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Creating processor for case-processing')
   case_processor = single_case.create_processor
-  case_processor.set_processing_profile('<%= getProcessingProfile(runner) %>')
-  <%= if (getProcessingFailed(runner)) { %>case_processor.rescan_evidence_repositories(true)<% } else { %>
-  <%= for (i, evidence) in getEvidence(runner) { %>
-  # Create container for evidence: <%= evidence.Name %>
-  log_info('', 0, 'Adding evidence-container to case')
-  container_<%= i %> = case_processor.new_evidence_container('<%= evidence.Name %>')
-  container_<%= i %>.add_file('<%= evidence.Directory %>')
-  container_<%= i %>.set_description('<%= evidence.Description %>')
-  container_<%= i %>.set_encoding('<%= evidence.Encoding %>')
-  container_<%= i %>.set_time_zone('<%= evidence.TimeZone %>')
-  container_<%= i %>.set_initial_custodian('<%= evidence.Custodian %>')
-  container_<%= i %>.set_locale('<%= evidence.Locale %>')
-  container_<%= i %>.save
+  case_processor.set_processing_profile(<%= literal(s.Process.Profile) %>)
+  <%= if (processFailed(s)) { %>case_processor.rescan_evidence_repositories(true)<% } else { %>
+  <%= for (j, evidence) in s.Process.EvidenceStore { %>
+  # Create container for evidence: <%= comment(evidence.Name) %>
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Adding evidence-container to case')
+  container_<%= s.ID %>_<%= j %> = case_processor.new_evidence_container(<%= literal(evidence.Name) %>)
+  evidence_paths = [<%= for (path) in evidence.SourcePaths() { %><%= literal(path) %>, <% } %>]<%= if (evidence.PathList != "") { %>
+  evidence_paths += read_path_list(<%= literal(evidence.PathList) %>)<% } %>
+  evidence_files = filter_paths(evidence_paths, [<%= for (pattern) in includes(evidence) { %><%= literal(pattern) %>, <% } %>], [<%= for (pattern) in excludes(evidence) { %><%= literal(pattern) %>, <% } %>])
+  evidence_files.each do |path|
+    container_<%= s.ID %>_<%= j %>.add_file(path)
+  end
+  evidence_total += count_files(evidence_files)<%= if (evidence.LoadFile != "") { %>
+  container_<%= s.ID %>_<%= j %>.add_load_file({"metadata" => <%= literal(evidence.LoadFile) %>})<% } %><%= if (len(evidence.Metadata) != 0) { %>
+  container_<%= s.ID %>_<%= j %>.set_custom_metadata({<%= for (metadata) in evidence.Metadata { %>
+    <%= literal(metadata.Key) %> => <%= literal(metadata.Value) %>,<% } %>
+  })<% } %>
+  container_<%= s.ID %>_<%= j %>.set_description(<%= literal(evidence.Description) %>)
+  container_<%= s.ID %>_<%= j %>.set_encoding(<%= literal(evidence.Encoding) %>)
+  container_<%= s.ID %>_<%= j %>.set_time_zone(<%= literal(evidence.TimeZone) %>)
+  container_<%= s.ID %>_<%= j %>.set_initial_custodian(<%= literal(evidence.Custodian) %>)
+  container_<%= s.ID %>_<%= j %>.set_locale(<%= literal(evidence.Locale) %>)
+  container_<%= s.ID %>_<%= j %>.save
   <% } %><% } %>
 rescue => e
   # handle exception
-  log_error('', 0, 'Cannot initialize processor', e)
+  log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Cannot initialize processor', e)
   STDOUT.puts('FINISHED RUNNER')
   STDERR.puts("error initializing processor #{e}")
   tear_down(single_case, compound_case, review_compound)
@@ -261,7 +404,8 @@ end
 # Start the processing
 begin
   # Start the process-stage (update api)
-  start(<%= getProcessingStageID(runner) %>)
+  start(<%= s.ID %>)
+  progress(<%= s.ID %>, evidence_total, 0)
 
   # Handle the items being processed
   semaphore = Mutex.new
@@ -269,78 +413,83 @@ begin
   case_processor.when_item_processed do |info|
     semaphore.synchronize {
       processed_count += 1
-      log_item('Process', <%= getProcessingStageID(runner) %>, 'Processed item', processed_count, info.mime_type, info.guid_path, '')
+      progress(<%= s.ID %>, evidence_total, processed_count)
+      log_item(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Processed item', processed_count, info.mime_type, info.guid_path, '')
     }
   end
 
-  log_info('Process', <%= getProcessingStageID(runner) %>, 'Start case-processing')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Start case-processing')
   case_processor.process
-  log_info('Process', <%= getProcessingStageID(runner) %>, 'Finished case-processing')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished case-processing')
 
   # Finish the process-stage (update api)
-  finish(<%= getProcessingStageID(runner) %>)
+  finish(<%= s.ID %>)
 rescue => e
   # Handle the exception
   # Set the process-stage to failed (update api)
-  failed(<%= getProcessingStageID(runner) %>)
+  failed(<%= s.ID %>)
   tear_down(single_case, compound_case, review_compound)
-  log_error('Process', <%= getProcessingStageID(runner) %>, 'Processing failed', e)
+  log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Processing failed', e)
   STDOUT.puts('FINISHED RUNNER')
   STDERR.puts("Processing failed: #{e}")
   failed_runner(e)
   exit(false)
 end
-<% } %><%= for (i, s) in getStages(runner) { %><%= if (searchAndTag(s)) { %>
+<% } else if (searchAndTag(s)) { %>
 # Start stage: <%= i %>
 begin
   # Start SearchAndTag-stage (update api)
   start(<%= s.ID %>)
 
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Starting SearchAndTag-stage')<%= if (len(s.SearchAndTag.Files) != 0) { %>
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting SearchAndTag-stage')<%= if (len(s.SearchAndTag.Files) != 0) { %>
   # Search And Tag with files
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Creating bulk-searcher')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Creating bulk-searcher')
   bulk_searcher = single_case.create_bulk_searcher
   <%= for (file) in s.SearchAndTag.Files { %>
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Adding file: <%= file.Path %> to bulk-searcher')
-  bulk_searcher.import_file('<%= file.Path %>')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Adding file: ' + <%= literal(file.Path) %> + ' to bulk-searcher')
+  bulk_searcher.import_file(<%= literal(file.Path) %>)
   <% } %>
   num_rows = bulk_searcher.row_count
   row_num = 0
+  progress(<%= s.ID %>, num_rows, 0)
   # Perform search and handle info
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Starting search')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting search')
   bulk_searcher.run do |info|
     row_num += 1
-    log_item('<%= stageName(s) %>', <%= s.ID %>, 'Searching through row - current size: #{info.current_size} - total size: #{info.total_size}', row_num, '', '', '')
+    progress(<%= s.ID %>, num_rows, row_num)
+    log_item(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Searching through row - current size: #{info.current_size} - total size: #{info.total_size}', row_num, '', '', '')
   end
 <% } else { %>
   # Search And Tag with search-query
-  items = single_case.search('<%= formatQuotes(s.SearchAndTag.Search) %>')
-  log_debug('<%= stageName(s) %>', <%= s.ID %>, "Found #{items.length} from search <%= s.SearchAndTag.Search %> - starts tagging")
+  items = single_case.search(<%= literal(s.SearchAndTag.Search) %>)
+  log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, "Found #{items.length} from search " + <%= literal(s.SearchAndTag.Search) %> + " - starts tagging")
   item_count = 0
+  progress(<%= s.ID %>, items.length, 0)
   for item in items
-    item.add_tag('<%= s.SearchAndTag.Tag %>')
+    item.add_tag(<%= literal(s.SearchAndTag.Tag) %>)
     item_count += 1
-    log_item('<%= stageName(s) %>', <%= s.ID %>, 'Tagged item', item_count, item.type.name, item.guid, '')
+    progress(<%= s.ID %>, items.length, item_count)
+    log_item(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Tagged item', item_count, item.type.name, item.guid, '')
   end
 <% } %>
   # Finish the SearchAndTag-stage (update api)
-  log_debug('<%= stageName(s) %>', <%= s.ID %>, 'Finished')
+  log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished')
   finish(<%= s.ID %>)
 rescue => e
   # Handle the exception for stage
   
   # Set the SearchAndTag-stage to failed (update api)
   failed(<%= s.ID %>)
-  <%= if (process(runner)) { %>
+  <%= if (hasProcess(runner)) { %>
   # Tear down the cases
   tear_down(single_case, compound_case, review_compound)
   <% } else { %>
   # Tear down the single-case
   tear_down(single_case, nil, nil)
   <% } %>
-  log_error('<%= stageName(s) %>', <%= s.ID %>, 'Failed', e)
+  log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Failed', e)
   STDOUT.puts('FINISHED RUNNER')
-  STDERR.puts("Failed to run stage <%= stageName(s) %> id <%= s.ID %> : #{e}")
+  STDERR.puts("Failed to run stage " + <%= literal(stageName(s)) %> + " id <%= s.ID %> : #{e}")
   failed_runner(e)
   exit(false)
 end
@@ -351,33 +500,35 @@ begin
   start(<%= s.ID %>)
 
   # Exclude with reason
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Starting Exclude-stage')
-  items = single_case.search('<%= formatQuotes(s.Exclude.Search) %>')
-  log_debug('<%= stageName(s) %>', <%= s.ID %>, "Found #{items.length} from search <%= s.Exclude.Search %> - starts excluding")
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting Exclude-stage')
+  items = single_case.search(<%= literal(s.Exclude.Search) %>)
+  log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, "Found #{items.length} from search " + <%= literal(s.Exclude.Search) %> + " - starts excluding")
   item_count = 0
+  progress(<%= s.ID %>, items.length, 0)
   for item in items
-    item.exclude('<%= s.Exclude.Reason %>')
+    item.exclude(<%= literal(s.Exclude.Reason) %>)
     item_count += 1
-    log_item('<%= stageName(s) %>', <%= s.ID %>, 'Excluded item', item_count, item.type.name, item.guid, '')
+    progress(<%= s.ID %>, items.length, item_count)
+    log_item(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Excluded item', item_count, item.type.name, item.guid, '')
   end
   # Finish the Exclude-stage (update api)
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Finished')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished')
   finish(<%= s.ID %>)
 rescue => e
   # Handle the exception for stage
 
   # Set the Exclude-stage to failed (update api)
   failed(<%= s.ID %>)
-  <%= if (process(runner)) { %>
+  <%= if (hasProcess(runner)) { %>
   # Tear down the cases
   tear_down(single_case, compound_case, review_compound)
   <% } else { %>
   # Tear down the single-case
   tear_down(single_case, nil, nil)
   <% } %>
-  log_error('<%= stageName(s) %>', <%= s.ID %>, 'Failed', e)
+  log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Failed', e)
   STDOUT.puts('FINISHED RUNNER')
-  STDERR.puts("Failed to run stage <%= stageName(s) %> id <%= s.ID %> : #{e}")
+  STDERR.puts("Failed to run stage " + <%= literal(stageName(s)) %> + " id <%= s.ID %> : #{e}")
   failed_runner(e)
   exit(false)
 end
@@ -388,24 +539,24 @@ begin
   start(<%= s.ID %>)
 
   # Ocr
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Starting OCR-stage')
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Creating OCR-processor')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting OCR-stage')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Creating OCR-processor')
   ocr_processor = $utilities.createOcrProcessor
 
   # Check if the profile exists in the store
-  unless $utilities.get_ocr_profile_store.contains_profile('<%= s.Ocr.Profile %>')
+  unless $utilities.get_ocr_profile_store.contains_profile(<%= literal(s.Ocr.Profile) %>)
     # Import the profile
-    log_debug('<%= stageName(s) %>', <%= s.ID %>, 'Did not find the requested ocr-profile in the profile-store')
-    log_info('<%= stageName(s) %>', <%= s.ID %>, 'Importing new ocr-profile from path <%= s.Ocr.ProfilePath %>')
-    $utilities.get_ocr_profile_store.import_profile('<%= s.Ocr.ProfilePath %>', '<%= s.Ocr.Profile %>')
-    log_debug('<%= stageName(s) %>', <%= s.ID %>, 'OCR-profile has been imported')
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Did not find the requested ocr-profile in the profile-store')
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Importing new ocr-profile from path ' + <%= literal(s.Ocr.ProfilePath) %>)
+    $utilities.get_ocr_profile_store.import_profile(<%= literal(s.Ocr.ProfilePath) %>, <%= literal(s.Ocr.Profile) %>)
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'OCR-profile has been imported')
   end
 
-  ocr_profile = $utilities.get_ocr_profile_store.get_profile('<%= s.Ocr.Profile %>')
-  ocr_items = single_case.search('<%= formatQuotes(s.Ocr.Search) %>')
-  log_debug('<%= stageName(s) %>', <%= s.ID %>, "Found #{ocr_items.length} from search: <%= s.Ocr.Search %> - starts ocr")
+  ocr_profile = $utilities.get_ocr_profile_store.get_profile(<%= literal(s.Ocr.Profile) %>)
+  ocr_items = single_case.search(<%= literal(s.Ocr.Search) %>)
+  log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, "Found #{ocr_items.length} from search: " + <%= literal(s.Ocr.Search) %> + " - starts ocr")
   if ocr_items.length == 0 
-    log_info('<%= stageName(s) %>', <%= s.ID %>, 'No OCR items to process - skipping stage')
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'No OCR items to process - skipping stage')
   else
     # Log the info for the items
     ocr_sempahore = Mutex.new
@@ -413,7 +564,7 @@ begin
     ocr_processor.when_item_event_occurs do |info|
       ocr_sempahore.synchronize {
         processed_approx_count += 1
-        log_item('<%= stageName(s) %>', <%= s.ID %>, 'OCR item', info.stage_count, info.item.type.name, info.item.guid, info.stage)
+        log_item(<%= literal(stageName(s)) %>, <%= s.ID %>, 'OCR item', info.stage_count, info.item.type.name, info.item.guid, info.stage)
       }
     end
 
@@ -423,30 +574,32 @@ begin
     total_batches = (ocr_items.size.to_f / target_batch_size.to_f).ceil
 
     ocr_items.each_slice(target_batch_size) do |slice_items|
-      log_info('<%= stageName(s) %>', <%= s.ID %>, "Start ocr-processing batch : #{batch_index+1}/#{total_batches}")
+      progress(<%= s.ID %>, total_batches, batch_index)
+      log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, "Start ocr-processing batch : #{batch_index+1}/#{total_batches}")
       ocr_processor.process(slice_items, ocr_profile)
       batch_index += 1
     end
+    progress(<%= s.ID %>, total_batches, total_batches)
   end
 
   # Finish the OCR-stage (update api)
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Finished')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished')
   finish(<%= s.ID %>)
 rescue => e
   # Handle the exception for stage
 
   # Set the OCR-stage to failed (update api)
   failed(<%= s.ID %>)
-  <%= if (process(runner)) { %>
+  <%= if (hasProcess(runner)) { %>
   # Tear down the cases
   tear_down(single_case, compound_case, review_compound)
   <% } else { %>
   # Tear down the single-case
   tear_down(single_case, nil, nil)
   <% } %>
-  log_error('<%= stageName(s) %>', <%= s.ID %>, 'Failed', e)
+  log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Failed', e)
   STDOUT.puts('FINISHED RUNNER')
-  STDERR.puts("Failed to run stage <%= stageName(s) %> id <%= s.ID %> : #{e}")
+  STDERR.puts("Failed to run stage " + <%= literal(stageName(s)) %> + " id <%= s.ID %> : #{e}")
   failed_runner(e)
   exit(false)
 end
@@ -455,77 +608,79 @@ end
 begin
   # Start Populate-stage (update api)
   start(<%= s.ID %>)
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Starting stage')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting stage')
 
   # Populate stage
   tmpdir = Dir.tmpdir
   dir = "#{tmpdir}/populate"
   unless Dir.exist?(dir)
-    log_info('<%= stageName(s) %>', <%= s.ID %>, "Creating tmp-dir: #{dir} for export")
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, "Creating tmp-dir: #{dir} for export")
     FileUtils.mkdir_p(dir)
   end
 
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Creating batch-exporter with tmp-dir for populate')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Creating batch-exporter with tmp-dir for populate')
   exporter = $utilities.create_batch_exporter(dir)
   <%= for (t) in s.Populate.Types { %>
   <%= if (t.Type == "native") { %>
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Adding Native-product to exporter')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Adding Native-product to exporter')
   exporter.addProduct("native",{
     "naming" => "guid",
     "path" => "Natives",
     "regenerateStored" => true,
   })
   <% } %><%= if (t.Type == "pdf") { %>
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Adding PDF-product to exporter')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Adding PDF-product to exporter')
   exporter.addProduct("pdf",{
     "naming" => "guid",
     "path" => "PDFs",
     "regenerateStored" => true,
   })
   <% } %><% } %>
-  items = single_case.search('<%= formatQuotes(s.Populate.Search) %>')
-  log_debug('<%= stageName(s) %>', <%= s.ID %>, "Found #{items.length} items from search: <%= s.Populate.Search %> - starts export for populate")
+  items = single_case.search(<%= literal(s.Populate.Search) %>)
+  log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, "Found #{items.length} items from search: " + <%= literal(s.Populate.Search) %> + " - starts export for populate")
 
   # Used to synchronize thread access in batch exported callback
   semaphore = Mutex.new
+  progress(<%= s.ID %>, items.length, 0)
 
   # Setup batch exporter callback
   exporter.when_item_event_occurs do |info|
     if !info.failure.nil?
-      log_error('<%= stageName(s) %>', <%= s.ID %>, "Export failure for item: #{info.item.guid} : #{info.item.localised_name}", '')
+      log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, "Export failure for item: #{info.item.guid} : #{info.item.localised_name}", '')
     end
     # Make the progress reporting have some thread safety
     semaphore.synchronize {
+      progress(<%= s.ID %>, items.length, info.stage_count)
       log_item('Populate', <%= s.ID %>, 'Exporting item', info.stage_count, info.item.type.name, info.item.guid, info.stage)
     }
   end
 
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Starting export of items')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting export of items')
   exporter.export_items(items)
-  log_debug('<%= stageName(s) %>', <%= s.ID %>, 'Finished export of items')
+  log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished export of items')
 
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Removing tmp-dir')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Removing tmp-dir')
   FileUtils.rm_rf(dir)
-  log_debug('<%= stageName(s) %>', <%= s.ID %>, 'Removed tmp-dir')
+  log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Removed tmp-dir')
 
   # Finish the Populate-stage (update api)
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Finished')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished')
   finish(<%= s.ID %>)
 rescue => e
   # Handle the exception for stage
 
   # Set the Populate-stage to failed (update api)
   failed(<%= s.ID %>)
-  <%= if (process(runner)) { %>
+  <%= if (hasProcess(runner)) { %>
   # Tear down the cases
   tear_down(single_case, compound_case, review_compound)
   <% } else { %>
   # Tear down the single-case
   tear_down(single_case, nil, nil)
   <% } %>
-  log_error('<%= stageName(s) %>', <%= s.ID %>, 'Failed', e)
+  log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Failed', e)
   STDOUT.puts('FINISHED RUNNER')
-  STDERR.puts("Failed to run stage <%= stageName(s) %> id <%= s.ID %> : #{e}")
+  STDERR.puts("Failed to run stage " + <%= literal(stageName(s)) %> + " id <%= s.ID %> : #{e}")
   failed_runner(e)
   exit(false)
 end
@@ -536,65 +691,67 @@ begin
   start(<%= s.ID %>)
 
   # Reload stage
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Starting Reload-stage')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starting Reload-stage')
 
   # Check if the profile exists in the profile-store
-  unless $utilities.get_processing_profile_store.contains_profile('<%= s.Reload.Profile %>')
+  unless $utilities.get_processing_profile_store.contains_profile(<%= literal(s.Reload.Profile) %>)
     # Import the profile
-    log_debug('<%= stageName(s) %>', <%= s.ID %>, 'Did not find the requested processing-profile for reload in the profile-store')
-    log_info('<%= stageName(s) %>', <%= s.ID %>, 'Importing new processing-profile from <%= s.Reload.ProfilePath %>')
-    $utilities.get_processing_profile_store.import_profile('<%= s.Reload.ProfilePath %>', '<%= s.Reload.Profile %>')
-    log_debug('<%= stageName(s) %>', <%= s.ID %>, 'Processing-profile has been imported')
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Did not find the requested processing-profile for reload in the profile-store')
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Importing new processing-profile from ' + <%= literal(s.Reload.ProfilePath) %>)
+    $utilities.get_processing_profile_store.import_profile(<%= literal(s.Reload.ProfilePath) %>, <%= literal(s.Reload.Profile) %>)
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Processing-profile has been imported')
   end
 
-  items = single_case.search('<%= formatQuotes(s.Reload.Search) %>')
-  log_debug('<%= stageName(s) %>', <%= s.ID %>, "Found #{items.length} items from search: <%= s.Reload.Search %>")
+  items = single_case.search(<%= literal(s.Reload.Search) %>)
+  log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, "Found #{items.length} items from search: " + <%= literal(s.Reload.Search) %>)
   
-  log_info('<%= stageName(s) %>', <%= s.ID %>, 'Creating reload_processor')
+  log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Creating reload_processor')
   reload_processor = single_case.create_processor
-  log_debug('<%= stageName(s) %>', <%= s.ID %>, 'Created reload_processor')
-  reload_processor.set_processing_profile('<%= s.Reload.Profile %>')
+  log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Created reload_processor')
+  reload_processor.set_processing_profile(<%= literal(s.Reload.Profile) %>)
   reload_processor.reload_items_from_source_data(items)
   
   # Handle item-information from reload-processor
   sempahore = Mutex.new
   reload_count = 0
+  progress(<%= s.ID %>, items.length, 0)
   reload_processor.when_item_processed do |info|
     semaphore.synchronize {
       reload_count += 1
+      progress(<%= s.ID %>, items.length, reload_count)
       log_item('Reload', <%= s.ID %>, 'Reloaded item', reload_count, info.mime_type, info.guid_path, '')
     }
   end
   
   # Start the processing
   if items.length > 0
-    log_info('<%= stageName(s) %>', <%= s.ID %>, 'Starts the reload-processing')
+    log_info(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Starts the reload-processing')
     reload_processor.process
-    log_debug('<%= stageName(s) %>', <%= s.ID %>, 'Finished the reload-processing')
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished the reload-processing')
   else
-    log_debug('<%= stageName(s) %>', <%= s.ID %>, 'No items to process for reload')
+    log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'No items to process for reload')
   end
 
   # Finish the Reload-stage (update api)
-  log_debug('<%= stageName(s) %>', <%= s.ID %>, 'Finished')
+  log_debug(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Finished')
   finish(<%= s.ID %>)
 rescue => e
   # Handle the exception for stage
 
   # Set the Reload-stage to failed (update api)
   failed(<%= s.ID %>)
-  <%= if (process(runner)) { %>
+  <%= if (hasProcess(runner)) { %>
   # Tear down the cases
   tear_down(single_case, compound_case, review_compound)
   <% } else { %>
   # Tear down the single-case
   tear_down(single_case, nil, nil)
   <% } %>
-  log_error('<%= stageName(s) %>', <%= s.ID %>, 'Failed', e)
+  log_error(<%= literal(stageName(s)) %>, <%= s.ID %>, 'Failed', e)
   STDOUT.puts('FINISHED RUNNER')
-  STDERR.puts("Failed to run stage <%= stageName(s) %> id <%= s.ID %> : #{e}")
+  STDERR.puts("Failed to run stage " + <%= literal(stageName(s)) %> + " id <%= s.ID %> : #{e}")
   failed_runner(e)
   exit(false)
-end<% } %><% } %><% } %><% } %>
+end<% } %><% } %>
 STDOUT.puts('FINISHED RUNNER')
 finish_runner`
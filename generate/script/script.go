@@ -0,0 +1,63 @@
+// Package script generates the scripts
+// for the runners in the supported engines
+package script
+
+import (
+	"fmt"
+
+	"github.com/avian-digital-forensics/auto-processing/generate/python"
+	"github.com/avian-digital-forensics/auto-processing/generate/ruby"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+)
+
+// Engines for the scripts
+const (
+	Ruby   = "ruby"
+	Python = "python"
+)
+
+// Generator generates the script for a runner
+type Generator interface {
+	// Generate returns the script for the runner,
+	// remoteAddress is the address for the callbacks and
+	// ca is the pinned CA (pem) if the service uses TLS
+	Generate(remoteAddress, ca string, runner api.Runner) (string, error)
+
+	// Extension returns the file-extension
+	// for the scripts (used by nuix_console)
+	Extension() string
+}
+
+// New returns the generator for the engine
+func New(engine string) (Generator, error) {
+	switch engine {
+	case "", Ruby:
+		return ruby.Generator{}, nil
+	case Python:
+		return python.Generator{}, nil
+	}
+	return nil, fmt.Errorf("invalid script-engine: %s - specify '%s' or '%s'", engine, Ruby, Python)
+}
+
+// Engine returns the engine to use for the runner,
+// the engine for the runner has precedence over the server
+func Engine(runner api.Runner, server api.Server) string {
+	if runner.Engine != "" {
+		return runner.Engine
+	}
+
+	if server.Engine != "" {
+		return server.Engine
+	}
+	return Ruby
+}
+
+// FileName returns the name for the script-file of the runner, with
+// the extension for the engine of the runner or server
+func FileName(runner api.Runner, server api.Server) (string, error) {
+	generator, err := New(Engine(runner, server))
+	if err != nil {
+		return "", err
+	}
+	return runner.Name + ".gen" + generator.Extension(), nil
+}
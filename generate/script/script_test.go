@@ -0,0 +1,211 @@
+package script_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/generate/script"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/matryer/is"
+)
+
+var update = flag.Bool("update", false, "update the golden-files in testdata")
+
+// hostile is a value trying to break out of the string-literals
+const hostile = `O'Brien "quoted" \ #{system('calc')} & <b>
+second line`
+
+// ca is a pinned CA for the TLS-connection to the service
+const ca = `-----BEGIN CERTIFICATE-----
+MIIBdzCCAR2gAwIBAgIQAvianTestCertificate0wCgYIKoZIzj0EAwIwHTEOMAwG
+-----END CERTIFICATE-----
+`
+
+func newCase(name string) *api.Case {
+	return &api.Case{
+		Name:         name,
+		Directory:    `C:\Cases\` + name,
+		Description:  "Description for " + name,
+		Investigator: "Investigator",
+	}
+}
+
+func newRunner(stages ...api.Stage) api.Runner {
+	runner := api.Runner{
+		Name:  "runner",
+		RunID: "5b0f3c1e9d2a4b7c8e6f1a2b3c4d5e6f",
+		CaseSettings: &api.CaseSettings{
+			Case:           newCase("single"),
+			CompoundCase:   newCase("compound"),
+			ReviewCompound: newCase("review"),
+		},
+	}
+	runner.ID = 1
+
+	for i := range stages {
+		stage := stages[i]
+		stage.ID = uint(i + 1)
+		runner.Stages = append(runner.Stages, &stage)
+	}
+	return runner
+}
+
+func newProcess() *api.Process {
+	return &api.Process{
+		Profile:     "Default",
+		ProfilePath: `C:\Profiles\Default.xml`,
+		EvidenceStore: []*api.Evidence{
+			{
+				Name:        "Evidence",
+				Directory:   `C:\Evidence`,
+				Description: "Evidence for the case",
+				Encoding:    "UTF-8",
+				TimeZone:    "Europe/Stockholm",
+				Custodian:   "Custodian",
+				Locale:      "sv-SE",
+				Paths:       []*api.EvidencePath{{Path: `D:\Evidence`}},
+				PathList:    `C:\Evidence\paths.txt`,
+				LoadFile:    `C:\Evidence\load.dat`,
+				Filters: []*api.EvidenceFilter{
+					{Pattern: "**/*.pst"},
+					{Pattern: "**/~*", Exclude: true},
+				},
+				Metadata: []*api.EvidenceMetadata{{Key: "Matter", Value: "M-1"}},
+			},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	failed := newProcess()
+	failed.Status = avian.StatusFailed
+
+	// the evidence is only the load-file (the metadata for the load-file in Nuix)
+	loadFile := &api.Process{
+		Profile:     "Default",
+		ProfilePath: `C:\Profiles\Default.xml`,
+		EvidenceStore: []*api.Evidence{
+			{Name: "Production", LoadFile: `\\fs01\Productions\VOL001\VOL001.dat`},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		runner api.Runner
+	}{
+		{"process", newRunner(api.Stage{Process: newProcess()})},
+		{"process-failed", newRunner(api.Stage{Process: failed})},
+		{"load-file", newRunner(api.Stage{Process: loadFile})},
+		{"search-and-tag", newRunner(api.Stage{SearchAndTag: &api.SearchAndTag{Search: "kind:email", Tag: "Email"}})},
+		{"search-and-tag-files", newRunner(api.Stage{SearchAndTag: &api.SearchAndTag{
+			Files: []*api.File{{Path: `C:\Searches\terms.csv`}},
+		}})},
+		{"exclude", newRunner(api.Stage{Exclude: &api.Exclude{Search: "kind:system", Reason: "System files"}})},
+		{"ocr", newRunner(api.Stage{Ocr: &api.Ocr{Profile: "OCR", ProfilePath: `C:\Profiles\OCR.xml`, Search: "kind:image"}})},
+		{"populate", newRunner(api.Stage{Populate: &api.Populate{
+			Search: "kind:document",
+			Types:  []*api.Type{{Type: "native"}, {Type: "pdf"}},
+		}})},
+		{"reload", newRunner(api.Stage{Reload: &api.Reload{Profile: "Reload", ProfilePath: `C:\Profiles\Reload.xml`, Search: "flag:encrypted"}})},
+		{"hostile", hostileRunner()},
+	}
+
+	for _, engine := range []string{script.Ruby, script.Python} {
+		generator, err := script.New(engine)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tt := range tests {
+			t.Run(engine+"/"+tt.name, func(t *testing.T) {
+				is := is.New(t)
+
+				got, err := generator.Generate("http://localhost:8080/oto/", "", tt.runner)
+				is.NoErr(err)
+
+				golden(is, engine, tt.name, got)
+			})
+		}
+	}
+}
+
+// TestGenerateTLS generates the scripts with a pinned CA
+func TestGenerateTLS(t *testing.T) {
+	runner := newRunner(api.Stage{Exclude: &api.Exclude{Search: "kind:system", Reason: "System files"}})
+	for _, engine := range []string{script.Ruby, script.Python} {
+		is := is.New(t)
+
+		generator, err := script.New(engine)
+		is.NoErr(err)
+
+		got, err := generator.Generate("https://localhost:8080/oto/", ca, runner)
+		is.NoErr(err)
+		golden(is, engine, "tls", got)
+	}
+}
+
+// golden compares the script with the golden-file
+func golden(is *is.I, engine, name, got string) {
+	path := filepath.Join("testdata", engine, name+".golden")
+	if *update {
+		is.NoErr(ioutil.WriteFile(path, []byte(got), 0644))
+	}
+
+	want, err := ioutil.ReadFile(path)
+	is.NoErr(err)
+	is.Equal(got, string(want)) // script differs from golden-file (run with -update)
+}
+
+func hostileRunner() api.Runner {
+	process := newProcess()
+	process.Profile = hostile
+	process.ProfilePath = hostile
+	evidence := process.EvidenceStore[0]
+	evidence.Name = hostile
+	evidence.Directory = hostile
+	evidence.Description = hostile
+	evidence.Custodian = hostile
+	evidence.Paths = []*api.EvidencePath{{Path: hostile}}
+	evidence.PathList = hostile
+	evidence.LoadFile = hostile
+	evidence.Filters = []*api.EvidenceFilter{{Pattern: hostile}, {Pattern: hostile, Exclude: true}}
+	evidence.Metadata = []*api.EvidenceMetadata{{Key: hostile, Value: hostile}}
+
+	runner := newRunner(
+		api.Stage{Process: process},
+		api.Stage{SearchAndTag: &api.SearchAndTag{Search: "name:'O'Brien'", Tag: hostile}},
+		api.Stage{SearchAndTag: &api.SearchAndTag{Files: []*api.File{{Path: hostile}}}},
+		api.Stage{Exclude: &api.Exclude{Search: hostile, Reason: hostile}},
+		api.Stage{Ocr: &api.Ocr{Profile: hostile, ProfilePath: hostile, Search: hostile}},
+		api.Stage{Populate: &api.Populate{Search: hostile, Types: []*api.Type{{Type: "native"}}}},
+		api.Stage{Reload: &api.Reload{Profile: hostile, ProfilePath: hostile, Search: hostile}},
+	)
+	runner.Name = hostile
+	runner.CaseSettings.Case = newCase(hostile)
+	return runner
+}
+
+func TestFileName(t *testing.T) {
+	is := is.New(t)
+
+	for _, tt := range []struct {
+		runner   api.Runner
+		server   api.Server
+		expected string
+	}{
+		{api.Runner{Name: "runner"}, api.Server{}, "runner.gen.rb"},
+		{api.Runner{Name: "runner", Engine: script.Python}, api.Server{}, "runner.gen.py"},
+		{api.Runner{Name: "runner"}, api.Server{Engine: script.Python}, "runner.gen.py"},
+		{api.Runner{Name: "runner", Engine: script.Ruby}, api.Server{Engine: script.Python}, "runner.gen.rb"},
+	} {
+		name, err := script.FileName(tt.runner, tt.server)
+		is.NoErr(err)
+		is.Equal(name, tt.expected)
+	}
+
+	_, err := script.FileName(api.Runner{Name: "runner", Engine: "perl"}, api.Server{})
+	is.True(err != nil)
+}
@@ -14,6 +14,7 @@ require (
 	github.com/natefinch/lumberjack v2.0.0+incompatible
 	github.com/pacedotdev/oto/otohttp v0.8.0
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.7.1
 	github.com/simonjanss/go-powershell v0.0.0-20200903093105-20b430117a11
 	github.com/spf13/cobra v1.0.0
 	go.uber.org/zap v1.16.0
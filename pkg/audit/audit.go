@@ -0,0 +1,196 @@
+// Package audit records the administrative actions
+// on the runners, servers and nms in the audit-log
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+)
+
+const (
+	// OutcomeOK is the outcome for the requests that succeeded
+	OutcomeOK = "ok"
+
+	// OutcomeFailed is the outcome for the requests that failed
+	OutcomeFailed = "failed"
+
+	// Anonymous is the actor for the requests without an api-token
+	Anonymous = "anonymous"
+
+	// maxBodySize is the max size of a request-body
+	maxBodySize = 32 << 20
+
+	// maxRequest is the max size of the request-summary
+	maxRequest = 4096
+)
+
+// methods are the administrative methods that are recorded in
+// the audit-log, the callbacks from the runner-scripts are not
+var methods = map[string]bool{
+	"RunnerService.Apply":          true,
+	"RunnerService.Delete":         true,
+	"RunnerService.Restore":        true,
+	"RunnerService.Purge":          true,
+	"ServerService.Apply":          true,
+	"ServerService.Delete":         true,
+	"ServerService.VerifyPassword": true,
+	"NmsService.Apply":             true,
+	"NmsService.Delete":            true,
+	"NmsService.VerifyPassword":    true,
+}
+
+// redacted are the keys (in lowercase) for
+// the secrets in the request-bodies
+var redacted = map[string]bool{
+	"password": true,
+	"secret":   true,
+	"token":    true,
+}
+
+// Audited returns true if the method is recorded in the audit-log
+func Audited(method string) bool {
+	return methods[method]
+}
+
+// Handler records the requests for the administrative methods in
+// the audit-log after they are handled by next, the errors from
+// the services are recorded with OnErr for the oto-server
+func Handler(db *gorm.DB, logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := path.Base(r.URL.Path)
+		if r.Method != http.MethodPost || !methods[method] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			logger.Error("Cannot read request-body", zap.String("method", method), zap.String("exception", err.Error()))
+			http.Error(w, "cannot read request-body", http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		res := &result{}
+		rec := &recorder{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), resultKey, res)))
+
+		entry := api.AuditEntry{
+			Actor:   Anonymous,
+			Remote:  r.RemoteAddr,
+			Method:  method,
+			Request: Redact(body),
+			Outcome: OutcomeOK,
+		}
+		if token, ok := auth.FromContext(r.Context()); ok {
+			entry.Actor = token.Name
+			entry.Key = token.Key
+		} else if r.TLS != nil && len(r.TLS.VerifiedChains) != 0 {
+			entry.Actor = r.TLS.VerifiedChains[0][0].Subject.CommonName
+		}
+		if res.err != nil {
+			entry.Outcome = OutcomeFailed
+			entry.Reason = res.err.Error()
+		} else if rec.code >= http.StatusBadRequest {
+			entry.Outcome = OutcomeFailed
+			entry.Reason = http.StatusText(rec.code)
+		}
+
+		if err := db.Create(&entry).Error; err != nil {
+			logger.Error("Cannot record the request in the audit-log",
+				zap.String("method", method),
+				zap.String("actor", entry.Actor),
+				zap.String("outcome", entry.Outcome),
+				zap.String("exception", err.Error()),
+			)
+		}
+	})
+}
+
+// OnErr records the error for the audit-log before it is handled by
+// next, it wraps the OnErr for the oto-server:
+//
+//	server.OnErr = audit.OnErr(server.OnErr)
+func OnErr(next func(http.ResponseWriter, *http.Request, error)) func(http.ResponseWriter, *http.Request, error) {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		if res, ok := r.Context().Value(resultKey).(*result); ok {
+			res.err = err
+		}
+		next(w, r, err)
+	}
+}
+
+// Redact returns the request-body as a summary for the audit-log,
+// the secrets are redacted and the summary is truncated to 4kB
+func Redact(body []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return "(invalid request-body)"
+	}
+
+	b, err := json.Marshal(redact(v))
+	if err != nil {
+		return "(invalid request-body)"
+	}
+	if len(b) > maxRequest {
+		return string(b[:maxRequest]) + "..."
+	}
+	return string(b)
+}
+
+// redact redacts the values for the secrets in v, the
+// references to the credentials are kept as they are
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if !redacted[strings.ToLower(key)] {
+				v[key] = redact(value)
+				continue
+			}
+			if s, ok := value.(string); ok {
+				v[key] = secrets.Mask(s)
+			} else if value != nil {
+				v[key] = secrets.Masked
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redact(v[i])
+		}
+	}
+	return v
+}
+
+// contextKey is the type for the values in the request-context
+type contextKey string
+
+// resultKey is the key for the result in the request-context
+const resultKey contextKey = "audit"
+
+// result is the result of the request for the audit-log
+type result struct {
+	err error
+}
+
+// recorder records the status-code of the response
+type recorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *recorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
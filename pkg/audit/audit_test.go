@@ -0,0 +1,109 @@
+package audit_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/audit"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/dbtest"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"github.com/matryer/is"
+	"github.com/pacedotdev/oto/otohttp"
+	"go.uber.org/zap"
+)
+
+func request(method, value, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/oto/"+method, bytes.NewBufferString(body))
+	key, secret, _ := auth.Parse(value)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, _ := auth.NewNonce()
+	req.Header.Set(auth.HeaderKey, key)
+	req.Header.Set(auth.HeaderTimestamp, timestamp)
+	req.Header.Set(auth.HeaderNonce, nonce)
+	req.Header.Set(auth.HeaderSignature, auth.Sign([]byte(secret), req.Method, req.URL.RequestURI(), timestamp, nonce, []byte(body)))
+	return req
+}
+
+func TestHandler(t *testing.T) {
+	is := is.New(t)
+
+	db := dbtest.Open(t)
+	is.NoErr(db.AutoMigrate(&api.Token{}, &api.AuditEntry{}).Error)
+
+	key, err := secrets.NewKey()
+	is.NoErr(err)
+	cipher, err := secrets.New(key)
+	is.NoErr(err)
+
+	token, err := auth.New("ci", 0)
+	is.NoErr(err)
+	value, err := auth.Create(db, cipher, token)
+	is.NoErr(err)
+
+	// the services fail to delete the nms
+	server := otohttp.NewServer()
+	server.OnErr = audit.OnErr(server.OnErr)
+	oto := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "NmsService.Delete") {
+			server.OnErr(w, r, errors.New("nms not found"))
+			return
+		}
+		otohttp.Encode(w, r, http.StatusOK, struct{}{})
+	})
+	handler := auth.Handler(db, cipher, zap.NewNop(), audit.Handler(db, zap.NewNop(), oto))
+
+	for _, req := range []*http.Request{
+		request("NmsService.Apply", value, `{"nms":[{"address":"nms","password":"hunter2","workers":8}]}`),
+		request("ServerService.Apply", value, `{"hostname":"dev01","password":"env:DEV01_PASSWORD"}`),
+		request("NmsService.Delete", value, `{"address":"nms"}`),
+		request("ServerService.VerifyPassword", value, `{"hostname":"dev01","password":"hunter2"}`),
+		request("RunnerService.List", value, `{}`),
+		request("RunnerService.Heartbeat", value, `{"runner":"runner"}`),
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// only the administrative requests are recorded
+	var entries []api.AuditEntry
+	is.NoErr(db.Order("id").Find(&entries).Error)
+	is.Equal(len(entries), 4)
+
+	for _, entry := range entries {
+		is.Equal(entry.Actor, "ci")
+		is.Equal(entry.Key, token.Key)
+		is.True(entry.CTime != 0)
+	}
+
+	is.Equal(entries[0].Method, "NmsService.Apply")
+	is.Equal(entries[0].Outcome, audit.OutcomeOK)
+	is.Equal(entries[0].Request, `{"nms":[{"address":"nms","password":"********","workers":8}]}`)
+
+	// the references are not secrets
+	is.Equal(entries[1].Request, `{"hostname":"dev01","password":"env:DEV01_PASSWORD"}`)
+
+	is.Equal(entries[2].Method, "NmsService.Delete")
+	is.Equal(entries[2].Outcome, audit.OutcomeFailed)
+	is.Equal(entries[2].Reason, "nms not found")
+
+	// the verifications of the passwords are recorded without the password
+	is.Equal(entries[3].Method, "ServerService.VerifyPassword")
+	is.Equal(entries[3].Request, `{"hostname":"dev01","password":"********"}`)
+}
+
+func TestRedact(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(audit.Redact([]byte(`{"Token":{"secret":"s"},"value":"v"}`)), `{"Token":"********","value":"v"}`)
+	is.Equal(audit.Redact([]byte(`not json`)), "(invalid request-body)")
+
+	long := audit.Redact([]byte(`{"name":"` + strings.Repeat("a", 5000) + `"}`))
+	is.Equal(len(long), 4096+len("..."))
+}
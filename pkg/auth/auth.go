@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// HeaderKey is the header for the key of the api-token
+	HeaderKey = "X-API-KEY"
+
+	// HeaderSignature is the header for the HMAC-signature
+	// of the method, request-uri, timestamp, nonce and body
+	HeaderSignature = "X-API-SIGNATURE"
+
+	// HeaderTimestamp is the header for the time (unix-time)
+	// the request was signed
+	HeaderTimestamp = "X-API-TIMESTAMP"
+
+	// HeaderNonce is the header for the random value that is
+	// unique for every request, the service rejects the requests
+	// with a nonce it has already seen (the replayed requests)
+	HeaderNonce = "X-API-NONCE"
+
+	// MaxNonce is the max length of a nonce
+	MaxNonce = 64
+
+	// MaxSkew is how far the timestamp of a request can be from
+	// the time of the service, the requests with older timestamps
+	// are rejected - so the nonces are only kept for as long
+	MaxSkew = 5 * time.Minute
+
+	// RunnerTTL is how long the token for a runner-script
+	// is valid, it is extended by the heartbeats of the runner
+	RunnerTTL = 30 * time.Minute
+)
+
+// New returns a new api-token with a random key and secret,
+// the token never expires if expires is zero
+func New(name string, expires time.Duration) (*api.Token, error) {
+	key, err := random(8)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate key: %v", err)
+	}
+	secret, err := random(32)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate secret: %v", err)
+	}
+
+	token := &api.Token{Name: name, Key: key, Secret: secret}
+	if expires != 0 {
+		token.ExpiresAt = time.Now().Add(expires).Unix()
+	}
+	return token, nil
+}
+
+// Value returns the value (key.secret) of the token for the clients
+func Value(token api.Token) string {
+	return token.Key + "." + token.Secret
+}
+
+// Parse returns the key and the secret from the value of a token
+func Parse(value string) (string, string, error) {
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid token - expected key.secret")
+	}
+	return parts[0], parts[1], nil
+}
+
+// Expired returns true if the token has expired
+func Expired(token api.Token, now time.Time) bool {
+	return token.ExpiresAt != 0 && now.Unix() >= token.ExpiresAt
+}
+
+// Sign returns the HMAC-signature for the request
+func Sign(secret []byte, method, uri, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(message(method, uri, timestamp, nonce, body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if the signature is valid for the request
+func Verify(secret []byte, method, uri, timestamp, nonce string, body []byte, signature string) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(message(method, uri, timestamp, nonce, body))
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+// Fresh returns true if the timestamp (unix-time)
+// is within MaxSkew from now
+func Fresh(timestamp string, now time.Time) bool {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	skew := now.Sub(time.Unix(unix, 0))
+	return skew <= MaxSkew && skew >= -MaxSkew
+}
+
+// NewNonce returns a new random nonce for a request
+func NewNonce() (string, error) {
+	return random(16)
+}
+
+// message returns the message to sign for the request (the
+// method, request-uri, timestamp, nonce and body separated by newlines)
+func message(method, uri, timestamp, nonce string, body []byte) []byte {
+	return append([]byte(strings.Join([]string{method, uri, timestamp, nonce}, "\n")+"\n"), body...)
+}
+
+// Create stores the token with the secret encrypted with
+// the master-key, it returns the value of the token
+func Create(db *gorm.DB, c *secrets.Cipher, token *api.Token) (string, error) {
+	value := Value(*token)
+	secret, err := c.Encrypt(token.Secret)
+	if err != nil {
+		return "", fmt.Errorf("cannot encrypt secret: %v", err)
+	}
+	token.Secret = secret
+	if err := db.Create(token).Error; err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// Initial creates an admin-token for the clients if there are none,
+// the value is empty if the service already has tokens
+func Initial(db *gorm.DB, c *secrets.Cipher) (string, error) {
+	var count int
+	if err := db.Model(&api.Token{}).Where("runner_id = ?", 0).Count(&count).Error; err != nil {
+		return "", fmt.Errorf("cannot count tokens: %v", err)
+	}
+	if count != 0 {
+		return "", nil
+	}
+
+	token, err := New("initial", 0)
+	if err != nil {
+		return "", err
+	}
+	token.Admin = true
+	value, err := Create(db, c, token)
+	if err != nil {
+		return "", fmt.Errorf("cannot create initial token: %v", err)
+	}
+	return value, nil
+}
+
+// NewRunnerToken creates a token for the runner-script that is
+// only valid for the callbacks of the runner, the previous
+// tokens for the runner are revoked
+func NewRunnerToken(db *gorm.DB, c *secrets.Cipher, runner api.Runner) (string, error) {
+	if err := Revoke(db, runner.ID); err != nil {
+		return "", err
+	}
+
+	token, err := New("runner: "+runner.Name, RunnerTTL)
+	if err != nil {
+		return "", err
+	}
+	token.RunnerID = runner.ID
+	token.Runner = runner.Name
+	value, err := Create(db, c, token)
+	if err != nil {
+		return "", fmt.Errorf("cannot create token for runner: %v", err)
+	}
+	return value, nil
+}
+
+// NewRunID returns a unique id for a run of a runner,
+// the callbacks from the other runs are rejected
+func NewRunID() (string, error) {
+	id, err := random(16)
+	if err != nil {
+		return "", fmt.Errorf("cannot generate run-id: %v", err)
+	}
+	return id, nil
+}
+
+// Extend extends the tokens for the runner with the RunnerTTL
+func Extend(db *gorm.DB, runnerID uint) error {
+	expires := time.Now().Add(RunnerTTL).Unix()
+	if err := db.Model(&api.Token{}).Where("runner_id = ?", runnerID).Update("expires_at", expires).Error; err != nil {
+		return fmt.Errorf("cannot extend tokens for runner: %v", err)
+	}
+	return nil
+}
+
+// Revoke revokes the tokens for the runner
+func Revoke(db *gorm.DB, runnerID uint) error {
+	if runnerID == 0 {
+		return fmt.Errorf("cannot revoke tokens without a runner")
+	}
+	if err := db.Where("runner_id = ?", runnerID).Delete(&api.Token{}).Error; err != nil {
+		return fmt.Errorf("cannot revoke tokens for runner: %v", err)
+	}
+	return nil
+}
+
+func random(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
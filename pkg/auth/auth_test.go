@@ -0,0 +1,190 @@
+package auth_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/dbtest"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func request(method, value, body string) *http.Request {
+	return signed(method, value, body, time.Now())
+}
+
+// signed returns the request signed at the time
+func signed(method, value, body string, at time.Time) *http.Request {
+	nonce, _ := auth.NewNonce()
+	return signedNonce(method, value, body, at, nonce)
+}
+
+// signedNonce returns the request signed at the time with the nonce
+func signedNonce(method, value, body string, at time.Time, nonce string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/oto/"+method, bytes.NewBufferString(body))
+	if value != "" {
+		key, secret, _ := auth.Parse(value)
+		timestamp := strconv.FormatInt(at.Unix(), 10)
+		req.Header.Set(auth.HeaderKey, key)
+		req.Header.Set(auth.HeaderTimestamp, timestamp)
+		req.Header.Set(auth.HeaderNonce, nonce)
+		req.Header.Set(auth.HeaderSignature, auth.Sign([]byte(secret), req.Method, req.URL.RequestURI(), timestamp, nonce, []byte(body)))
+	}
+	return req
+}
+
+func newCipher(is *is.I) *secrets.Cipher {
+	key, err := secrets.NewKey()
+	is.NoErr(err)
+	c, err := secrets.New(key)
+	is.NoErr(err)
+	return c
+}
+
+func TestHandler(t *testing.T) {
+	is := is.New(t)
+
+	db := dbtest.Open(t)
+	is.NoErr(db.AutoMigrate(&api.Token{}, &api.Stage{}).Error)
+
+	cipher := newCipher(is)
+
+	cli, err := auth.Initial(db, cipher)
+	is.NoErr(err)
+	is.True(cli != "")
+
+	// only one initial token is created
+	again, err := auth.Initial(db, cipher)
+	is.NoErr(err)
+	is.Equal(again, "")
+
+	// the secret is encrypted in the db
+	_, secret, err := auth.Parse(cli)
+	is.NoErr(err)
+	var stored api.Token
+	is.NoErr(db.First(&stored).Error)
+	is.True(secrets.Encrypted(stored.Secret))
+	decrypted, err := cipher.Decrypt(stored.Secret)
+	is.NoErr(err)
+	is.Equal(decrypted, secret)
+
+	runner := api.Runner{Name: "runner"}
+	runner.ID = 1
+	is.NoErr(db.Create(&api.Stage{RunnerID: 1}).Error)
+	is.NoErr(db.Create(&api.Stage{RunnerID: 2}).Error)
+	script, err := auth.NewRunnerToken(db, cipher, runner)
+	is.NoErr(err)
+
+	expired, err := auth.New("expired", time.Nanosecond)
+	is.NoErr(err)
+	is.NoErr(db.Create(expired).Error)
+
+	handler := auth.Handler(db, cipher, zap.NewNop(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// tamper with the body after it has been signed
+	tampered := request("RunnerService.List", cli, `{}`)
+	tampered.Body = http.NoBody
+
+	// replay the signed request to another method
+	replayed := request("RunnerService.List", cli, `{}`)
+	replayed.URL.Path = "/oto/RunnerService.Delete"
+
+	// the request without a timestamp
+	untimed := request("RunnerService.List", cli, `{}`)
+	untimed.Header.Del(auth.HeaderTimestamp)
+
+	// the request without a nonce
+	unnonced := request("RunnerService.List", cli, `{}`)
+	unnonced.Header.Del(auth.HeaderNonce)
+
+	// the captured request is sent again
+	nonce, err := auth.NewNonce()
+	is.NoErr(err)
+	captured := signedNonce("RunnerService.Delete", cli, `{"name":"runner"}`, time.Now(), nonce)
+	resent := signedNonce("RunnerService.Delete", cli, `{"name":"runner"}`, time.Now(), nonce)
+
+	// the nonce is signed
+	changed := request("RunnerService.List", cli, `{}`)
+	changed.Header.Set(auth.HeaderNonce, nonce+"0")
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"client", request("RunnerService.List", cli, `{}`), http.StatusOK},
+		{"no token", request("RunnerService.List", "", `{}`), http.StatusUnauthorized},
+		{"unknown key", request("RunnerService.List", "abc.def", `{}`), http.StatusUnauthorized},
+		{"expired", request("RunnerService.List", auth.Value(*expired), `{}`), http.StatusUnauthorized},
+		{"tampered", tampered, http.StatusUnauthorized},
+		{"replayed", replayed, http.StatusUnauthorized},
+		{"no timestamp", untimed, http.StatusUnauthorized},
+		{"no nonce", unnonced, http.StatusUnauthorized},
+		{"captured", captured, http.StatusOK},
+		{"sent again", resent, http.StatusUnauthorized},
+		{"changed nonce", changed, http.StatusUnauthorized},
+		{"stale", signed("RunnerService.List", cli, `{}`, time.Now().Add(-auth.MaxSkew-time.Minute)), http.StatusUnauthorized},
+		{"future", signed("RunnerService.List", cli, `{}`, time.Now().Add(auth.MaxSkew+time.Minute)), http.StatusUnauthorized},
+		{"runner callback", request("RunnerService.Heartbeat", script, `{"runner":"runner","id":1}`), http.StatusOK},
+		{"runner stage", request("RunnerService.FinishStage", script, `{"runner":"runner","stageID":1}`), http.StatusOK},
+		{"other runner", request("RunnerService.Heartbeat", script, `{"runner":"other","id":2}`), http.StatusForbidden},
+		{"other stage", request("RunnerService.FinishStage", script, `{"runner":"runner","stageID":2}`), http.StatusForbidden},
+		{"not a callback", request("RunnerService.Delete", script, `{"runner":"runner","id":1}`), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, tt.req)
+		if rec.Code != tt.status {
+			t.Errorf("%s: got status %d - want %d", tt.name, rec.Code, tt.status)
+		}
+	}
+
+	// revoked tokens are not valid
+	is.NoErr(auth.Revoke(db, runner.ID))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, request("RunnerService.Heartbeat", script, `{"runner":"runner","id":1}`))
+	is.Equal(rec.Code, http.StatusUnauthorized)
+}
+
+func TestRequireAdmin(t *testing.T) {
+	is := is.New(t)
+
+	db := dbtest.Open(t)
+	is.NoErr(db.AutoMigrate(&api.Token{}).Error)
+	cipher := newCipher(is)
+
+	// the initial token is an admin-token
+	admin, err := auth.Initial(db, cipher)
+	is.NoErr(err)
+
+	token, err := auth.New("ci", 0)
+	is.NoErr(err)
+	ci, err := auth.Create(db, cipher, token)
+	is.NoErr(err)
+
+	handler := auth.Handler(db, cipher, zap.NewNop(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := auth.RequireAdmin(r.Context()); err != nil {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, request("ServerService.VerifyPassword", admin, `{}`))
+	is.Equal(rec.Code, http.StatusOK)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, request("ServerService.VerifyPassword", ci, `{}`))
+	is.Equal(rec.Code, http.StatusForbidden)
+
+	// the requests without a verified token are not admin-requests
+	is.True(auth.RequireAdmin(context.Background()) != nil)
+}
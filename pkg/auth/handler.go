@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+)
+
+// maxBodySize is the max size of a request-body
+const maxBodySize = 32 << 20
+
+// callbacks are the methods the runner-scripts
+// are allowed to call with their tokens
+var callbacks = map[string]bool{
+	"RunnerService.Start":         true,
+	"RunnerService.Failed":        true,
+	"RunnerService.Finish":        true,
+	"RunnerService.StartStage":    true,
+	"RunnerService.FailedStage":   true,
+	"RunnerService.FinishStage":   true,
+	"RunnerService.ProgressStage": true,
+	"RunnerService.LogItem":       true,
+	"RunnerService.LogItems":      true,
+	"RunnerService.LogDebug":      true,
+	"RunnerService.LogInfo":       true,
+	"RunnerService.LogError":      true,
+	"RunnerService.Heartbeat":     true,
+}
+
+// stageCallbacks are the callbacks that updates a stage
+var stageCallbacks = map[string]bool{
+	"RunnerService.StartStage":    true,
+	"RunnerService.FailedStage":   true,
+	"RunnerService.FinishStage":   true,
+	"RunnerService.ProgressStage": true,
+}
+
+// Handler verifies the api-token and the signature of the requests
+// before they are handled by next, the method, request-uri, timestamp,
+// nonce and body are signed (the GET-requests for the event-stream have
+// no body) and the requests with stale timestamps or a nonce that has
+// been used before are rejected
+func Handler(db *gorm.DB, c *secrets.Cipher, logger *zap.Logger, next http.Handler) http.Handler {
+	seen := newNonces()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		method := path.Base(r.URL.Path)
+		logger := logger.With(zap.String("method", method), zap.String("remote", r.RemoteAddr))
+
+		key := r.Header.Get(HeaderKey)
+		if key == "" {
+			logger.Warn("Request without api-token")
+			writeError(w, http.StatusUnauthorized, "missing api-token")
+			return
+		}
+		logger = logger.With(zap.String("key", key))
+
+		var token api.Token
+		if db.Where(&api.Token{Key: key}).First(&token).RecordNotFound() {
+			logger.Warn("Request with unknown api-token")
+			writeError(w, http.StatusUnauthorized, "invalid api-token")
+			return
+		}
+
+		if Expired(token, time.Now()) {
+			logger.Warn("Request with expired api-token")
+			writeError(w, http.StatusUnauthorized, "api-token has expired")
+			return
+		}
+
+		timestamp := r.Header.Get(HeaderTimestamp)
+		if !Fresh(timestamp, time.Now()) {
+			logger.Warn("Request with stale timestamp", zap.String("timestamp", timestamp))
+			writeError(w, http.StatusUnauthorized, "missing or stale timestamp - check the clock for the client")
+			return
+		}
+
+		nonce := r.Header.Get(HeaderNonce)
+		if nonce == "" || len(nonce) > MaxNonce {
+			logger.Warn("Request without a valid nonce")
+			writeError(w, http.StatusUnauthorized, "missing or invalid nonce")
+			return
+		}
+
+		var body []byte
+		if r.Method == http.MethodPost {
+			var err error
+			body, err = ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+			if err != nil {
+				logger.Error("Cannot read request-body", zap.String("exception", err.Error()))
+				writeError(w, http.StatusBadRequest, "cannot read request-body")
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		secret, err := c.Decrypt(token.Secret)
+		if err != nil {
+			logger.Error("Cannot decrypt secret for api-token", zap.String("exception", err.Error()))
+			writeError(w, http.StatusInternalServerError, "cannot verify api-token")
+			return
+		}
+
+		if !Verify([]byte(secret), r.Method, r.URL.RequestURI(), timestamp, nonce, body, r.Header.Get(HeaderSignature)) {
+			logger.Warn("Request with invalid signature")
+			writeError(w, http.StatusUnauthorized, "invalid signature")
+			return
+		}
+
+		// the nonce is only recorded for the verified requests
+		if !seen.use(token.Key, nonce, time.Now()) {
+			logger.Warn("Replayed request", zap.String("nonce", nonce))
+			writeError(w, http.StatusUnauthorized, "the nonce has already been used")
+			return
+		}
+
+		if token.RunnerID != 0 {
+			if err := authorizeRunner(db, token, method, body); err != nil {
+				logger.Warn("Request denied for runner-token", zap.String("runner", token.Runner), zap.String("exception", err.Error()))
+				writeError(w, http.StatusForbidden, err.Error())
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), token)))
+	})
+}
+
+// contextKey is the type for the values in the request-context
+type contextKey string
+
+// tokenKey is the key for the verified api-token in the request-context
+const tokenKey contextKey = "token"
+
+// NewContext returns the context with the verified api-token
+func NewContext(ctx context.Context, token api.Token) context.Context {
+	return context.WithValue(ctx, tokenKey, token)
+}
+
+// FromContext returns the verified api-token for the request
+func FromContext(ctx context.Context) (api.Token, bool) {
+	token, ok := ctx.Value(tokenKey).(api.Token)
+	return token, ok
+}
+
+// RequireAdmin returns an error unless the
+// request is signed with an admin-token
+func RequireAdmin(ctx context.Context) error {
+	token, ok := FromContext(ctx)
+	if !ok || !token.Admin {
+		return errors.New("an admin api-token is required")
+	}
+	return nil
+}
+
+// RequireClientCert requires a verified client-certificate (mutual TLS)
+// for the requests, except for the callbacks of the runner-scripts
+func RequireClientCert(logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := path.Base(r.URL.Path)
+		if !Callback(method) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+				logger.Warn("Request without client-certificate", zap.String("method", method), zap.String("remote", r.RemoteAddr))
+				writeError(w, http.StatusUnauthorized, "client-certificate is required")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Callback returns true if the method
+// is a callback for the runner-scripts
+func Callback(method string) bool {
+	return callbacks[method]
+}
+
+// authorizeRunner checks that the token for a runner-script
+// is used for a callback to the runner of the token
+func authorizeRunner(db *gorm.DB, token api.Token, method string, body []byte) error {
+	if !callbacks[method] {
+		return errors.New("api-token is only valid for the callbacks of the runner")
+	}
+
+	var request struct {
+		Runner  string `json:"runner"`
+		ID      uint   `json:"id"`
+		StageID uint   `json:"stageID"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return errors.New("cannot decode request-body")
+	}
+
+	if request.Runner != token.Runner {
+		return errors.New("api-token is not valid for the runner")
+	}
+	if request.ID != 0 && request.ID != token.RunnerID {
+		return errors.New("api-token is not valid for the runner")
+	}
+
+	if stageCallbacks[method] {
+		var stage api.Stage
+		if db.Where("id = ? and runner_id = ?", request.StageID, token.RunnerID).First(&stage).RecordNotFound() {
+			return errors.New("api-token is not valid for the stage")
+		}
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{Error: message})
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// nonces records the nonces of the verified requests until
+// their timestamps are stale, so the requests can't be replayed
+type nonces struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+	prune   time.Time
+}
+
+func newNonces() *nonces {
+	return &nonces{expires: make(map[string]time.Time)}
+}
+
+// use records the nonce for the key, it returns false if the nonce
+// has already been used - a request that was accepted now has a
+// timestamp that is fresh for at most 2*MaxSkew
+func (n *nonces) use(key, nonce string, now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if now.After(n.prune) {
+		for id, expires := range n.expires {
+			if now.After(expires) {
+				delete(n.expires, id)
+			}
+		}
+		n.prune = now.Add(MaxSkew)
+	}
+
+	id := key + "." + nonce
+	if expires, ok := n.expires[id]; ok && !now.After(expires) {
+		return false
+	}
+	n.expires[id] = now.Add(2 * MaxSkew)
+	return true
+}
@@ -14,11 +14,41 @@ import (
 	time "time"
 )
 
+// AuditService handles the audit-log for the administrative actions on the
+// runners, servers and nms
+type AuditService interface {
+
+	// List returns the entries from the audit-log
+	List(context.Context, AuditListRequest) (*AuditListResponse, error)
+}
+
 // NmsService handles the Nuix Management Servers
 type NmsService interface {
 	Apply(context.Context, NmsApplyRequests) (*NmsApplyResponse, error)
+	// Delete deletes the requested nms-server, unless it is used by any waiting or
+	// active runners
+	Delete(context.Context, NmsDeleteRequest) (*NmsDeleteResponse, error)
+	// Get returns the requested nms-server
+	Get(context.Context, NmsGetRequest) (*NmsGetResponse, error)
 	List(context.Context, NmsListRequest) (*NmsListResponse, error)
 	ListLicences(context.Context, NmsListLicencesRequest) (*NmsListLicencesResponse, error)
+	// VerifyPassword verifies the stored password for the nms-server, the password is
+	// never returned by the service (it requires an admin-token)
+	VerifyPassword(context.Context, NmsVerifyPasswordRequest) (*NmsVerifyPasswordResponse, error)
+}
+
+// NotificationService handles the notification-rules for the runners and the log
+// of the deliveries
+type NotificationService interface {
+
+	// Apply creates or updates the notification-rules
+	Apply(context.Context, NotificationApplyRequests) (*NotificationApplyResponse, error)
+	// Delete deletes the requested notification-rule
+	Delete(context.Context, NotificationDeleteRequest) (*NotificationDeleteResponse, error)
+	// Deliveries returns the log of the deliveries
+	Deliveries(context.Context, NotificationDeliveriesRequest) (*NotificationDeliveriesResponse, error)
+	// List returns the notification-rules
+	List(context.Context, NotificationListRequest) (*NotificationListResponse, error)
 }
 
 // RunnerService handles all the runners
@@ -26,7 +56,7 @@ type RunnerService interface {
 
 	// Apply applies the configuration to the backend
 	Apply(context.Context, RunnerApplyRequest) (*RunnerApplyResponse, error)
-	// Delete deletes the requested Runner
+	// Delete moves the requested Runner to the trash
 	Delete(context.Context, RunnerDeleteRequest) (*RunnerDeleteResponse, error)
 	// Failed sets a runner to failed
 	Failed(context.Context, RunnerFailedRequest) (*RunnerFailedResponse, error)
@@ -42,6 +72,8 @@ type RunnerService interface {
 	Heartbeat(context.Context, RunnerStartRequest) (*RunnerStartResponse, error)
 	// List returns the runners from the backend
 	List(context.Context, RunnerListRequest) (*RunnerListResponse, error)
+	// ListTrash returns the runners in the trash
+	ListTrash(context.Context, RunnerListTrashRequest) (*RunnerListTrashResponse, error)
 	// LogDebug logs a debug-message
 	LogDebug(context.Context, LogRequest) (*LogResponse, error)
 	// LogError logs an error-message
@@ -50,16 +82,92 @@ type RunnerService interface {
 	LogInfo(context.Context, LogRequest) (*LogResponse, error)
 	// LogItem logs an item
 	LogItem(context.Context, LogItemRequest) (*LogResponse, error)
+	// LogItems logs a batch of items
+	LogItems(context.Context, LogItemsRequest) (*LogResponse, error)
+	// Manifest returns the evidence-manifests for the requested Runner
+	Manifest(context.Context, RunnerManifestRequest) (*RunnerManifestResponse, error)
+	// ProgressStage sets the progress for a stage
+	ProgressStage(context.Context, StageProgressRequest) (*StageResponse, error)
+	// Purge permanently deletes the runners in the trash
+	Purge(context.Context, RunnerPurgeRequest) (*RunnerPurgeResponse, error)
+	// Restore restores the requested Runner from the trash
+	Restore(context.Context, RunnerRestoreRequest) (*RunnerRestoreResponse, error)
+	// Script returns the generated script for the requested Runner
+	Script(context.Context, RunnerScriptRequest) (*RunnerScriptResponse, error)
 	// Start sets a runner to started
 	Start(context.Context, RunnerStartRequest) (*RunnerStartResponse, error)
 	// StartStage sets a stage to Active
 	StartStage(context.Context, StageRequest) (*StageResponse, error)
 }
 
+// SecretService handles the named secrets, they are referenced from the
+// credentials in the configs with secret:<name>
+type SecretService interface {
+
+	// Delete deletes the requested secret, unless it is referenced by any servers or
+	// nms-servers
+	Delete(context.Context, SecretDeleteRequest) (*SecretDeleteResponse, error)
+	// List returns the secrets without their values
+	List(context.Context, SecretListRequest) (*SecretListResponse, error)
+	// Set creates or updates a secret
+	Set(context.Context, SecretSetRequest) (*SecretSetResponse, error)
+}
+
 // ServerService handles all the servers
 type ServerService interface {
 	Apply(context.Context, ServerApplyRequest) (*ServerApplyResponse, error)
+	// Delete deletes the requested server, unless it is used by any waiting or active
+	// runners
+	Delete(context.Context, ServerDeleteRequest) (*ServerDeleteResponse, error)
+	// Get returns the requested server
+	Get(context.Context, ServerGetRequest) (*ServerGetResponse, error)
 	List(context.Context, ServerListRequest) (*ServerListResponse, error)
+	// VerifyPassword verifies the stored password for the server, the password is
+	// never returned by the service (it requires an admin-token)
+	VerifyPassword(context.Context, ServerVerifyPasswordRequest) (*ServerVerifyPasswordResponse, error)
+}
+
+// TokenService handles the api-tokens
+type TokenService interface {
+
+	// Create creates a new api-token
+	Create(context.Context, TokenCreateRequest) (*TokenCreateResponse, error)
+	// Delete revokes the requested api-token
+	Delete(context.Context, TokenDeleteRequest) (*TokenDeleteResponse, error)
+	// List returns the api-tokens
+	List(context.Context, TokenListRequest) (*TokenListResponse, error)
+}
+
+type auditServiceServer struct {
+	server       *otohttp.Server
+	auditService AuditService
+}
+
+// Register adds the AuditService to the otohttp.Server.
+func RegisterAuditService(server *otohttp.Server, auditService AuditService) {
+	handler := &auditServiceServer{
+		server:       server,
+		auditService: auditService,
+	}
+	server.Register("AuditService", "List", handler.handleList)
+}
+
+func (s *auditServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
+	var request AuditListRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.auditService.List(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
 }
 
 type nmsServiceServer struct {
@@ -74,8 +182,11 @@ func RegisterNmsService(server *otohttp.Server, nmsService NmsService) {
 		nmsService: nmsService,
 	}
 	server.Register("NmsService", "Apply", handler.handleApply)
+	server.Register("NmsService", "Delete", handler.handleDelete)
+	server.Register("NmsService", "Get", handler.handleGet)
 	server.Register("NmsService", "List", handler.handleList)
 	server.Register("NmsService", "ListLicences", handler.handleListLicences)
+	server.Register("NmsService", "VerifyPassword", handler.handleVerifyPassword)
 }
 
 func (s *nmsServiceServer) handleApply(w http.ResponseWriter, r *http.Request) {
@@ -96,6 +207,42 @@ func (s *nmsServiceServer) handleApply(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *nmsServiceServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	var request NmsDeleteRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.nmsService.Delete(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *nmsServiceServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	var request NmsGetRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.nmsService.Get(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
 func (s *nmsServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
 	var request NmsListRequest
 	if err := otohttp.Decode(r, &request); err != nil {
@@ -132,6 +279,113 @@ func (s *nmsServiceServer) handleListLicences(w http.ResponseWriter, r *http.Req
 	}
 }
 
+func (s *nmsServiceServer) handleVerifyPassword(w http.ResponseWriter, r *http.Request) {
+	var request NmsVerifyPasswordRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.nmsService.VerifyPassword(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+type notificationServiceServer struct {
+	server              *otohttp.Server
+	notificationService NotificationService
+}
+
+// Register adds the NotificationService to the otohttp.Server.
+func RegisterNotificationService(server *otohttp.Server, notificationService NotificationService) {
+	handler := &notificationServiceServer{
+		server:              server,
+		notificationService: notificationService,
+	}
+	server.Register("NotificationService", "Apply", handler.handleApply)
+	server.Register("NotificationService", "Delete", handler.handleDelete)
+	server.Register("NotificationService", "Deliveries", handler.handleDeliveries)
+	server.Register("NotificationService", "List", handler.handleList)
+}
+
+func (s *notificationServiceServer) handleApply(w http.ResponseWriter, r *http.Request) {
+	var request NotificationApplyRequests
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.notificationService.Apply(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *notificationServiceServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	var request NotificationDeleteRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.notificationService.Delete(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *notificationServiceServer) handleDeliveries(w http.ResponseWriter, r *http.Request) {
+	var request NotificationDeliveriesRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.notificationService.Deliveries(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *notificationServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
+	var request NotificationListRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.notificationService.List(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
 type runnerServiceServer struct {
 	server        *otohttp.Server
 	runnerService RunnerService
@@ -152,10 +406,17 @@ func RegisterRunnerService(server *otohttp.Server, runnerService RunnerService)
 	server.Register("RunnerService", "Get", handler.handleGet)
 	server.Register("RunnerService", "Heartbeat", handler.handleHeartbeat)
 	server.Register("RunnerService", "List", handler.handleList)
+	server.Register("RunnerService", "ListTrash", handler.handleListTrash)
 	server.Register("RunnerService", "LogDebug", handler.handleLogDebug)
 	server.Register("RunnerService", "LogError", handler.handleLogError)
 	server.Register("RunnerService", "LogInfo", handler.handleLogInfo)
 	server.Register("RunnerService", "LogItem", handler.handleLogItem)
+	server.Register("RunnerService", "LogItems", handler.handleLogItems)
+	server.Register("RunnerService", "Manifest", handler.handleManifest)
+	server.Register("RunnerService", "ProgressStage", handler.handleProgressStage)
+	server.Register("RunnerService", "Purge", handler.handlePurge)
+	server.Register("RunnerService", "Restore", handler.handleRestore)
+	server.Register("RunnerService", "Script", handler.handleScript)
 	server.Register("RunnerService", "Start", handler.handleStart)
 	server.Register("RunnerService", "StartStage", handler.handleStartStage)
 }
@@ -322,6 +583,24 @@ func (s *runnerServiceServer) handleList(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+func (s *runnerServiceServer) handleListTrash(w http.ResponseWriter, r *http.Request) {
+	var request RunnerListTrashRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.ListTrash(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
 func (s *runnerServiceServer) handleLogDebug(w http.ResponseWriter, r *http.Request) {
 	var request LogRequest
 	if err := otohttp.Decode(r, &request); err != nil {
@@ -364,7 +643,311 @@ func (s *runnerServiceServer) handleLogInfo(w http.ResponseWriter, r *http.Reque
 		s.server.OnErr(w, r, err)
 		return
 	}
-	response, err := s.runnerService.LogInfo(r.Context(), request)
+	response, err := s.runnerService.LogInfo(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleLogItem(w http.ResponseWriter, r *http.Request) {
+	var request LogItemRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.LogItem(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleLogItems(w http.ResponseWriter, r *http.Request) {
+	var request LogItemsRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.LogItems(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleManifest(w http.ResponseWriter, r *http.Request) {
+	var request RunnerManifestRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.Manifest(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleProgressStage(w http.ResponseWriter, r *http.Request) {
+	var request StageProgressRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.ProgressStage(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handlePurge(w http.ResponseWriter, r *http.Request) {
+	var request RunnerPurgeRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.Purge(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleRestore(w http.ResponseWriter, r *http.Request) {
+	var request RunnerRestoreRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.Restore(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleScript(w http.ResponseWriter, r *http.Request) {
+	var request RunnerScriptRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.Script(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleStart(w http.ResponseWriter, r *http.Request) {
+	var request RunnerStartRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.Start(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *runnerServiceServer) handleStartStage(w http.ResponseWriter, r *http.Request) {
+	var request StageRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.runnerService.StartStage(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+type secretServiceServer struct {
+	server        *otohttp.Server
+	secretService SecretService
+}
+
+// Register adds the SecretService to the otohttp.Server.
+func RegisterSecretService(server *otohttp.Server, secretService SecretService) {
+	handler := &secretServiceServer{
+		server:        server,
+		secretService: secretService,
+	}
+	server.Register("SecretService", "Delete", handler.handleDelete)
+	server.Register("SecretService", "List", handler.handleList)
+	server.Register("SecretService", "Set", handler.handleSet)
+}
+
+func (s *secretServiceServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	var request SecretDeleteRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.secretService.Delete(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *secretServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
+	var request SecretListRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.secretService.List(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *secretServiceServer) handleSet(w http.ResponseWriter, r *http.Request) {
+	var request SecretSetRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.secretService.Set(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+type serverServiceServer struct {
+	server        *otohttp.Server
+	serverService ServerService
+}
+
+// Register adds the ServerService to the otohttp.Server.
+func RegisterServerService(server *otohttp.Server, serverService ServerService) {
+	handler := &serverServiceServer{
+		server:        server,
+		serverService: serverService,
+	}
+	server.Register("ServerService", "Apply", handler.handleApply)
+	server.Register("ServerService", "Delete", handler.handleDelete)
+	server.Register("ServerService", "Get", handler.handleGet)
+	server.Register("ServerService", "List", handler.handleList)
+	server.Register("ServerService", "VerifyPassword", handler.handleVerifyPassword)
+}
+
+func (s *serverServiceServer) handleApply(w http.ResponseWriter, r *http.Request) {
+	var request ServerApplyRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.serverService.Apply(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *serverServiceServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	var request ServerDeleteRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.serverService.Delete(r.Context(), request)
+	if err != nil {
+		log.Println("TODO: oto service error:", err)
+		s.server.OnErr(w, r, err)
+		return
+	}
+	if err := otohttp.Encode(w, r, http.StatusOK, response); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+}
+
+func (s *serverServiceServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	var request ServerGetRequest
+	if err := otohttp.Decode(r, &request); err != nil {
+		s.server.OnErr(w, r, err)
+		return
+	}
+	response, err := s.serverService.Get(r.Context(), request)
 	if err != nil {
 		log.Println("TODO: oto service error:", err)
 		s.server.OnErr(w, r, err)
@@ -376,13 +959,13 @@ func (s *runnerServiceServer) handleLogInfo(w http.ResponseWriter, r *http.Reque
 	}
 }
 
-func (s *runnerServiceServer) handleLogItem(w http.ResponseWriter, r *http.Request) {
-	var request LogItemRequest
+func (s *serverServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
+	var request ServerListRequest
 	if err := otohttp.Decode(r, &request); err != nil {
 		s.server.OnErr(w, r, err)
 		return
 	}
-	response, err := s.runnerService.LogItem(r.Context(), request)
+	response, err := s.serverService.List(r.Context(), request)
 	if err != nil {
 		log.Println("TODO: oto service error:", err)
 		s.server.OnErr(w, r, err)
@@ -394,13 +977,13 @@ func (s *runnerServiceServer) handleLogItem(w http.ResponseWriter, r *http.Reque
 	}
 }
 
-func (s *runnerServiceServer) handleStart(w http.ResponseWriter, r *http.Request) {
-	var request RunnerStartRequest
+func (s *serverServiceServer) handleVerifyPassword(w http.ResponseWriter, r *http.Request) {
+	var request ServerVerifyPasswordRequest
 	if err := otohttp.Decode(r, &request); err != nil {
 		s.server.OnErr(w, r, err)
 		return
 	}
-	response, err := s.runnerService.Start(r.Context(), request)
+	response, err := s.serverService.VerifyPassword(r.Context(), request)
 	if err != nil {
 		log.Println("TODO: oto service error:", err)
 		s.server.OnErr(w, r, err)
@@ -412,13 +995,29 @@ func (s *runnerServiceServer) handleStart(w http.ResponseWriter, r *http.Request
 	}
 }
 
-func (s *runnerServiceServer) handleStartStage(w http.ResponseWriter, r *http.Request) {
-	var request StageRequest
+type tokenServiceServer struct {
+	server       *otohttp.Server
+	tokenService TokenService
+}
+
+// Register adds the TokenService to the otohttp.Server.
+func RegisterTokenService(server *otohttp.Server, tokenService TokenService) {
+	handler := &tokenServiceServer{
+		server:       server,
+		tokenService: tokenService,
+	}
+	server.Register("TokenService", "Create", handler.handleCreate)
+	server.Register("TokenService", "Delete", handler.handleDelete)
+	server.Register("TokenService", "List", handler.handleList)
+}
+
+func (s *tokenServiceServer) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var request TokenCreateRequest
 	if err := otohttp.Decode(r, &request); err != nil {
 		s.server.OnErr(w, r, err)
 		return
 	}
-	response, err := s.runnerService.StartStage(r.Context(), request)
+	response, err := s.tokenService.Create(r.Context(), request)
 	if err != nil {
 		log.Println("TODO: oto service error:", err)
 		s.server.OnErr(w, r, err)
@@ -430,28 +1029,13 @@ func (s *runnerServiceServer) handleStartStage(w http.ResponseWriter, r *http.Re
 	}
 }
 
-type serverServiceServer struct {
-	server        *otohttp.Server
-	serverService ServerService
-}
-
-// Register adds the ServerService to the otohttp.Server.
-func RegisterServerService(server *otohttp.Server, serverService ServerService) {
-	handler := &serverServiceServer{
-		server:        server,
-		serverService: serverService,
-	}
-	server.Register("ServerService", "Apply", handler.handleApply)
-	server.Register("ServerService", "List", handler.handleList)
-}
-
-func (s *serverServiceServer) handleApply(w http.ResponseWriter, r *http.Request) {
-	var request ServerApplyRequest
+func (s *tokenServiceServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	var request TokenDeleteRequest
 	if err := otohttp.Decode(r, &request); err != nil {
 		s.server.OnErr(w, r, err)
 		return
 	}
-	response, err := s.serverService.Apply(r.Context(), request)
+	response, err := s.tokenService.Delete(r.Context(), request)
 	if err != nil {
 		log.Println("TODO: oto service error:", err)
 		s.server.OnErr(w, r, err)
@@ -463,13 +1047,13 @@ func (s *serverServiceServer) handleApply(w http.ResponseWriter, r *http.Request
 	}
 }
 
-func (s *serverServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
-	var request ServerListRequest
+func (s *tokenServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
+	var request TokenListRequest
 	if err := otohttp.Decode(r, &request); err != nil {
 		s.server.OnErr(w, r, err)
 		return
 	}
-	response, err := s.serverService.List(r.Context(), request)
+	response, err := s.tokenService.List(r.Context(), request)
 	if err != nil {
 		log.Println("TODO: oto service error:", err)
 		s.server.OnErr(w, r, err)
@@ -481,6 +1065,50 @@ func (s *serverServiceServer) handleList(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// AuditEntry is an administrative action recorded in the audit-log, the time for
+// the action is the time the entry was created
+type AuditEntry struct {
+	datastore.Base
+	// Actor is the name of the api-token for the caller
+	Actor string `json:"actor" yaml:"actor"`
+	// Key for the api-token of the caller
+	Key string `json:"key" yaml:"key"`
+	// Remote address of the caller
+	Remote string `json:"remote" yaml:"remote"`
+	// Method that was called (Service.Method)
+	Method string `json:"method" yaml:"method"`
+	// Request is the request-body with the secrets redacted
+	Request string `json:"request" yaml:"request"`
+	// Outcome of the request (ok or failed)
+	Outcome string `json:"outcome" yaml:"outcome"`
+	// Reason the request failed
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// AuditListRequest is the input-object for listing the audit-log
+type AuditListRequest struct {
+	// Since is the time to list the entries from, either a duration back in time (like
+	// 24h or 7d) or a date
+	Since string `json:"since" yaml:"since"`
+	// Actor to list the entries for
+	Actor string `json:"actor" yaml:"actor"`
+	// Limit is the max amount of entries to return (defaults to 100)
+	Limit int64 `json:"limit" yaml:"limit"`
+	// PageToken is the token for the page to return, from NextPageToken of the
+	// previous page
+	PageToken string `json:"pageToken" yaml:"pageToken"`
+}
+
+// AuditListResponse is the output-object for listing the audit-log
+type AuditListResponse struct {
+	// Entries with the latest first
+	Entries []AuditEntry `json:"entries" yaml:"entries"`
+	// NextPageToken is the token for the next page, empty if it is the last page
+	NextPageToken string `json:"nextPageToken" yaml:"nextPageToken"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 type Base struct {
 	ID    uint   `json:"id" yaml:"id"`
 	CTime int64  `json:"cTime" yaml:"cTime"`
@@ -518,6 +1146,28 @@ type CaseSettings struct {
 	ReviewCompound   *Case `json:"reviewCompound" yaml:"reviewCompound"`
 }
 
+// Delivery is a delivery of a notification
+type Delivery struct {
+	datastore.Base
+	// NotificationID and Notification (name) for the rule that was delivered
+	NotificationID uint   `json:"notificationID" yaml:"notificationID"`
+	Notification   string `json:"notification" yaml:"notification"`
+	// Runner and Trigger for the delivery
+	Runner  string `json:"runner" yaml:"runner"`
+	Trigger string `json:"trigger" yaml:"trigger"`
+	// Status of the delivery (pending, delivered or failed)
+	Status string `json:"status" yaml:"status"`
+	// Attempts to deliver the notification
+	Attempts int64 `json:"attempts" yaml:"attempts"`
+	// LastError is the error from the last failed attempt
+	LastError string `json:"lastError" yaml:"lastError"`
+	// DeliveredAt is when the notification was delivered (unix-time)
+	DeliveredAt int64 `json:"deliveredAt" yaml:"deliveredAt"`
+	// Event that is notified (json), the pending deliveries are resumed with it on
+	// startup
+	Event string `json:"event" yaml:"event"`
+}
+
 // Evidence holds information about a specific evidence
 type Evidence struct {
 	datastore.Base
@@ -527,6 +1177,17 @@ type Evidence struct {
 	Name string `json:"name" yaml:"name"`
 	// Directory of where the evidence is located
 	Directory string `json:"directory" yaml:"directory"`
+	// Paths holds additional paths for the evidence
+	Paths []*EvidencePath `json:"paths" yaml:"paths"`
+	// PathList is a text-file listing paths for the evidence (one path per line)
+	PathList string `json:"pathList" yaml:"pathList"`
+	// LoadFile is a Nuix load-file to ingest into the evidence-container
+	LoadFile string `json:"loadFile" yaml:"loadFile"`
+	// Filters holds glob-patterns to include or exclude files from the paths of the
+	// evidence
+	Filters []*EvidenceFilter `json:"filters" yaml:"filters"`
+	// Metadata holds custom metadata for the evidence
+	Metadata []*EvidenceMetadata `json:"metadata" yaml:"metadata"`
 	// Description of the evidence
 	Description string `json:"description" yaml:"description"`
 	// Encoding for the evidence (used when processing)
@@ -539,6 +1200,37 @@ type Evidence struct {
 	Locale string `json:"locale" yaml:"locale"`
 }
 
+// EvidenceFilter holds a glob-pattern for the files to add from an evidence
+type EvidenceFilter struct {
+	datastore.Base
+	// EvidenceID foreign-key for evidence-table
+	EvidenceID uint `json:"evidenceID" yaml:"evidenceID"`
+	// Pattern to match the files with, relative to the paths of the evidence
+	Pattern string `json:"pattern" yaml:"pattern"`
+	// Exclude - if the matched files should be excluded instead of included
+	Exclude bool `json:"exclude" yaml:"exclude"`
+}
+
+// EvidenceMetadata holds a custom metadata-field for an evidence
+type EvidenceMetadata struct {
+	datastore.Base
+	// EvidenceID foreign-key for evidence-table
+	EvidenceID uint `json:"evidenceID" yaml:"evidenceID"`
+	// Key for the metadata-field
+	Key string `json:"key" yaml:"key"`
+	// Value for the metadata-field
+	Value string `json:"value" yaml:"value"`
+}
+
+// EvidencePath holds a path for an evidence
+type EvidencePath struct {
+	datastore.Base
+	// EvidenceID foreign-key for evidence-table
+	EvidenceID uint `json:"evidenceID" yaml:"evidenceID"`
+	// Path for where the evidence is located at
+	Path string `json:"path" yaml:"path"`
+}
+
 // Exclude excludes items in a Nuix-case based on a search
 type Exclude struct {
 	datastore.Base
@@ -589,6 +1281,7 @@ type Licences struct {
 
 type LogItemRequest struct {
 	Runner       string `json:"runner" yaml:"runner"`
+	RunID        string `json:"runID" yaml:"runID"`
 	Stage        string `json:"stage" yaml:"stage"`
 	StageID      int    `json:"stageID" yaml:"stageID"`
 	Message      string `json:"message" yaml:"message"`
@@ -598,8 +1291,19 @@ type LogItemRequest struct {
 	ProcessStage string `json:"processStage" yaml:"processStage"`
 }
 
+// LogItemsRequest is the input-object for logging a batch of items
+type LogItemsRequest struct {
+	// Runner the items are logged for
+	Runner string `json:"runner" yaml:"runner"`
+	// RunID for the run of the runner-script
+	RunID string `json:"runID" yaml:"runID"`
+	// Items to log
+	Items []LogItemRequest `json:"items" yaml:"items"`
+}
+
 type LogRequest struct {
 	Runner    string `json:"runner" yaml:"runner"`
+	RunID     string `json:"runID" yaml:"runID"`
 	Stage     string `json:"stage" yaml:"stage"`
 	StageID   int    `json:"stageID" yaml:"stageID"`
 	Message   string `json:"message" yaml:"message"`
@@ -611,6 +1315,66 @@ type LogResponse struct {
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
+// Manifest is a chain-of-custody manifest for the evidence of a runner
+type Manifest struct {
+	datastore.Base
+	// RunnerID foreign-key for runner-table
+	RunnerID uint `json:"runnerID" yaml:"runnerID"`
+	// Phase for when the manifest was created (apply, run or finish)
+	Phase string `json:"phase" yaml:"phase"`
+	// FileCount is the amount of files in the manifest
+	FileCount int64 `json:"fileCount" yaml:"fileCount"`
+	// TotalSize is the total size in bytes for the files in the manifest
+	TotalSize int64 `json:"totalSize" yaml:"totalSize"`
+	// Verified - if the manifest matches the previous manifest for the runner
+	Verified bool `json:"verified" yaml:"verified"`
+	// Evidence holds the evidence-paths in the manifest
+	Evidence []*ManifestEvidence `json:"evidence" yaml:"evidence"`
+	// Changes holds the changes from the previous manifest for the runner
+	Changes []*ManifestChange `json:"changes" yaml:"changes"`
+}
+
+// ManifestChange holds a change for a file compared to the previous manifest
+type ManifestChange struct {
+	datastore.Base
+	// ManifestID foreign-key for manifest-table
+	ManifestID uint `json:"manifestID" yaml:"manifestID"`
+	// Path for the changed file
+	Path string `json:"path" yaml:"path"`
+	// Change for the file (added, removed or modified)
+	Change string `json:"change" yaml:"change"`
+}
+
+// ManifestEvidence holds the files for an evidence-path in a manifest
+type ManifestEvidence struct {
+	datastore.Base
+	// ManifestID foreign-key for manifest-table
+	ManifestID uint `json:"manifestID" yaml:"manifestID"`
+	// Name of the evidence
+	Name string `json:"name" yaml:"name"`
+	// Path for the evidence
+	Path string `json:"path" yaml:"path"`
+	// FileCount is the amount of files for the evidence-path
+	FileCount int64 `json:"fileCount" yaml:"fileCount"`
+	// TotalSize is the total size in bytes for the files in the evidence-path
+	TotalSize int64 `json:"totalSize" yaml:"totalSize"`
+	// Files in the evidence-path
+	Files []*ManifestFile `json:"files" yaml:"files"`
+}
+
+// ManifestFile holds the information for a file in an evidence-path
+type ManifestFile struct {
+	datastore.Base
+	// ManifestEvidenceID foreign-key for manifestevidence-table
+	ManifestEvidenceID uint `json:"manifestEvidenceID" yaml:"manifestEvidenceID"`
+	// Path for the file
+	Path string `json:"path" yaml:"path"`
+	// Size of the file in bytes
+	Size int64 `json:"size" yaml:"size"`
+	// Hash is the SHA256-hash for the file
+	Hash string `json:"hash" yaml:"hash"`
+}
+
 // Nms is the main struct for the Nuix Management Servers
 type Nms struct {
 	datastore.Base
@@ -620,7 +1384,8 @@ type Nms struct {
 	Port int64 `json:"port" yaml:"port"`
 	// Username for the nms-server
 	Username string `json:"username" yaml:"username"`
-	// Password for the nms-server
+	// Password for the nms-server, it is write-only and masked in the responses - or a
+	// reference (env:<name>, file:<path> or secret:<name>)
 	Password string `json:"password" yaml:"password"`
 	// amount of workers licensed to the server
 	Workers int64 `json:"workers" yaml:"workers"`
@@ -638,7 +1403,8 @@ type NmsApplyRequest struct {
 	Port int64 `json:"port" yaml:"port"`
 	// Username for the nms-server
 	Username string `json:"username" yaml:"username"`
-	// Password for the nms-server
+	// Password for the nms-server - or a reference (env:<name>, file:<path> or
+	// secret:<name>)
 	Password string `json:"password" yaml:"password"`
 	// amount of workers licensed to the server
 	Workers int64 `json:"workers" yaml:"workers"`
@@ -657,11 +1423,38 @@ type NmsApplyResponse struct {
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
+// NmsDeleteRequest is the input-object for Delete in the NMS-service
+type NmsDeleteRequest struct {
+	// Address of the nms-server
+	Address string `json:"address" yaml:"address"`
+}
+
+// NmsDeleteResponse is the output-object for Delete in the NMS-service
+type NmsDeleteResponse struct {
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// NmsGetRequest is the input-object for Get in the NMS-service
+type NmsGetRequest struct {
+	// Address of the nms-server
+	Address string `json:"address" yaml:"address"`
+}
+
+// NmsGetResponse is the output-object for Get in the NMS-service
+type NmsGetResponse struct {
+	Nms Nms `json:"nms" yaml:"nms"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 // NmsListLicencesRequest is the input-object for listing licences for a specific
 // NMS
 type NmsListLicencesRequest struct {
 	// ID for the nms-server to list the licences for
 	NmsID uint `json:"nmsID" yaml:"nmsID"`
+	// Address for the nms-server to list the licences for (if NmsID isn't set)
+	Address string `json:"address" yaml:"address"`
 }
 
 // NmsListLicencesResponse is the output-object for listing licences for a specific
@@ -674,11 +1467,152 @@ type NmsListLicencesResponse struct {
 
 // NmsListRequest is the input-object for List in the NMS-service
 type NmsListRequest struct {
+	// Address to filter the nms-servers with, a glob-pattern (for example *.avian.dk)
+	Address string `json:"address" yaml:"address"`
+	// Sort is the field to sort by (id, address or created), prefixed with - for
+	// descending order
+	Sort string `json:"sort" yaml:"sort"`
+	// Limit is the max amount of nms-servers to return (defaults to 100)
+	Limit int64 `json:"limit" yaml:"limit"`
+	// PageToken is the token for the page to return, from NextPageToken of the
+	// previous page
+	PageToken string `json:"pageToken" yaml:"pageToken"`
 }
 
 // NmsListResponse is the output-object for List in the NMS-service
 type NmsListResponse struct {
 	Nms []Nms `json:"nms" yaml:"nms"`
+	// NextPageToken is the token for the next page, empty if it is the last page
+	NextPageToken string `json:"nextPageToken" yaml:"nextPageToken"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// NmsVerifyPasswordRequest is the input-object for VerifyPassword in the
+// NMS-service
+type NmsVerifyPasswordRequest struct {
+	// Address of the nms-server
+	Address string `json:"address" yaml:"address"`
+	// Password to verify
+	Password string `json:"password" yaml:"password"`
+}
+
+// NmsVerifyPasswordResponse is the output-object for VerifyPassword in the
+// NMS-service
+type NmsVerifyPasswordResponse struct {
+	// Match - if the password matches the stored password
+	Match bool `json:"match" yaml:"match"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Notification is a rule for notifying when something happens with a runner
+type Notification struct {
+	datastore.Base
+	// Name of the notification-rule
+	Name string `json:"name" yaml:"name"`
+	// Runner the rule is for, empty if it is for all runners
+	Runner string `json:"runner" yaml:"runner"`
+	// Triggers for the notification separated by comma (finish, failure, timeout and
+	// stage-failure)
+	Triggers string `json:"triggers" yaml:"triggers"`
+	// Type of the notification (webhook or email)
+	Type string `json:"type" yaml:"type"`
+	// Endpoint is the url to post the webhook to
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	// Body is the template for the json-body of the webhook, a default body is posted
+	// if it is empty
+	Body string `json:"body" yaml:"body"`
+	// Recipients are the email-addresses to notify, separated by comma
+	Recipients string `json:"recipients" yaml:"recipients"`
+	// Subject is the template for the subject of the email, a default subject is used
+	// if it is empty
+	Subject string `json:"subject" yaml:"subject"`
+}
+
+// NotificationApplyRequest is the input-object for applying a notification-rule
+type NotificationApplyRequest struct {
+	// Name of the notification-rule
+	Name string `json:"name" yaml:"name"`
+	// Runner the rule is for, empty if it is for all runners
+	Runner string `json:"runner" yaml:"runner"`
+	// Triggers for the notification (finish, failure, timeout and stage-failure)
+	Triggers []string `json:"triggers" yaml:"triggers"`
+	// Type of the notification (webhook or email)
+	Type string `json:"type" yaml:"type"`
+	// Endpoint is the url to post the webhook to
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	// Body is the template for the json-body of the webhook
+	Body string `json:"body" yaml:"body"`
+	// Recipients are the email-addresses to notify
+	Recipients []string `json:"recipients" yaml:"recipients"`
+	// Subject is the template for the subject of the email
+	Subject string `json:"subject" yaml:"subject"`
+}
+
+// NotificationApplyRequests is the input-object for Apply in the
+// notification-service
+type NotificationApplyRequests struct {
+	Notifications []NotificationApplyRequest `json:"notifications" yaml:"notifications"`
+}
+
+// NotificationApplyResponse is the output-object for Apply in the
+// notification-service
+type NotificationApplyResponse struct {
+	Notifications []Notification `json:"notifications" yaml:"notifications"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// NotificationDeleteRequest is the input-object for Delete in the
+// notification-service
+type NotificationDeleteRequest struct {
+	// Name of the notification-rule
+	Name string `json:"name" yaml:"name"`
+}
+
+// NotificationDeleteResponse is the output-object for Delete in the
+// notification-service
+type NotificationDeleteResponse struct {
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// NotificationDeliveriesRequest is the input-object for Deliveries in the
+// notification-service
+type NotificationDeliveriesRequest struct {
+	// Notification (name) to filter the deliveries with
+	Notification string `json:"notification" yaml:"notification"`
+	// Runner to filter the deliveries with
+	Runner string `json:"runner" yaml:"runner"`
+	// Status to filter the deliveries with (pending, delivered or failed)
+	Status string `json:"status" yaml:"status"`
+	// Limit is the max amount of deliveries to return (defaults to 100)
+	Limit int64 `json:"limit" yaml:"limit"`
+	// PageToken is the token for the page to return, from NextPageToken of the
+	// previous page
+	PageToken string `json:"pageToken" yaml:"pageToken"`
+}
+
+// NotificationDeliveriesResponse is the output-object for Deliveries in the
+// notification-service
+type NotificationDeliveriesResponse struct {
+	// Deliveries with the latest first
+	Deliveries []Delivery `json:"deliveries" yaml:"deliveries"`
+	// NextPageToken is the token for the next page, empty if it is the last page
+	NextPageToken string `json:"nextPageToken" yaml:"nextPageToken"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// NotificationListRequest is the input-object for List in the notification-service
+type NotificationListRequest struct {
+}
+
+// NotificationListResponse is the output-object for List in the
+// notification-service
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications" yaml:"notifications"`
 	// Error is string explaining what went wrong. Empty if everything was fine.
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
@@ -727,6 +1661,8 @@ type Process struct {
 	ProfilePath string `json:"profilePath" yaml:"profilePath"`
 	// EvidenceStore to process to the nuix-case
 	EvidenceStore []*Evidence `json:"evidenceStore" yaml:"evidenceStore"`
+	// Manifest - if a chain-of-custody manifest should be created for the evidence
+	Manifest bool `json:"manifest" yaml:"manifest"`
 	// Status for the stage
 	Status int64 `json:"status" yaml:"status"`
 }
@@ -766,6 +1702,9 @@ type Runner struct {
 	Status int64 `json:"status" yaml:"status"`
 	// HealthyAt - last time the runner was healthy
 	HealthyAt *time.Time `json:"healthyAt" yaml:"healthyAt"`
+	// RunID is the id for the current run of the runner, it is set every time the
+	// runner is started by the queue
+	RunID string `json:"runID" yaml:"runID"`
 	// CaseSettings for the cases to use
 	CaseSettingsID uint          `json:"caseSettingsID" yaml:"caseSettingsID"`
 	CaseSettings   *CaseSettings `json:"caseSettings" yaml:"caseSettings"`
@@ -773,6 +1712,9 @@ type Runner struct {
 	Stages []*Stage `json:"stages" yaml:"stages"`
 	// Switches to use for nuix-console
 	Switches []*NuixSwitch `json:"switches" yaml:"switches"`
+	// Engine for the script of the runner (ruby or python), the engine for the server
+	// is used if empty
+	Engine string `json:"engine" yaml:"engine"`
 }
 
 // RunnerApplyRequest is the input-object for applying a runner-configuration to
@@ -797,6 +1739,8 @@ type RunnerApplyRequest struct {
 	Stages []*Stage `json:"stages" yaml:"stages"`
 	// Switches to use for nuix-console
 	Switches []string `json:"switches" yaml:"switches"`
+	// Engine for the script of the runner (ruby or python)
+	Engine string `json:"engine" yaml:"engine"`
 	// Update - if the runner should be updated
 	Update bool `json:"update" yaml:"update"`
 }
@@ -819,6 +1763,9 @@ type RunnerDeleteRequest struct {
 	DeleteAllCases bool `json:"deleteAllCases" yaml:"deleteAllCases"`
 	// Force - if the delete should be forced
 	Force bool `json:"force" yaml:"force"`
+	// Purge - if the runner should be permanently deleted instead of moved to the
+	// trash
+	Purge bool `json:"purge" yaml:"purge"`
 }
 
 // RunnerDeleteResponse is the output-object for deleting a runner by name
@@ -831,6 +1778,7 @@ type RunnerDeleteResponse struct {
 type RunnerFailedRequest struct {
 	ID        uint   `json:"id" yaml:"id"`
 	Runner    string `json:"runner" yaml:"runner"`
+	RunID     string `json:"runID" yaml:"runID"`
 	Exception string `json:"exception" yaml:"exception"`
 }
 
@@ -844,6 +1792,8 @@ type RunnerFailedResponse struct {
 type RunnerFinishRequest struct {
 	ID     uint   `json:"id" yaml:"id"`
 	Runner string `json:"runner" yaml:"runner"`
+	// RunID for the run of the runner-script
+	RunID string `json:"runID" yaml:"runID"`
 }
 
 // RunnerFinishResponse is the output-object for finishing a runner by id
@@ -866,17 +1816,109 @@ type RunnerGetResponse struct {
 
 // RunnerListRequest is the input-object for listing the runners from the backend
 type RunnerListRequest struct {
+	// Status to filter the runners with (waiting, running, failed, finished or
+	// timeout)
+	Status string `json:"status" yaml:"status"`
+	// Hostname of the server to filter the runners with
+	Hostname string `json:"hostname" yaml:"hostname"`
+	// Nms to filter the runners with
+	Nms string `json:"nms" yaml:"nms"`
+	// Licence to filter the runners with
+	Licence string `json:"licence" yaml:"licence"`
+	// Name to filter the runners with, a glob-pattern (for example case-*)
+	Name string `json:"name" yaml:"name"`
+	// CreatedAfter and CreatedBefore filters the runners by when they were created
+	// (unix-time)
+	CreatedAfter  int64 `json:"createdAfter" yaml:"createdAfter"`
+	CreatedBefore int64 `json:"createdBefore" yaml:"createdBefore"`
+	// Sort is the field to sort by (id, name, created, status or hostname), prefixed
+	// with - for descending order
+	Sort string `json:"sort" yaml:"sort"`
+	// Limit is the max amount of runners to return (defaults to 100)
+	Limit int64 `json:"limit" yaml:"limit"`
+	// PageToken is the token for the page to return, from NextPageToken of the
+	// previous page
+	PageToken string `json:"pageToken" yaml:"pageToken"`
 }
 
 // RunnerListResponse is the input-object for listing the runners from the backend
 type RunnerListResponse struct {
 	Runners []Runner `json:"runners" yaml:"runners"`
+	// NextPageToken is the token for the next page, empty if it is the last page
+	NextPageToken string `json:"nextPageToken" yaml:"nextPageToken"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// RunnerListTrashRequest is the input-object for listing the runners in the trash
+type RunnerListTrashRequest struct {
+	// Limit is the max amount of runners to return (defaults to 100)
+	Limit int64 `json:"limit" yaml:"limit"`
+	// PageToken is the token for the page to return, from NextPageToken of the
+	// previous page
+	PageToken string `json:"pageToken" yaml:"pageToken"`
+}
+
+// RunnerListTrashResponse is the output-object for listing the runners in the
+// trash
+type RunnerListTrashResponse struct {
+	Runners []Runner `json:"runners" yaml:"runners"`
+	// NextPageToken is the token for the next page, empty if it is the last page
+	NextPageToken string `json:"nextPageToken" yaml:"nextPageToken"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// RunnerManifestRequest is the input-object for requesting the manifests for a
+// runner
+type RunnerManifestRequest struct {
+	// Name of the runner
+	Name string `json:"name" yaml:"name"`
+}
+
+// RunnerManifestResponse is the output-object for requesting the manifests for a
+// runner
+type RunnerManifestResponse struct {
+	Manifests []Manifest `json:"manifests" yaml:"manifests"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// RunnerPurgeRequest is the input-object for permanently deleting the runners in
+// the trash
+type RunnerPurgeRequest struct {
+	// OlderThan is the duration (for example 720h or 30d) the runners must have been
+	// in the trash to be purged
+	OlderThan string `json:"olderThan" yaml:"olderThan"`
+}
+
+// RunnerPurgeResponse is the output-object for permanently deleting the runners in
+// the trash
+type RunnerPurgeResponse struct {
+	// Runners are the names of the purged runners
+	Runners []string `json:"runners" yaml:"runners"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// RunnerRestoreRequest is the input-object for restoring a runner from the trash
+// by name
+type RunnerRestoreRequest struct {
+	// Name of the runner
+	Name string `json:"name" yaml:"name"`
+}
+
+// RunnerRestoreResponse is the output-object for restoring a runner from the trash
+// by name
+type RunnerRestoreResponse struct {
+	Runner Runner `json:"runner" yaml:"runner"`
 	// Error is string explaining what went wrong. Empty if everything was fine.
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
 type StageRequest struct {
 	Runner  string `json:"runner" yaml:"runner"`
+	RunID   string `json:"runID" yaml:"runID"`
 	StageID uint   `json:"stageID" yaml:"stageID"`
 }
 
@@ -899,6 +1941,16 @@ type Stage struct {
 	Exclude *Exclude `json:"exclude" yaml:"exclude"`
 	// Reload reloads items in a Nuix-case based on a search
 	Reload *Reload `json:"reload" yaml:"reload"`
+	// Total is the estimated amount of work for the stage (items, search-hits or
+	// batches)
+	Total int64 `json:"total" yaml:"total"`
+	// Progress is the amount of work done for the stage
+	Progress int64 `json:"progress" yaml:"progress"`
+	// StartedAt is the time (unix) for when the stage was started
+	StartedAt int64 `json:"startedAt" yaml:"startedAt"`
+	// EstimatedFinish is the estimated time (unix) for when the stage will finish,
+	// based on the throughput of the stage
+	EstimatedFinish int64 `json:"estimatedFinish" yaml:"estimatedFinish"`
 }
 
 type StageResponse struct {
@@ -907,10 +1959,29 @@ type StageResponse struct {
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
+// RunnerScriptRequest is the input-object for generating the script for a runner
+type RunnerScriptRequest struct {
+	// Name of the stored runner
+	Name string `json:"name" yaml:"name"`
+	// Runner to generate the script for instead of a stored runner (from a local
+	// yml-file)
+	Runner *RunnerApplyRequest `json:"runner" yaml:"runner"`
+}
+
+// RunnerScriptResponse is the output-object for generating the script for a runner
+type RunnerScriptResponse struct {
+	// Script that would be run for the runner
+	Script string `json:"script" yaml:"script"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 // RunnerStartRequest is the input-object for starting a runner by id
 type RunnerStartRequest struct {
 	ID     uint   `json:"id" yaml:"id"`
 	Runner string `json:"runner" yaml:"runner"`
+	// RunID for the run of the runner-script
+	RunID string `json:"runID" yaml:"runID"`
 }
 
 // RunnerStartResponse is the output-object for starting a runner by id
@@ -934,6 +2005,54 @@ type SearchAndTag struct {
 	Status int64 `json:"status" yaml:"status"`
 }
 
+// Secret is a named secret, the value is encrypted with the master-key
+type Secret struct {
+	datastore.Base
+	// Name of the secret
+	Name string `json:"name" yaml:"name"`
+	// Value of the secret, it is never returned by the service
+	Value string `json:"value" yaml:"value"`
+}
+
+// SecretDeleteRequest is the input-object for deleting a secret
+type SecretDeleteRequest struct {
+	// Name of the secret
+	Name string `json:"name" yaml:"name"`
+}
+
+// SecretDeleteResponse is the output-object for deleting a secret
+type SecretDeleteResponse struct {
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// SecretListRequest is the input-object for listing the secrets
+type SecretListRequest struct {
+}
+
+// SecretListResponse is the output-object for listing the secrets
+type SecretListResponse struct {
+	Secrets []Secret `json:"secrets" yaml:"secrets"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// SecretSetRequest is the input-object for setting a secret
+type SecretSetRequest struct {
+	// Name of the secret
+	Name string `json:"name" yaml:"name"`
+	// Value of the secret
+	Value string `json:"value" yaml:"value"`
+}
+
+// SecretSetResponse is the output-object for setting a secret
+type SecretSetResponse struct {
+	// Secret that has been set
+	Secret Secret `json:"secret" yaml:"secret"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 // Server is the main-struct for the servers
 type Server struct {
 	datastore.Base
@@ -945,10 +2064,13 @@ type Server struct {
 	OperatingSystem string `json:"operatingSystem" yaml:"operatingSystem"`
 	// Username for connection to the server
 	Username string `json:"username" yaml:"username"`
-	// Password for connection to the server
+	// Password for connection to the server, it is write-only and masked in the
+	// responses - or a reference (env:<name>, file:<path> or secret:<name>)
 	Password string `json:"password" yaml:"password"`
 	// NuixPath to know where to run Nuix
 	NuixPath string `json:"nuixPath" yaml:"nuixPath"`
+	// Engine for the scripts of the runners on the server (ruby or python)
+	Engine string `json:"engine" yaml:"engine"`
 	// Active - if the server has an active job
 	Active bool `json:"active" yaml:"active"`
 }
@@ -961,6 +2083,7 @@ type ServerApplyRequest struct {
 	Username        string `json:"username" yaml:"username"`
 	Password        string `json:"password" yaml:"password"`
 	NuixPath        string `json:"nuixPath" yaml:"nuixPath"`
+	Engine          string `json:"engine" yaml:"engine"`
 }
 
 // ServerApplyResponse is the output-object for Apply in the server-service
@@ -970,13 +2093,145 @@ type ServerApplyResponse struct {
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
+// ServerDeleteRequest is the input-object for Delete in the server-service
+type ServerDeleteRequest struct {
+	// Hostname of the server
+	Hostname string `json:"hostname" yaml:"hostname"`
+}
+
+// ServerDeleteResponse is the output-object for Delete in the server-service
+type ServerDeleteResponse struct {
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ServerGetRequest is the input-object for Get in the server-service
+type ServerGetRequest struct {
+	// Hostname of the server
+	Hostname string `json:"hostname" yaml:"hostname"`
+}
+
+// ServerGetResponse is the output-object for Get in the server-service
+type ServerGetResponse struct {
+	Server Server `json:"server" yaml:"server"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 // ServerListRequest is the input-object for List in the server-service
 type ServerListRequest struct {
+	// Hostname to filter the servers with, a glob-pattern (for example dev*)
+	Hostname string `json:"hostname" yaml:"hostname"`
+	// Sort is the field to sort by (id, hostname or created), prefixed with - for
+	// descending order
+	Sort string `json:"sort" yaml:"sort"`
+	// Limit is the max amount of servers to return (defaults to 100)
+	Limit int64 `json:"limit" yaml:"limit"`
+	// PageToken is the token for the page to return, from NextPageToken of the
+	// previous page
+	PageToken string `json:"pageToken" yaml:"pageToken"`
 }
 
 // ServerListResponse is the output-object for List in the server-service
 type ServerListResponse struct {
 	Servers []Server `json:"servers" yaml:"servers"`
+	// NextPageToken is the token for the next page, empty if it is the last page
+	NextPageToken string `json:"nextPageToken" yaml:"nextPageToken"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ServerVerifyPasswordRequest is the input-object for VerifyPassword in the
+// server-service
+type ServerVerifyPasswordRequest struct {
+	// Hostname of the server
+	Hostname string `json:"hostname" yaml:"hostname"`
+	// Password to verify
+	Password string `json:"password" yaml:"password"`
+}
+
+// ServerVerifyPasswordResponse is the output-object for VerifyPassword in the
+// server-service
+type ServerVerifyPasswordResponse struct {
+	// Match - if the password matches the stored password
+	Match bool `json:"match" yaml:"match"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// StageProgressRequest is the input-object for setting the progress for a stage
+type StageProgressRequest struct {
+	Runner  string `json:"runner" yaml:"runner"`
+	RunID   string `json:"runID" yaml:"runID"`
+	StageID uint   `json:"stageID" yaml:"stageID"`
+	// Total is the estimated amount of items, search-hits or batches for the stage
+	Total int64 `json:"total" yaml:"total"`
+	// Count is the amount that is done for the stage
+	Count int64 `json:"count" yaml:"count"`
+}
+
+// Token is an api-token for the clients of the service
+type Token struct {
+	datastore.Base
+	// Name describes what the token is used for
+	Name string `json:"name" yaml:"name"`
+	// Key identifies the token in the requests
+	Key string `json:"key" yaml:"key"`
+	// Secret to verify the signatures of the requests with
+	Secret string `json:"secret" yaml:"secret"`
+	// RunnerID is set for the tokens of the runner-scripts, these tokens are only
+	// valid for the callbacks of the runner
+	RunnerID uint `json:"runnerID" yaml:"runnerID"`
+	// Runner the token is valid for
+	Runner string `json:"runner" yaml:"runner"`
+	// ExpiresAt is when the token expires (unix-time), zero if the token never expires
+	ExpiresAt int64 `json:"expiresAt" yaml:"expiresAt"`
+	// Admin is set for the tokens that can verify the stored passwords and create
+	// other admin-tokens
+	Admin bool `json:"admin" yaml:"admin"`
+}
+
+// TokenCreateRequest is the input-object for creating an api-token
+type TokenCreateRequest struct {
+	// Name describes what the token is used for
+	Name string `json:"name" yaml:"name"`
+	// Expires is the duration (for example 720h) the token is valid for, empty if it
+	// never expires
+	Expires string `json:"expires" yaml:"expires"`
+	// Admin creates an admin-token, the token for the request must be an admin-token
+	Admin bool `json:"admin" yaml:"admin"`
+}
+
+// TokenCreateResponse is the output-object for creating an api-token
+type TokenCreateResponse struct {
+	// Token that has been created
+	Token Token `json:"token" yaml:"token"`
+	// Value of the token (key.secret) for the clients, it is only returned when the
+	// token is created
+	Value string `json:"value" yaml:"value"`
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// TokenDeleteRequest is the input-object for revoking an api-token
+type TokenDeleteRequest struct {
+	// Key for the token to revoke
+	Key string `json:"key" yaml:"key"`
+}
+
+// TokenDeleteResponse is the output-object for revoking an api-token
+type TokenDeleteResponse struct {
+	// Error is string explaining what went wrong. Empty if everything was fine.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// TokenListRequest is the input-object for listing the api-tokens
+type TokenListRequest struct {
+}
+
+// TokenListResponse is the output-object for listing the api-tokens
+type TokenListResponse struct {
+	Tokens []Token `json:"tokens" yaml:"tokens"`
 	// Error is string explaining what went wrong. Empty if everything was fine.
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
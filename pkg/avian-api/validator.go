@@ -71,8 +71,8 @@ func (s *Stage) Validate() error {
 			if emptyString(evidence.Name) {
 				return fmt.Errorf("must specify name for evidence: #%d", i)
 			}
-			if emptyString(evidence.Directory) {
-				return fmt.Errorf("must specify directory for evidence: #%d", i)
+			if err := evidence.Validate(); err != nil {
+				return fmt.Errorf("evidence: %s - %v", evidence.Name, err)
 			}
 		}
 	}
@@ -130,6 +130,49 @@ func (s *Stage) Validate() error {
 	return nil
 }
 
+// Validate validates an Evidence
+func (e *Evidence) Validate() error {
+	if emptyString(e.Directory) && len(e.Paths) == 0 && emptyString(e.PathList) && emptyString(e.LoadFile) {
+		return errors.New("must specify directory, paths, pathList or loadFile for evidence")
+	}
+
+	for i, path := range e.Paths {
+		if emptyString(path.Path) {
+			return fmt.Errorf("must specify path for evidence-path #%d", i)
+		}
+	}
+
+	if len(e.Filters) != 0 && emptyString(e.Directory) && len(e.Paths) == 0 && emptyString(e.PathList) {
+		return errors.New("must specify directory, paths or pathList to use filters for evidence")
+	}
+
+	for i, filter := range e.Filters {
+		if emptyString(filter.Pattern) {
+			return fmt.Errorf("must specify pattern for filter #%d", i)
+		}
+	}
+
+	for i, metadata := range e.Metadata {
+		if emptyString(metadata.Key) {
+			return fmt.Errorf("must specify key for metadata #%d", i)
+		}
+	}
+	return nil
+}
+
+// SourcePaths returns the directory and paths
+// to add to the evidence-container
+func (e *Evidence) SourcePaths() []string {
+	var paths []string
+	if !emptyString(e.Directory) {
+		paths = append(paths, e.Directory)
+	}
+	for _, path := range e.Paths {
+		paths = append(paths, path.Path)
+	}
+	return paths
+}
+
 // Validate validates CaseSettings
 func (s *CaseSettings) Validate() error {
 	if s == nil {
@@ -152,7 +195,13 @@ func (r *Runner) Paths() []string {
 		if stage.Process != nil {
 			paths = append(paths, stage.Process.ProfilePath)
 			for _, evidence := range stage.Process.EvidenceStore {
-				paths = append(paths, evidence.Directory)
+				paths = append(paths, evidence.SourcePaths()...)
+				if !emptyString(evidence.PathList) {
+					paths = append(paths, evidence.PathList)
+				}
+				if !emptyString(evidence.LoadFile) {
+					paths = append(paths, evidence.LoadFile)
+				}
 			}
 		}
 
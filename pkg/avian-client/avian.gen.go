@@ -7,13 +7,16 @@ import (
 	"compress/gzip"
 	"context"
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 
 	datastore "github.com/avian-digital-forensics/auto-processing/pkg/datastore"
@@ -30,22 +33,107 @@ type Client struct {
 	HTTPClient *http.Client
 	// Debug writes a line of debug log output.
 	Debug func(s string)
+	// key identifies the api-token in the requests
+	key string
 	// secret is the Secret to make the HMAC signature
 	secret []byte
 }
 
-// New makes a new Client.
-func New(remoteHost, secret string) *Client {
+// New makes a new Client, the token is the
+// api-token (key.secret) to sign the requests with.
+func New(remoteHost, token string) *Client {
+	var key string
+	secret := token
+	if i := strings.Index(token, "."); i != -1 {
+		key, secret = token[:i], token[i+1:]
+	}
 	return &Client{
 		RemoteHost: remoteHost,
 		Debug:      func(s string) {},
 		// No timeout is set to HTTPClient
 		// since some operations takes too long
 		HTTPClient: &http.Client{},
+		key:        key,
 		secret:     []byte(secret),
 	}
 }
 
+// AuditService handles the audit-log for the administrative actions on the
+// runners, servers and nms
+type AuditService struct {
+	client *Client
+}
+
+// NewAuditService makes a new client for accessing AuditService services.
+func NewAuditService(client *Client) *AuditService {
+	return &AuditService{
+		client: client,
+	}
+}
+
+// List returns the entries from the audit-log
+func (s *AuditService) List(ctx context.Context, r AuditListRequest) (*AuditListResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "AuditService.List: marshal AuditListRequest")
+	}
+	url := s.client.RemoteHost + "AuditService.List"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "AuditService.List: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "AuditService.List: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "AuditService.List: generate signature AuditListRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "AuditService.List")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		AuditListResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "AuditService.List: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "AuditService.List: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("AuditService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.AuditListResponse, nil
+}
+
 // NmsService handles the Nuix Management Servers
 type NmsService struct {
 	client *Client
@@ -63,10 +151,6 @@ func (s *NmsService) Apply(ctx context.Context, r NmsApplyRequests) (*NmsApplyRe
 	if err != nil {
 		return nil, errors.Wrap(err, "NmsService.Apply: marshal NmsApplyRequests")
 	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.Apply: generate signature NmsApplyRequests")
-	}
 	url := s.client.RemoteHost + "NmsService.Apply"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
@@ -74,6 +158,18 @@ func (s *NmsService) Apply(ctx context.Context, r NmsApplyRequests) (*NmsApplyRe
 	if err != nil {
 		return nil, errors.Wrap(err, "NmsService.Apply: NewRequest")
 	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.Apply: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.Apply: generate signature NmsApplyRequests")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
@@ -112,14 +208,137 @@ func (s *NmsService) Apply(ctx context.Context, r NmsApplyRequests) (*NmsApplyRe
 	return &response.NmsApplyResponse, nil
 }
 
-func (s *NmsService) List(ctx context.Context, r NmsListRequest) (*NmsListResponse, error) {
+// Delete deletes the requested nms-server, unless it is used by any waiting or
+// active runners
+func (s *NmsService) Delete(ctx context.Context, r NmsDeleteRequest) (*NmsDeleteResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.List: marshal NmsListRequest")
+		return nil, errors.Wrap(err, "NmsService.Delete: marshal NmsDeleteRequest")
+	}
+	url := s.client.RemoteHost + "NmsService.Delete"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.Delete: NewRequest")
 	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.List: generate signature NmsListRequest")
+		return nil, errors.Wrap(err, "NmsService.Delete: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.Delete: generate signature NmsDeleteRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.Delete")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		NmsDeleteResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "NmsService.Delete: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.Delete: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("NmsService.Delete: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.NmsDeleteResponse, nil
+}
+
+// Get returns the requested nms-server
+func (s *NmsService) Get(ctx context.Context, r NmsGetRequest) (*NmsGetResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.Get: marshal NmsGetRequest")
+	}
+	url := s.client.RemoteHost + "NmsService.Get"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.Get: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.Get: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.Get: generate signature NmsGetRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.Get")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		NmsGetResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "NmsService.Get: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.Get: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("NmsService.Get: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.NmsGetResponse, nil
+}
+
+func (s *NmsService) List(ctx context.Context, r NmsListRequest) (*NmsListResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.List: marshal NmsListRequest")
 	}
 	url := s.client.RemoteHost + "NmsService.List"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
@@ -128,6 +347,18 @@ func (s *NmsService) List(ctx context.Context, r NmsListRequest) (*NmsListRespon
 	if err != nil {
 		return nil, errors.Wrap(err, "NmsService.List: NewRequest")
 	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.List: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.List: generate signature NmsListRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
@@ -171,366 +402,1759 @@ func (s *NmsService) ListLicences(ctx context.Context, r NmsListLicencesRequest)
 	if err != nil {
 		return nil, errors.Wrap(err, "NmsService.ListLicences: marshal NmsListLicencesRequest")
 	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+	url := s.client.RemoteHost + "NmsService.ListLicences"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.ListLicences: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.ListLicences: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
 	if err != nil {
 		return nil, errors.Wrap(err, "NmsService.ListLicences: generate signature NmsListLicencesRequest")
 	}
-	url := s.client.RemoteHost + "NmsService.ListLicences"
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.ListLicences")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		NmsListLicencesResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "NmsService.ListLicences: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.ListLicences: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("NmsService.ListLicences: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.NmsListLicencesResponse, nil
+}
+
+// VerifyPassword verifies the stored password for the nms-server, the password is
+// never returned by the service (it requires an admin-token)
+func (s *NmsService) VerifyPassword(ctx context.Context, r NmsVerifyPasswordRequest) (*NmsVerifyPasswordResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.VerifyPassword: marshal NmsVerifyPasswordRequest")
+	}
+	url := s.client.RemoteHost + "NmsService.VerifyPassword"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.VerifyPassword: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.VerifyPassword: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.VerifyPassword: generate signature NmsVerifyPasswordRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.VerifyPassword")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		NmsVerifyPasswordResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "NmsService.VerifyPassword: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "NmsService.VerifyPassword: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("NmsService.VerifyPassword: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.NmsVerifyPasswordResponse, nil
+}
+
+// NotificationService handles the notification-rules for the runners and the log
+// of the deliveries
+type NotificationService struct {
+	client *Client
+}
+
+// NewNotificationService makes a new client for accessing NotificationService services.
+func NewNotificationService(client *Client) *NotificationService {
+	return &NotificationService{
+		client: client,
+	}
+}
+
+// Apply creates or updates the notification-rules
+func (s *NotificationService) Apply(ctx context.Context, r NotificationApplyRequests) (*NotificationApplyResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Apply: marshal NotificationApplyRequests")
+	}
+	url := s.client.RemoteHost + "NotificationService.Apply"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Apply: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Apply: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Apply: generate signature NotificationApplyRequests")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Apply")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		NotificationApplyResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "NotificationService.Apply: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Apply: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("NotificationService.Apply: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.NotificationApplyResponse, nil
+}
+
+// Delete deletes the requested notification-rule
+func (s *NotificationService) Delete(ctx context.Context, r NotificationDeleteRequest) (*NotificationDeleteResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Delete: marshal NotificationDeleteRequest")
+	}
+	url := s.client.RemoteHost + "NotificationService.Delete"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Delete: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Delete: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Delete: generate signature NotificationDeleteRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Delete")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		NotificationDeleteResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "NotificationService.Delete: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Delete: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("NotificationService.Delete: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.NotificationDeleteResponse, nil
+}
+
+// Deliveries returns the log of the deliveries
+func (s *NotificationService) Deliveries(ctx context.Context, r NotificationDeliveriesRequest) (*NotificationDeliveriesResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Deliveries: marshal NotificationDeliveriesRequest")
+	}
+	url := s.client.RemoteHost + "NotificationService.Deliveries"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Deliveries: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Deliveries: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Deliveries: generate signature NotificationDeliveriesRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Deliveries")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		NotificationDeliveriesResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "NotificationService.Deliveries: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Deliveries: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("NotificationService.Deliveries: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.NotificationDeliveriesResponse, nil
+}
+
+// List returns the notification-rules
+func (s *NotificationService) List(ctx context.Context, r NotificationListRequest) (*NotificationListResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.List: marshal NotificationListRequest")
+	}
+	url := s.client.RemoteHost + "NotificationService.List"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.List: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.List: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.List: generate signature NotificationListRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.List")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		NotificationListResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "NotificationService.List: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.List: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("NotificationService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.NotificationListResponse, nil
+}
+
+// RunnerService handles all the runners
+type RunnerService struct {
+	client *Client
+}
+
+// NewRunnerService makes a new client for accessing RunnerService services.
+func NewRunnerService(client *Client) *RunnerService {
+	return &RunnerService{
+		client: client,
+	}
+}
+
+// Apply applies the configuration to the backend
+func (s *RunnerService) Apply(ctx context.Context, r RunnerApplyRequest) (*RunnerApplyResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Apply: marshal RunnerApplyRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Apply"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Apply: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Apply: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Apply: generate signature RunnerApplyRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Apply")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerApplyResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Apply: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Apply: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Apply: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerApplyResponse, nil
+}
+
+// Delete moves the requested Runner to the trash
+func (s *RunnerService) Delete(ctx context.Context, r RunnerDeleteRequest) (*RunnerDeleteResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Delete: marshal RunnerDeleteRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Delete"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Delete: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Delete: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Delete: generate signature RunnerDeleteRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Delete")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerDeleteResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Delete: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Delete: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Delete: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerDeleteResponse, nil
+}
+
+// Failed sets a runner to failed
+func (s *RunnerService) Failed(ctx context.Context, r RunnerFailedRequest) (*RunnerFailedResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Failed: marshal RunnerFailedRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Failed"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Failed: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Failed: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Failed: generate signature RunnerFailedRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Failed")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerFailedResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Failed: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Failed: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Failed: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerFailedResponse, nil
+}
+
+// FailedStage sets a stage to Failed
+func (s *RunnerService) FailedStage(ctx context.Context, r StageRequest) (*StageResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FailedStage: marshal StageRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.FailedStage"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FailedStage: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FailedStage: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FailedStage: generate signature StageRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FailedStage")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		StageResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.FailedStage: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FailedStage: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.FailedStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.StageResponse, nil
+}
+
+// Finish sets a runner to finished
+func (s *RunnerService) Finish(ctx context.Context, r RunnerFinishRequest) (*RunnerFinishResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Finish: marshal RunnerFinishRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Finish"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Finish: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Finish: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Finish: generate signature RunnerFinishRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Finish")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerFinishResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Finish: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Finish: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Finish: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerFinishResponse, nil
+}
+
+// FinishStage sets a stage to Finished
+func (s *RunnerService) FinishStage(ctx context.Context, r StageRequest) (*StageResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FinishStage: marshal StageRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.FinishStage"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FinishStage: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FinishStage: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FinishStage: generate signature StageRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FinishStage")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		StageResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.FinishStage: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.FinishStage: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.FinishStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.StageResponse, nil
+}
+
+// Get returns the requested Runner
+func (s *RunnerService) Get(ctx context.Context, r RunnerGetRequest) (*RunnerGetResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Get: marshal RunnerGetRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Get"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Get: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Get: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Get: generate signature RunnerGetRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Get")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerGetResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Get: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Get: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Get: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerGetResponse, nil
+}
+
+// Heartbeat sends a heartbeat for the api
+func (s *RunnerService) Heartbeat(ctx context.Context, r RunnerStartRequest) (*RunnerStartResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Heartbeat: marshal RunnerStartRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Heartbeat"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Heartbeat: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Heartbeat: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Heartbeat: generate signature RunnerStartRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Heartbeat")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerStartResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.Heartbeat: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Heartbeat: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.Heartbeat: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerStartResponse, nil
+}
+
+// List returns the runners from the backend
+func (s *RunnerService) List(ctx context.Context, r RunnerListRequest) (*RunnerListResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.List: marshal RunnerListRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.List"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.List: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.List: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.List: generate signature RunnerListRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.List")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerListResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.List: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.List: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerListResponse, nil
+}
+
+// ListTrash returns the runners in the trash
+func (s *RunnerService) ListTrash(ctx context.Context, r RunnerListTrashRequest) (*RunnerListTrashResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.ListTrash: marshal RunnerListTrashRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.ListTrash"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.ListTrash: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.ListTrash: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.ListTrash: generate signature RunnerListTrashRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.ListTrash")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		RunnerListTrashResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.ListTrash: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.ListTrash: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.ListTrash: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.RunnerListTrashResponse, nil
+}
+
+// LogDebug logs a debug-message
+func (s *RunnerService) LogDebug(ctx context.Context, r LogRequest) (*LogResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogDebug: marshal LogRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.LogDebug"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogDebug: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogDebug: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogDebug: generate signature LogRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogDebug")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		LogResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.LogDebug: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogDebug: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.LogDebug: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.LogResponse, nil
+}
+
+// LogError logs an error-message
+func (s *RunnerService) LogError(ctx context.Context, r LogRequest) (*LogResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogError: marshal LogRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.LogError"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogError: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogError: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogError: generate signature LogRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogError")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		LogResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.LogError: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogError: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.LogError: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.LogResponse, nil
+}
+
+// LogInfo logs an info-message
+func (s *RunnerService) LogInfo(ctx context.Context, r LogRequest) (*LogResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogInfo: marshal LogRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.LogInfo"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogInfo: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogInfo: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogInfo: generate signature LogRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogInfo")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		LogResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.LogInfo: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogInfo: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.LogInfo: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.LogResponse, nil
+}
+
+// LogItem logs an item
+func (s *RunnerService) LogItem(ctx context.Context, r LogItemRequest) (*LogResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItem: marshal LogItemRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.LogItem"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItem: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItem: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItem: generate signature LogItemRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItem")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		LogResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.LogItem: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItem: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.LogItem: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.LogResponse, nil
+}
+
+// LogItems logs a batch of items
+func (s *RunnerService) LogItems(ctx context.Context, r LogItemsRequest) (*LogResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItems: marshal LogItemsRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.LogItems"
+	s.client.Debug(fmt.Sprintf("POST %s", url))
+	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItems: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItems: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItems: generate signature LogItemsRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
+	req.Header.Set("X-API-SIGNATURE", signature)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req = req.WithContext(ctx)
+	resp, err := s.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItems")
+	}
+	defer resp.Body.Close()
+	var response struct {
+		LogResponse
+		Error string
+	}
+	var bodyReader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		decodedBody, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "RunnerService.LogItems: new gzip reader")
+		}
+		defer decodedBody.Close()
+		bodyReader = decodedBody
+	}
+	respBodyBytes, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.LogItems: read response body")
+	}
+	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("RunnerService.LogItems: (%d) %v", resp.StatusCode, string(respBodyBytes))
+		}
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return &response.LogResponse, nil
+}
+
+// Manifest returns the evidence-manifests for the requested Runner
+func (s *RunnerService) Manifest(ctx context.Context, r RunnerManifestRequest) (*RunnerManifestResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Manifest: marshal RunnerManifestRequest")
+	}
+	url := s.client.RemoteHost + "RunnerService.Manifest"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.ListLicences: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.Manifest: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Manifest: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Manifest: generate signature RunnerManifestRequest")
 	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.ListLicences")
+		return nil, errors.Wrap(err, "RunnerService.Manifest")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		NmsListLicencesResponse
+		RunnerManifestResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "NmsService.ListLicences: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.Manifest: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "NmsService.ListLicences: read response body")
+		return nil, errors.Wrap(err, "RunnerService.Manifest: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("NmsService.ListLicences: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.Manifest: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.NmsListLicencesResponse, nil
-}
-
-// RunnerService handles all the runners
-type RunnerService struct {
-	client *Client
-}
-
-// NewRunnerService makes a new client for accessing RunnerService services.
-func NewRunnerService(client *Client) *RunnerService {
-	return &RunnerService{
-		client: client,
-	}
+	return &response.RunnerManifestResponse, nil
 }
 
-// Apply applies the configuration to the backend
-func (s *RunnerService) Apply(ctx context.Context, r RunnerApplyRequest) (*RunnerApplyResponse, error) {
+// ProgressStage sets the progress for a stage
+func (s *RunnerService) ProgressStage(ctx context.Context, r StageProgressRequest) (*StageResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Apply: marshal RunnerApplyRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Apply: generate signature RunnerApplyRequest")
+		return nil, errors.Wrap(err, "RunnerService.ProgressStage: marshal StageProgressRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Apply"
+	url := s.client.RemoteHost + "RunnerService.ProgressStage"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Apply: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.ProgressStage: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.ProgressStage: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.ProgressStage: generate signature StageProgressRequest")
 	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Apply")
+		return nil, errors.Wrap(err, "RunnerService.ProgressStage")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerApplyResponse
+		StageResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Apply: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.ProgressStage: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Apply: read response body")
+		return nil, errors.Wrap(err, "RunnerService.ProgressStage: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Apply: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.ProgressStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerApplyResponse, nil
+	return &response.StageResponse, nil
 }
 
-// Delete deletes the requested Runner
-func (s *RunnerService) Delete(ctx context.Context, r RunnerDeleteRequest) (*RunnerDeleteResponse, error) {
+// Purge permanently deletes the runners in the trash
+func (s *RunnerService) Purge(ctx context.Context, r RunnerPurgeRequest) (*RunnerPurgeResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Delete: marshal RunnerDeleteRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Delete: generate signature RunnerDeleteRequest")
+		return nil, errors.Wrap(err, "RunnerService.Purge: marshal RunnerPurgeRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Delete"
+	url := s.client.RemoteHost + "RunnerService.Purge"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Delete: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.Purge: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Purge: generate nonce")
 	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Purge: generate signature RunnerPurgeRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Delete")
+		return nil, errors.Wrap(err, "RunnerService.Purge")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerDeleteResponse
+		RunnerPurgeResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Delete: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.Purge: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Delete: read response body")
+		return nil, errors.Wrap(err, "RunnerService.Purge: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Delete: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.Purge: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerDeleteResponse, nil
+	return &response.RunnerPurgeResponse, nil
 }
 
-// Failed sets a runner to failed
-func (s *RunnerService) Failed(ctx context.Context, r RunnerFailedRequest) (*RunnerFailedResponse, error) {
+// Restore restores the requested Runner from the trash
+func (s *RunnerService) Restore(ctx context.Context, r RunnerRestoreRequest) (*RunnerRestoreResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Failed: marshal RunnerFailedRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Failed: generate signature RunnerFailedRequest")
+		return nil, errors.Wrap(err, "RunnerService.Restore: marshal RunnerRestoreRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Failed"
+	url := s.client.RemoteHost + "RunnerService.Restore"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Failed: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.Restore: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Restore: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Restore: generate signature RunnerRestoreRequest")
 	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Failed")
+		return nil, errors.Wrap(err, "RunnerService.Restore")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerFailedResponse
+		RunnerRestoreResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Failed: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.Restore: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Failed: read response body")
+		return nil, errors.Wrap(err, "RunnerService.Restore: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Failed: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.Restore: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerFailedResponse, nil
+	return &response.RunnerRestoreResponse, nil
 }
 
-// FailedStage sets a stage to Failed
-func (s *RunnerService) FailedStage(ctx context.Context, r StageRequest) (*StageResponse, error) {
+// Script returns the generated script for the requested Runner
+func (s *RunnerService) Script(ctx context.Context, r RunnerScriptRequest) (*RunnerScriptResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FailedStage: marshal StageRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FailedStage: generate signature StageRequest")
+		return nil, errors.Wrap(err, "RunnerService.Script: marshal RunnerScriptRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.FailedStage"
+	url := s.client.RemoteHost + "RunnerService.Script"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FailedStage: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.Script: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Script: generate nonce")
 	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Script: generate signature RunnerScriptRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FailedStage")
+		return nil, errors.Wrap(err, "RunnerService.Script")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		StageResponse
+		RunnerScriptResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.FailedStage: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.Script: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FailedStage: read response body")
+		return nil, errors.Wrap(err, "RunnerService.Script: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.FailedStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.Script: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.StageResponse, nil
+	return &response.RunnerScriptResponse, nil
 }
 
-// Finish sets a runner to finished
-func (s *RunnerService) Finish(ctx context.Context, r RunnerFinishRequest) (*RunnerFinishResponse, error) {
+// Start sets a runner to started
+func (s *RunnerService) Start(ctx context.Context, r RunnerStartRequest) (*RunnerStartResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Finish: marshal RunnerFinishRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Finish: generate signature RunnerFinishRequest")
+		return nil, errors.Wrap(err, "RunnerService.Start: marshal RunnerStartRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Finish"
+	url := s.client.RemoteHost + "RunnerService.Start"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Finish: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.Start: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Start: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.Start: generate signature RunnerStartRequest")
 	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Finish")
+		return nil, errors.Wrap(err, "RunnerService.Start")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerFinishResponse
+		RunnerStartResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Finish: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.Start: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Finish: read response body")
+		return nil, errors.Wrap(err, "RunnerService.Start: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Finish: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.Start: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerFinishResponse, nil
+	return &response.RunnerStartResponse, nil
 }
 
-// FinishStage sets a stage to Finished
-func (s *RunnerService) FinishStage(ctx context.Context, r StageRequest) (*StageResponse, error) {
+// StartStage sets a stage to Active
+func (s *RunnerService) StartStage(ctx context.Context, r StageRequest) (*StageResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FinishStage: marshal StageRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FinishStage: generate signature StageRequest")
+		return nil, errors.Wrap(err, "RunnerService.StartStage: marshal StageRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.FinishStage"
+	url := s.client.RemoteHost + "RunnerService.StartStage"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FinishStage: NewRequest")
+		return nil, errors.Wrap(err, "RunnerService.StartStage: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.StartStage: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "RunnerService.StartStage: generate signature StageRequest")
 	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FinishStage")
+		return nil, errors.Wrap(err, "RunnerService.StartStage")
 	}
 	defer resp.Body.Close()
 	var response struct {
@@ -541,18 +2165,18 @@ func (s *RunnerService) FinishStage(ctx context.Context, r StageRequest) (*Stage
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.FinishStage: new gzip reader")
+			return nil, errors.Wrap(err, "RunnerService.StartStage: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.FinishStage: read response body")
+		return nil, errors.Wrap(err, "RunnerService.StartStage: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.FinishStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("RunnerService.StartStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
@@ -562,619 +2186,790 @@ func (s *RunnerService) FinishStage(ctx context.Context, r StageRequest) (*Stage
 	return &response.StageResponse, nil
 }
 
-// Get returns the requested Runner
-func (s *RunnerService) Get(ctx context.Context, r RunnerGetRequest) (*RunnerGetResponse, error) {
-	requestBodyBytes, err := json.Marshal(r)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Get: marshal RunnerGetRequest")
+// SecretService handles the named secrets, they are referenced from the
+// credentials in the configs with secret:<name>
+type SecretService struct {
+	client *Client
+}
+
+// NewSecretService makes a new client for accessing SecretService services.
+func NewSecretService(client *Client) *SecretService {
+	return &SecretService{
+		client: client,
 	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+}
+
+// Delete deletes the requested secret, unless it is referenced by any servers or
+// nms-servers
+func (s *SecretService) Delete(ctx context.Context, r SecretDeleteRequest) (*SecretDeleteResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Get: generate signature RunnerGetRequest")
+		return nil, errors.Wrap(err, "SecretService.Delete: marshal SecretDeleteRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Get"
+	url := s.client.RemoteHost + "SecretService.Delete"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Get: NewRequest")
+		return nil, errors.Wrap(err, "SecretService.Delete: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "SecretService.Delete: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "SecretService.Delete: generate signature SecretDeleteRequest")
 	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Get")
+		return nil, errors.Wrap(err, "SecretService.Delete")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerGetResponse
+		SecretDeleteResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Get: new gzip reader")
+			return nil, errors.Wrap(err, "SecretService.Delete: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Get: read response body")
+		return nil, errors.Wrap(err, "SecretService.Delete: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Get: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("SecretService.Delete: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerGetResponse, nil
+	return &response.SecretDeleteResponse, nil
 }
 
-// Heartbeat sends a heartbeat for the api
-func (s *RunnerService) Heartbeat(ctx context.Context, r RunnerStartRequest) (*RunnerStartResponse, error) {
+// List returns the secrets without their values
+func (s *SecretService) List(ctx context.Context, r SecretListRequest) (*SecretListResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Heartbeat: marshal RunnerStartRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Heartbeat: generate signature RunnerStartRequest")
+		return nil, errors.Wrap(err, "SecretService.List: marshal SecretListRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Heartbeat"
+	url := s.client.RemoteHost + "SecretService.List"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Heartbeat: NewRequest")
+		return nil, errors.Wrap(err, "SecretService.List: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "SecretService.List: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "SecretService.List: generate signature SecretListRequest")
 	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Heartbeat")
+		return nil, errors.Wrap(err, "SecretService.List")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerStartResponse
+		SecretListResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Heartbeat: new gzip reader")
+			return nil, errors.Wrap(err, "SecretService.List: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Heartbeat: read response body")
+		return nil, errors.Wrap(err, "SecretService.List: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Heartbeat: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("SecretService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerStartResponse, nil
+	return &response.SecretListResponse, nil
 }
 
-// List returns the runners from the backend
-func (s *RunnerService) List(ctx context.Context, r RunnerListRequest) (*RunnerListResponse, error) {
+// Set creates or updates a secret
+func (s *SecretService) Set(ctx context.Context, r SecretSetRequest) (*SecretSetResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.List: marshal RunnerListRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.List: generate signature RunnerListRequest")
+		return nil, errors.Wrap(err, "SecretService.Set: marshal SecretSetRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.List"
+	url := s.client.RemoteHost + "SecretService.Set"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.List: NewRequest")
+		return nil, errors.Wrap(err, "SecretService.Set: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "SecretService.Set: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "SecretService.Set: generate signature SecretSetRequest")
 	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.List")
+		return nil, errors.Wrap(err, "SecretService.Set")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerListResponse
+		SecretSetResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.List: new gzip reader")
+			return nil, errors.Wrap(err, "SecretService.Set: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.List: read response body")
+		return nil, errors.Wrap(err, "SecretService.Set: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("SecretService.Set: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerListResponse, nil
+	return &response.SecretSetResponse, nil
 }
 
-// LogDebug logs a debug-message
-func (s *RunnerService) LogDebug(ctx context.Context, r LogRequest) (*LogResponse, error) {
-	requestBodyBytes, err := json.Marshal(r)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogDebug: marshal LogRequest")
+// ServerService handles all the servers
+type ServerService struct {
+	client *Client
+}
+
+// NewServerService makes a new client for accessing ServerService services.
+func NewServerService(client *Client) *ServerService {
+	return &ServerService{
+		client: client,
 	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+}
+
+func (s *ServerService) Apply(ctx context.Context, r ServerApplyRequest) (*ServerApplyResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogDebug: generate signature LogRequest")
+		return nil, errors.Wrap(err, "ServerService.Apply: marshal ServerApplyRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.LogDebug"
+	url := s.client.RemoteHost + "ServerService.Apply"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogDebug: NewRequest")
+		return nil, errors.Wrap(err, "ServerService.Apply: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "ServerService.Apply: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "ServerService.Apply: generate signature ServerApplyRequest")
 	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogDebug")
+		return nil, errors.Wrap(err, "ServerService.Apply")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		LogResponse
+		ServerApplyResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.LogDebug: new gzip reader")
+			return nil, errors.Wrap(err, "ServerService.Apply: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogDebug: read response body")
+		return nil, errors.Wrap(err, "ServerService.Apply: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.LogDebug: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("ServerService.Apply: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.LogResponse, nil
+	return &response.ServerApplyResponse, nil
 }
 
-// LogError logs an error-message
-func (s *RunnerService) LogError(ctx context.Context, r LogRequest) (*LogResponse, error) {
+// Delete deletes the requested server, unless it is used by any waiting or active
+// runners
+func (s *ServerService) Delete(ctx context.Context, r ServerDeleteRequest) (*ServerDeleteResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogError: marshal LogRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogError: generate signature LogRequest")
+		return nil, errors.Wrap(err, "ServerService.Delete: marshal ServerDeleteRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.LogError"
+	url := s.client.RemoteHost + "ServerService.Delete"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogError: NewRequest")
+		return nil, errors.Wrap(err, "ServerService.Delete: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "ServerService.Delete: generate nonce")
 	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "ServerService.Delete: generate signature ServerDeleteRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogError")
+		return nil, errors.Wrap(err, "ServerService.Delete")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		LogResponse
+		ServerDeleteResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.LogError: new gzip reader")
+			return nil, errors.Wrap(err, "ServerService.Delete: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogError: read response body")
+		return nil, errors.Wrap(err, "ServerService.Delete: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.LogError: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("ServerService.Delete: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.LogResponse, nil
+	return &response.ServerDeleteResponse, nil
 }
 
-// LogInfo logs an info-message
-func (s *RunnerService) LogInfo(ctx context.Context, r LogRequest) (*LogResponse, error) {
+// Get returns the requested server
+func (s *ServerService) Get(ctx context.Context, r ServerGetRequest) (*ServerGetResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogInfo: marshal LogRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogInfo: generate signature LogRequest")
+		return nil, errors.Wrap(err, "ServerService.Get: marshal ServerGetRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.LogInfo"
+	url := s.client.RemoteHost + "ServerService.Get"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogInfo: NewRequest")
+		return nil, errors.Wrap(err, "ServerService.Get: NewRequest")
 	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "ServerService.Get: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "ServerService.Get: generate signature ServerGetRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogInfo")
+		return nil, errors.Wrap(err, "ServerService.Get")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		LogResponse
+		ServerGetResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.LogInfo: new gzip reader")
+			return nil, errors.Wrap(err, "ServerService.Get: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogInfo: read response body")
+		return nil, errors.Wrap(err, "ServerService.Get: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.LogInfo: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("ServerService.Get: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.LogResponse, nil
+	return &response.ServerGetResponse, nil
 }
 
-// LogItem logs an item
-func (s *RunnerService) LogItem(ctx context.Context, r LogItemRequest) (*LogResponse, error) {
+func (s *ServerService) List(ctx context.Context, r ServerListRequest) (*ServerListResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogItem: marshal LogItemRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogItem: generate signature LogItemRequest")
+		return nil, errors.Wrap(err, "ServerService.List: marshal ServerListRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.LogItem"
+	url := s.client.RemoteHost + "ServerService.List"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogItem: NewRequest")
+		return nil, errors.Wrap(err, "ServerService.List: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "ServerService.List: generate nonce")
 	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "ServerService.List: generate signature ServerListRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogItem")
+		return nil, errors.Wrap(err, "ServerService.List")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		LogResponse
+		ServerListResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.LogItem: new gzip reader")
+			return nil, errors.Wrap(err, "ServerService.List: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.LogItem: read response body")
+		return nil, errors.Wrap(err, "ServerService.List: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.LogItem: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("ServerService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.LogResponse, nil
+	return &response.ServerListResponse, nil
 }
 
-// Start sets a runner to started
-func (s *RunnerService) Start(ctx context.Context, r RunnerStartRequest) (*RunnerStartResponse, error) {
+// VerifyPassword verifies the stored password for the server, the password is
+// never returned by the service (it requires an admin-token)
+func (s *ServerService) VerifyPassword(ctx context.Context, r ServerVerifyPasswordRequest) (*ServerVerifyPasswordResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Start: marshal RunnerStartRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Start: generate signature RunnerStartRequest")
+		return nil, errors.Wrap(err, "ServerService.VerifyPassword: marshal ServerVerifyPasswordRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.Start"
+	url := s.client.RemoteHost + "ServerService.VerifyPassword"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Start: NewRequest")
+		return nil, errors.Wrap(err, "ServerService.VerifyPassword: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "ServerService.VerifyPassword: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "ServerService.VerifyPassword: generate signature ServerVerifyPasswordRequest")
 	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Start")
+		return nil, errors.Wrap(err, "ServerService.VerifyPassword")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		RunnerStartResponse
+		ServerVerifyPasswordResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.Start: new gzip reader")
+			return nil, errors.Wrap(err, "ServerService.VerifyPassword: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.Start: read response body")
+		return nil, errors.Wrap(err, "ServerService.VerifyPassword: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.Start: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("ServerService.VerifyPassword: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.RunnerStartResponse, nil
+	return &response.ServerVerifyPasswordResponse, nil
 }
 
-// StartStage sets a stage to Active
-func (s *RunnerService) StartStage(ctx context.Context, r StageRequest) (*StageResponse, error) {
-	requestBodyBytes, err := json.Marshal(r)
-	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.StartStage: marshal StageRequest")
+// TokenService handles the api-tokens
+type TokenService struct {
+	client *Client
+}
+
+// NewTokenService makes a new client for accessing TokenService services.
+func NewTokenService(client *Client) *TokenService {
+	return &TokenService{
+		client: client,
 	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
+}
+
+// Create creates a new api-token
+func (s *TokenService) Create(ctx context.Context, r TokenCreateRequest) (*TokenCreateResponse, error) {
+	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.StartStage: generate signature StageRequest")
+		return nil, errors.Wrap(err, "TokenService.Create: marshal TokenCreateRequest")
 	}
-	url := s.client.RemoteHost + "RunnerService.StartStage"
+	url := s.client.RemoteHost + "TokenService.Create"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.StartStage: NewRequest")
+		return nil, errors.Wrap(err, "TokenService.Create: NewRequest")
 	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "TokenService.Create: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "TokenService.Create: generate signature TokenCreateRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.StartStage")
+		return nil, errors.Wrap(err, "TokenService.Create")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		StageResponse
+		TokenCreateResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "RunnerService.StartStage: new gzip reader")
+			return nil, errors.Wrap(err, "TokenService.Create: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "RunnerService.StartStage: read response body")
+		return nil, errors.Wrap(err, "TokenService.Create: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("RunnerService.StartStage: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("TokenService.Create: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.StageResponse, nil
-}
-
-// ServerService handles all the servers
-type ServerService struct {
-	client *Client
-}
-
-// NewServerService makes a new client for accessing ServerService services.
-func NewServerService(client *Client) *ServerService {
-	return &ServerService{
-		client: client,
-	}
+	return &response.TokenCreateResponse, nil
 }
 
-func (s *ServerService) Apply(ctx context.Context, r ServerApplyRequest) (*ServerApplyResponse, error) {
+// Delete revokes the requested api-token
+func (s *TokenService) Delete(ctx context.Context, r TokenDeleteRequest) (*TokenDeleteResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.Apply: marshal ServerApplyRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.Apply: generate signature ServerApplyRequest")
+		return nil, errors.Wrap(err, "TokenService.Delete: marshal TokenDeleteRequest")
 	}
-	url := s.client.RemoteHost + "ServerService.Apply"
+	url := s.client.RemoteHost + "TokenService.Delete"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.Apply: NewRequest")
+		return nil, errors.Wrap(err, "TokenService.Delete: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "TokenService.Delete: generate nonce")
+	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "TokenService.Delete: generate signature TokenDeleteRequest")
 	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.Apply")
+		return nil, errors.Wrap(err, "TokenService.Delete")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		ServerApplyResponse
+		TokenDeleteResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "ServerService.Apply: new gzip reader")
+			return nil, errors.Wrap(err, "TokenService.Delete: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.Apply: read response body")
+		return nil, errors.Wrap(err, "TokenService.Delete: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("ServerService.Apply: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("TokenService.Delete: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.ServerApplyResponse, nil
+	return &response.TokenDeleteResponse, nil
 }
 
-func (s *ServerService) List(ctx context.Context, r ServerListRequest) (*ServerListResponse, error) {
+// List returns the api-tokens
+func (s *TokenService) List(ctx context.Context, r TokenListRequest) (*TokenListResponse, error) {
 	requestBodyBytes, err := json.Marshal(r)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.List: marshal ServerListRequest")
-	}
-	signature, err := generateSignature(requestBodyBytes, s.client.secret)
-	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.List: generate signature ServerListRequest")
+		return nil, errors.Wrap(err, "TokenService.List: marshal TokenListRequest")
 	}
-	url := s.client.RemoteHost + "ServerService.List"
+	url := s.client.RemoteHost + "TokenService.List"
 	s.client.Debug(fmt.Sprintf("POST %s", url))
 	s.client.Debug(fmt.Sprintf(">> %s", string(requestBodyBytes)))
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.List: NewRequest")
+		return nil, errors.Wrap(err, "TokenService.List: NewRequest")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, errors.Wrap(err, "TokenService.List: generate nonce")
 	}
+	signature, err := generateSignature(req.Method, req.URL.RequestURI(), timestamp, nonce, requestBodyBytes, s.client.secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "TokenService.List: generate signature TokenListRequest")
+	}
+	req.Header.Set("X-API-KEY", s.client.key)
+	req.Header.Set("X-API-TIMESTAMP", timestamp)
+	req.Header.Set("X-API-NONCE", nonce)
 	req.Header.Set("X-API-SIGNATURE", signature)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
 	req = req.WithContext(ctx)
 	resp, err := s.client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.List")
+		return nil, errors.Wrap(err, "TokenService.List")
 	}
 	defer resp.Body.Close()
 	var response struct {
-		ServerListResponse
+		TokenListResponse
 		Error string
 	}
 	var bodyReader io.Reader = resp.Body
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		decodedBody, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, errors.Wrap(err, "ServerService.List: new gzip reader")
+			return nil, errors.Wrap(err, "TokenService.List: new gzip reader")
 		}
 		defer decodedBody.Close()
 		bodyReader = decodedBody
 	}
 	respBodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
-		return nil, errors.Wrap(err, "ServerService.List: read response body")
+		return nil, errors.Wrap(err, "TokenService.List: read response body")
 	}
 	if err := json.Unmarshal(respBodyBytes, &response); err != nil {
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.Errorf("ServerService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
+			return nil, errors.Errorf("TokenService.List: (%d) %v", resp.StatusCode, string(respBodyBytes))
 		}
 		return nil, err
 	}
 	if response.Error != "" {
 		return nil, errors.New(response.Error)
 	}
-	return &response.ServerListResponse, nil
+	return &response.TokenListResponse, nil
+}
+
+// AuditEntry is an administrative action recorded in the audit-log, the time for
+// the action is the time the entry was created
+type AuditEntry struct {
+	datastore.Base
+
+	// Actor is the name of the api-token for the caller
+	Actor string `json:"actor" yaml:"actor"`
+
+	// Key for the api-token of the caller
+	Key string `json:"key" yaml:"key"`
+
+	// Remote address of the caller
+	Remote string `json:"remote" yaml:"remote"`
+
+	// Method that was called (Service.Method)
+	Method string `json:"method" yaml:"method"`
+
+	// Request is the request-body with the secrets redacted
+	Request string `json:"request" yaml:"request"`
+
+	// Outcome of the request (ok or failed)
+	Outcome string `json:"outcome" yaml:"outcome"`
+
+	// Reason the request failed
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// AuditListRequest is the input-object for listing the audit-log
+type AuditListRequest struct {
+
+	// Since is the time to list the entries from, either a duration back in time (like
+	// 24h or 7d) or a date
+	Since string `json:"since" yaml:"since"`
+
+	// Actor to list the entries for
+	Actor string `json:"actor" yaml:"actor"`
+
+	// Limit is the max amount of entries to return (defaults to 100)
+	Limit int64 `json:"limit" yaml:"limit"`
+
+	// PageToken is the token for the page to return, from NextPageToken of the
+	// previous page
+	PageToken string `json:"pageToken" yaml:"pageToken"`
+}
+
+// AuditListResponse is the output-object for listing the audit-log
+type AuditListResponse struct {
+
+	// Entries with the latest first
+	Entries []AuditEntry `json:"entries" yaml:"entries"`
+
+	// NextPageToken is the token for the next page, empty if it is the last page
+	NextPageToken string `json:"nextPageToken" yaml:"nextPageToken"`
 }
 
 // Case holds the information for a case
@@ -1218,6 +3013,37 @@ type CaseSettings struct {
 	ReviewCompound *Case `json:"reviewCompound" yaml:"reviewCompound"`
 }
 
+// Delivery is a delivery of a notification
+type Delivery struct {
+	datastore.Base
+
+	// NotificationID and Notification (name) for the rule that was delivered
+	NotificationID uint `json:"notificationID" yaml:"notificationID"`
+
+	Notification string `json:"notification" yaml:"notification"`
+
+	// Runner and Trigger for the delivery
+	Runner string `json:"runner" yaml:"runner"`
+
+	Trigger string `json:"trigger" yaml:"trigger"`
+
+	// Status of the delivery (pending, delivered or failed)
+	Status string `json:"status" yaml:"status"`
+
+	// Attempts to deliver the notification
+	Attempts int64 `json:"attempts" yaml:"attempts"`
+
+	// LastError is the error from the last failed attempt
+	LastError string `json:"lastError" yaml:"lastError"`
+
+	// DeliveredAt is when the notification was delivered (unix-time)
+	DeliveredAt int64 `json:"deliveredAt" yaml:"deliveredAt"`
+
+	// Event that is notified (json), the pending deliveries are resumed with it on
+	// startup
+	Event string `json:"event" yaml:"event"`
+}
+
 // Evidence holds information about a specific evidence
 type Evidence struct {
 	datastore.Base
@@ -1231,6 +3057,22 @@ type Evidence struct {
 	// Directory of where the evidence is located
 	Directory string `json:"directory" yaml:"directory"`
 
+	// Paths holds additional paths for the evidence
+	Paths []*EvidencePath `json:"paths" yaml:"paths"`
+
+	// PathList is a text-file listing paths for the evidence (one path per line)
+	PathList string `json:"pathList" yaml:"pathList"`
+
+	// LoadFile is a Nuix load-file to ingest into the evidence-container
+	LoadFile string `json:"loadFile" yaml:"loadFile"`
+
+	// Filters holds glob-patterns to include or exclude files from the paths of the
+	// evidence
+	Filters []*EvidenceFilter `json:"filters" yaml:"filters"`
+
+	// Metadata holds custom metadata for the evidence
+	Metadata []*EvidenceMetadata `json:"metadata" yaml:"metadata"`
+
 	// Description of the evidence
 	Description string `json:"description" yaml:"description"`
 
@@ -1247,6 +3089,45 @@ type Evidence struct {
 	Locale string `json:"locale" yaml:"locale"`
 }
 
+// EvidenceFilter holds a glob-pattern for the files to add from an evidence
+type EvidenceFilter struct {
+	datastore.Base
+
+	// EvidenceID foreign-key for evidence-table
+	EvidenceID uint `json:"evidenceID" yaml:"evidenceID"`
+
+	// Pattern to match the files with, relative to the paths of the evidence
+	Pattern string `json:"pattern" yaml:"pattern"`
+
+	// Exclude - if the matched files should be excluded instead of included
+	Exclude bool `json:"exclude" yaml:"exclude"`
+}
+
+// EvidenceMetadata holds a custom metadata-field for an evidence
+type EvidenceMetadata struct {
+	datastore.Base
+
+	// EvidenceID foreign-key for evidence-table
+	EvidenceID uint `json:"evidenceID" yaml:"evidenceID"`
+
+	// Key for the metadata-field
+	Key string `json:"key" yaml:"key"`
+
+	// Value for the metadata-field
+	Value string `json:"value" yaml:"value"`
+}
+
+// EvidencePath holds a path for an evidence
+type EvidencePath struct {
+	datastore.Base
+
+	// EvidenceID foreign-key for evidence-table
+	EvidenceID uint `json:"evidenceID" yaml:"evidenceID"`
+
+	// Path for where the evidence is located at
+	Path string `json:"path" yaml:"path"`
+}
+
 // Exclude excludes items in a Nuix-case based on a search
 type Exclude struct {
 	datastore.Base
@@ -1310,6 +3191,8 @@ type Licences struct {
 type LogItemRequest struct {
 	Runner string `json:"runner" yaml:"runner"`
 
+	RunID string `json:"runID" yaml:"runID"`
+
 	Stage string `json:"stage" yaml:"stage"`
 
 	StageID int `json:"stageID" yaml:"stageID"`
@@ -1325,9 +3208,24 @@ type LogItemRequest struct {
 	ProcessStage string `json:"processStage" yaml:"processStage"`
 }
 
+// LogItemsRequest is the input-object for logging a batch of items
+type LogItemsRequest struct {
+
+	// Runner the items are logged for
+	Runner string `json:"runner" yaml:"runner"`
+
+	// RunID for the run of the runner-script
+	RunID string `json:"runID" yaml:"runID"`
+
+	// Items to log
+	Items []LogItemRequest `json:"items" yaml:"items"`
+}
+
 type LogRequest struct {
 	Runner string `json:"runner" yaml:"runner"`
 
+	RunID string `json:"runID" yaml:"runID"`
+
 	Stage string `json:"stage" yaml:"stage"`
 
 	StageID int `json:"stageID" yaml:"stageID"`
@@ -1337,7 +3235,87 @@ type LogRequest struct {
 	Exception string `json:"exception" yaml:"exception"`
 }
 
-type LogResponse struct {
+type LogResponse struct {
+}
+
+// Manifest is a chain-of-custody manifest for the evidence of a runner
+type Manifest struct {
+	datastore.Base
+
+	// RunnerID foreign-key for runner-table
+	RunnerID uint `json:"runnerID" yaml:"runnerID"`
+
+	// Phase for when the manifest was created (apply, run or finish)
+	Phase string `json:"phase" yaml:"phase"`
+
+	// FileCount is the amount of files in the manifest
+	FileCount int64 `json:"fileCount" yaml:"fileCount"`
+
+	// TotalSize is the total size in bytes for the files in the manifest
+	TotalSize int64 `json:"totalSize" yaml:"totalSize"`
+
+	// Verified - if the manifest matches the previous manifest for the runner
+	Verified bool `json:"verified" yaml:"verified"`
+
+	// Evidence holds the evidence-paths in the manifest
+	Evidence []*ManifestEvidence `json:"evidence" yaml:"evidence"`
+
+	// Changes holds the changes from the previous manifest for the runner
+	Changes []*ManifestChange `json:"changes" yaml:"changes"`
+}
+
+// ManifestChange holds a change for a file compared to the previous manifest
+type ManifestChange struct {
+	datastore.Base
+
+	// ManifestID foreign-key for manifest-table
+	ManifestID uint `json:"manifestID" yaml:"manifestID"`
+
+	// Path for the changed file
+	Path string `json:"path" yaml:"path"`
+
+	// Change for the file (added, removed or modified)
+	Change string `json:"change" yaml:"change"`
+}
+
+// ManifestEvidence holds the files for an evidence-path in a manifest
+type ManifestEvidence struct {
+	datastore.Base
+
+	// ManifestID foreign-key for manifest-table
+	ManifestID uint `json:"manifestID" yaml:"manifestID"`
+
+	// Name of the evidence
+	Name string `json:"name" yaml:"name"`
+
+	// Path for the evidence
+	Path string `json:"path" yaml:"path"`
+
+	// FileCount is the amount of files for the evidence-path
+	FileCount int64 `json:"fileCount" yaml:"fileCount"`
+
+	// TotalSize is the total size in bytes for the files in the evidence-path
+	TotalSize int64 `json:"totalSize" yaml:"totalSize"`
+
+	// Files in the evidence-path
+	Files []*ManifestFile `json:"files" yaml:"files"`
+}
+
+// ManifestFile holds the information for a file in an evidence-path
+type ManifestFile struct {
+	datastore.Base
+
+	// ManifestEvidenceID foreign-key for manifestevidence-table
+	ManifestEvidenceID uint `json:"manifestEvidenceID" yaml:"manifestEvidenceID"`
+
+	// Path for the file
+	Path string `json:"path" yaml:"path"`
+
+	// Size of the file in bytes
+	Size int64 `json:"size" yaml:"size"`
+
+	// Hash is the SHA256-hash for the file
+	Hash string `json:"hash" yaml:"hash"`
 }
 
 // Nms is the main struct for the Nuix Management Servers
@@ -1353,7 +3331,8 @@ type Nms struct {
 	// Username for the nms-server
 	Username string `json:"username" yaml:"username"`
 
-	// Password for the nms-server
+	// Password for the nms-server, it is write-only and masked in the responses - or a
+	// reference (env:<name>, file:<path> or secret:<name>)
 	Password string `json:"password" yaml:"password"`
 
 	// amount of workers licensed to the server
@@ -1378,7 +3357,8 @@ type NmsApplyRequest struct {
 	// Username for the nms-server
 	Username string `json:"username" yaml:"username"`
 
-	// Password for the nms-server
+	// Password for the nms-server - or a reference (env:<name>, file:<path> or
+	// secret:<name>)
 	Password string `json:"password" yaml:"password"`
 
 	// amount of workers licensed to the server
@@ -1397,12 +3377,38 @@ type NmsApplyResponse struct {
 	Nms []Nms `json:"nms" yaml:"nms"`
 }
 
+// NmsDeleteRequest is the input-object for Delete in the NMS-service
+type NmsDeleteRequest struct {
+
+	// Address of the nms-server
+	Address string `json:"address" yaml:"address"`
+}
+
+// NmsDeleteResponse is the output-object for Delete in the NMS-service
+type NmsDeleteResponse struct {
+}
+
+// NmsGetRequest is the input-object for Get in the NMS-service
+type NmsGetRequest struct {
+
+	// Address of the nms-server
+	Address string `json:"address" yaml:"address"`
+}
+
+// NmsGetResponse is the output-object for Get in the NMS-service
+type NmsGetResponse struct {
+	Nms Nms `json:"nms" yaml:"nms"`
+}
+
 // NmsListLicencesRequest is the input-object for listing licences for a specific
 // NMS
 type NmsListLicencesRequest struct {
 
 	// ID for the nms-server to list the licences for
 	NmsID uint `json:"nmsID" yaml:"nmsID"`
+
+	// Address for the nms-server to list the licences for (if NmsID isn't set)
+	Address string `json:"address" yaml:"address"`
 }
 
 // NmsListLicencesResponse is the output-object for listing licences for a specific
@@ -1413,11 +3419,174 @@ type NmsListLicencesResponse struct {
 
 // NmsListRequest is the input-object for List in the NMS-service
 type NmsListRequest struct {
+
+	// Address to filter the nms-servers with, a glob-pattern (for example *.avian.dk)
+	Address string `json:"address" yaml:"address"`
+
+	// Sort is the field to sort by (id, address or created), prefixed with - for
+	// descending order
+	Sort string `json:"sort" yaml:"sort"`
+
+	// Limit is the max amount of nms-servers to return (defaults to 100)
+	Limit int64 `json:"limit" yaml:"limit"`
+
+	// PageToken is the token for the page to return, from NextPageToken of the
+	// previous page
+	PageToken string `json:"pageToken" yaml:"pageToken"`
 }
 
 // NmsListResponse is the output-object for List in the NMS-service
 type NmsListResponse struct {
 	Nms []Nms `json:"nms" yaml:"nms"`
+
+	// NextPageToken is the token for the next page, empty if it is the last page
+	NextPageToken string `json:"nextPageToken" yaml:"nextPageToken"`
+}
+
+// NmsVerifyPasswordRequest is the input-object for VerifyPassword in the
+// NMS-service
+type NmsVerifyPasswordRequest struct {
+
+	// Address of the nms-server
+	Address string `json:"address" yaml:"address"`
+
+	// Password to verify
+	Password string `json:"password" yaml:"password"`
+}
+
+// NmsVerifyPasswordResponse is the output-object for VerifyPassword in the
+// NMS-service
+type NmsVerifyPasswordResponse struct {
+
+	// Match - if the password matches the stored password
+	Match bool `json:"match" yaml:"match"`
+}
+
+// Notification is a rule for notifying when something happens with a runner
+type Notification struct {
+	datastore.Base
+
+	// Name of the notification-rule
+	Name string `json:"name" yaml:"name"`
+
+	// Runner the rule is for, empty if it is for all runners
+	Runner string `json:"runner" yaml:"runner"`
+
+	// Triggers for the notification separated by comma (finish, failure, timeout and
+	// stage-failure)
+	Triggers string `json:"triggers" yaml:"triggers"`
+
+	// Type of the notification (webhook or email)
+	Type string `json:"type" yaml:"type"`
+
+	// Endpoint is the url to post the webhook to
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+
+	// Body is the template for the json-body of the webhook, a default body is posted
+	// if it is empty
+	Body string `json:"body" yaml:"body"`
+
+	// Recipients are the email-addresses to notify, separated by comma
+	Recipients string `json:"recipients" yaml:"recipients"`
+
+	// Subject is the template for the subject of the email, a default subject is used
+	// if it is empty
+	Subject string `json:"subject" yaml:"subject"`
+}
+
+// NotificationApplyRequest is the input-object for applying a notification-rule
+type NotificationApplyRequest struct {
+
+	// Name of the notification-rule
+	Name string `json:"name" yaml:"name"`
+
+	// Runner the rule is for, empty if it is for all runners
+	Runner string `json:"runner" yaml:"runner"`
+
+	// Triggers for the notification (finish, failure, timeout and stage-failure)
+	Triggers []string `json:"triggers" yaml:"triggers"`
+
+	// Type of the notification (webhook or email)
+	Type string `json:"type" yaml:"type"`
+
+	// Endpoint is the url to post the webhook to
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+
+	// Body is the template for the json-body of the webhook
+	Body string `json:"body" yaml:"body"`
+
+	// Recipients are the email-addresses to notify
+	Recipients []string `json:"recipients" yaml:"recipients"`
+
+	// Subject is the template for the subject of the email
+	Subject string `json:"subject" yaml:"subject"`
+}
+
+// NotificationApplyRequests is the input-object for Apply in the
+// notification-service
+type NotificationApplyRequests struct {
+	Notifications []NotificationApplyRequest `json:"notifications" yaml:"notifications"`
+}
+
+// NotificationApplyResponse is the output-object for Apply in the
+// notification-service
+type NotificationApplyResponse struct {
+	Notifications []Notification `json:"notifications" yaml:"notifications"`
+}
+
+// NotificationDeleteRequest is the input-object for Delete in the
+// notification-service
+type NotificationDeleteRequest struct {
+
+	// Name of the notification-rule
+	Name string `json:"name" yaml:"name"`
+}
+
+// NotificationDeleteResponse is the output-object for Delete in the
+// notification-service
+type NotificationDeleteResponse struct {
+}
+
+// NotificationDeliveriesRequest is the input-object for Deliveries in the
+// notification-service
+type NotificationDeliveriesRequest struct {
+
+	// Notification (name) to filter the deliveries with
+	Notification string `json:"notification" yaml:"notification"`
+
+	// Runner to filter the deliveries with
+	Runner string `json:"runner" yaml:"runner"`
+
+	// Status to filter the deliveries with (pending, delivered or failed)
+	Status string `json:"status" yaml:"status"`
+
+	// Limit is the max amount of deliveries to return (defaults to 100)
+	Limit int64 `json:"limit" yaml:"limit"`
+
+	// PageToken is the token for the page to return, from NextPageToken of the
+	// previous page
+	PageToken string `json:"pageToken" yaml:"pageToken"`
+}
+
+// NotificationDeliveriesResponse is the output-object for Deliveries in the
+// notification-service
+type NotificationDeliveriesResponse struct {
+
+	// Deliveries with the latest first
+	Deliveries []Delivery `json:"deliveries" yaml:"deliveries"`
+
+	// NextPageToken is the token for the next page, empty if it is the last page
+	NextPageToken string `json:"nextPageToken" yaml:"nextPageToken"`
+}
+
+// NotificationListRequest is the input-object for List in the notification-service
+type NotificationListRequest struct {
+}
+
+// NotificationListResponse is the output-object for List in the
+// notification-service
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications" yaml:"notifications"`
 }
 
 // NuixSwitch is a command argument for nuix-console
@@ -1480,6 +3649,9 @@ type Process struct {
 	// EvidenceStore to process to the nuix-case
 	EvidenceStore []*Evidence `json:"evidenceStore" yaml:"evidenceStore"`
 
+	// Manifest - if a chain-of-custody manifest should be created for the evidence
+	Manifest bool `json:"manifest" yaml:"manifest"`
+
 	// Status for the stage
 	Status int64 `json:"status" yaml:"status"`
 }
@@ -1534,6 +3706,10 @@ type Runner struct {
 	// HealthyAt - last time the runner was healthy
 	HealthyAt *time.Time `json:"healthyAt" yaml:"healthyAt"`
 
+	// RunID is the id for the current run of the runner, it is set every time the
+	// runner is started by the queue
+	RunID string `json:"runID" yaml:"runID"`
+
 	// CaseSettings for the cases to use
 	CaseSettingsID uint `json:"caseSettingsID" yaml:"caseSettingsID"`
 
@@ -1544,6 +3720,10 @@ type Runner struct {
 
 	// Switches to use for nuix-console
 	Switches []*NuixSwitch `json:"switches" yaml:"switches"`
+
+	// Engine for the script of the runner (ruby or python), the engine for the server
+	// is used if empty
+	Engine string `json:"engine" yaml:"engine"`
 }
 
 // RunnerApplyRequest is the input-object for applying a runner-configuration to
@@ -1578,6 +3758,9 @@ type RunnerApplyRequest struct {
 	// Switches to use for nuix-console
 	Switches []string `json:"switches" yaml:"switches"`
 
+	// Engine for the script of the runner (ruby or python)
+	Engine string `json:"engine" yaml:"engine"`
+
 	// Update - if the runner should be updated
 	Update bool `json:"update" yaml:"update"`
 }
@@ -1602,6 +3785,10 @@ type RunnerDeleteRequest struct {
 
 	// Force - if the delete should be forced
 	Force bool `json:"force" yaml:"force"`
+
+	// Purge - if the runner should be permanently deleted instead of moved to the
+	// trash
+	Purge bool `json:"purge" yaml:"purge"`
 }
 
 // RunnerDeleteResponse is the output-object for deleting a runner by name
@@ -1614,6 +3801,8 @@ type RunnerFailedRequest struct {
 
 	Runner string `json:"runner" yaml:"runner"`
 
+	RunID string `json:"runID" yaml:"runID"`
+
 	Exception string `json:"exception" yaml:"exception"`
 }
 
@@ -1626,6 +3815,9 @@ type RunnerFinishRequest struct {
 	ID uint `json:"id" yaml:"id"`
 
 	Runner string `json:"runner" yaml:"runner"`
+
+	// RunID for the run of the runner-script
+	RunID string `json:"runID" yaml:"runID"`
 }
 
 // RunnerFinishResponse is the output-object for finishing a runner by id
@@ -1644,16 +3836,119 @@ type RunnerGetResponse struct {
 
 // RunnerListRequest is the input-object for listing the runners from the backend
 type RunnerListRequest struct {
+
+	// Status to filter the runners with (waiting, running, failed, finished or
+	// timeout)
+	Status string `json:"status" yaml:"status"`
+
+	// Hostname of the server to filter the runners with
+	Hostname string `json:"hostname" yaml:"hostname"`
+
+	// Nms to filter the runners with
+	Nms string `json:"nms" yaml:"nms"`
+
+	// Licence to filter the runners with
+	Licence string `json:"licence" yaml:"licence"`
+
+	// Name to filter the runners with, a glob-pattern (for example case-*)
+	Name string `json:"name" yaml:"name"`
+
+	// CreatedAfter and CreatedBefore filters the runners by when they were created
+	// (unix-time)
+	CreatedAfter int64 `json:"createdAfter" yaml:"createdAfter"`
+
+	CreatedBefore int64 `json:"createdBefore" yaml:"createdBefore"`
+
+	// Sort is the field to sort by (id, name, created, status or hostname), prefixed
+	// with - for descending order
+	Sort string `json:"sort" yaml:"sort"`
+
+	// Limit is the max amount of runners to return (defaults to 100)
+	Limit int64 `json:"limit" yaml:"limit"`
+
+	// PageToken is the token for the page to return, from NextPageToken of the
+	// previous page
+	PageToken string `json:"pageToken" yaml:"pageToken"`
 }
 
 // RunnerListResponse is the input-object for listing the runners from the backend
 type RunnerListResponse struct {
 	Runners []Runner `json:"runners" yaml:"runners"`
+
+	// NextPageToken is the token for the next page, empty if it is the last page
+	NextPageToken string `json:"nextPageToken" yaml:"nextPageToken"`
+}
+
+// RunnerListTrashRequest is the input-object for listing the runners in the trash
+type RunnerListTrashRequest struct {
+
+	// Limit is the max amount of runners to return (defaults to 100)
+	Limit int64 `json:"limit" yaml:"limit"`
+
+	// PageToken is the token for the page to return, from NextPageToken of the
+	// previous page
+	PageToken string `json:"pageToken" yaml:"pageToken"`
+}
+
+// RunnerListTrashResponse is the output-object for listing the runners in the
+// trash
+type RunnerListTrashResponse struct {
+	Runners []Runner `json:"runners" yaml:"runners"`
+
+	// NextPageToken is the token for the next page, empty if it is the last page
+	NextPageToken string `json:"nextPageToken" yaml:"nextPageToken"`
+}
+
+// RunnerManifestRequest is the input-object for requesting the manifests for a
+// runner
+type RunnerManifestRequest struct {
+
+	// Name of the runner
+	Name string `json:"name" yaml:"name"`
+}
+
+// RunnerManifestResponse is the output-object for requesting the manifests for a
+// runner
+type RunnerManifestResponse struct {
+	Manifests []Manifest `json:"manifests" yaml:"manifests"`
+}
+
+// RunnerPurgeRequest is the input-object for permanently deleting the runners in
+// the trash
+type RunnerPurgeRequest struct {
+
+	// OlderThan is the duration (for example 720h or 30d) the runners must have been
+	// in the trash to be purged
+	OlderThan string `json:"olderThan" yaml:"olderThan"`
+}
+
+// RunnerPurgeResponse is the output-object for permanently deleting the runners in
+// the trash
+type RunnerPurgeResponse struct {
+
+	// Runners are the names of the purged runners
+	Runners []string `json:"runners" yaml:"runners"`
+}
+
+// RunnerRestoreRequest is the input-object for restoring a runner from the trash
+// by name
+type RunnerRestoreRequest struct {
+
+	// Name of the runner
+	Name string `json:"name" yaml:"name"`
+}
+
+// RunnerRestoreResponse is the output-object for restoring a runner from the trash
+// by name
+type RunnerRestoreResponse struct {
+	Runner Runner `json:"runner" yaml:"runner"`
 }
 
 type StageRequest struct {
 	Runner string `json:"runner" yaml:"runner"`
 
+	RunID string `json:"runID" yaml:"runID"`
+
 	StageID uint `json:"stageID" yaml:"stageID"`
 }
 
@@ -1684,17 +3979,52 @@ type Stage struct {
 
 	// Reload reloads items in a Nuix-case based on a search
 	Reload *Reload `json:"reload" yaml:"reload"`
+
+	// Total is the estimated amount of work for the stage (items, search-hits or
+	// batches)
+	Total int64 `json:"total" yaml:"total"`
+
+	// Progress is the amount of work done for the stage
+	Progress int64 `json:"progress" yaml:"progress"`
+
+	// StartedAt is the time (unix) for when the stage was started
+	StartedAt int64 `json:"startedAt" yaml:"startedAt"`
+
+	// EstimatedFinish is the estimated time (unix) for when the stage will finish,
+	// based on the throughput of the stage
+	EstimatedFinish int64 `json:"estimatedFinish" yaml:"estimatedFinish"`
 }
 
 type StageResponse struct {
 	Stage Stage `json:"stage" yaml:"stage"`
 }
 
+// RunnerScriptRequest is the input-object for generating the script for a runner
+type RunnerScriptRequest struct {
+
+	// Name of the stored runner
+	Name string `json:"name" yaml:"name"`
+
+	// Runner to generate the script for instead of a stored runner (from a local
+	// yml-file)
+	Runner *RunnerApplyRequest `json:"runner" yaml:"runner"`
+}
+
+// RunnerScriptResponse is the output-object for generating the script for a runner
+type RunnerScriptResponse struct {
+
+	// Script that would be run for the runner
+	Script string `json:"script" yaml:"script"`
+}
+
 // RunnerStartRequest is the input-object for starting a runner by id
 type RunnerStartRequest struct {
 	ID uint `json:"id" yaml:"id"`
 
 	Runner string `json:"runner" yaml:"runner"`
+
+	// RunID for the run of the runner-script
+	RunID string `json:"runID" yaml:"runID"`
 }
 
 // RunnerStartResponse is the output-object for starting a runner by id
@@ -1721,6 +4051,54 @@ type SearchAndTag struct {
 	Status int64 `json:"status" yaml:"status"`
 }
 
+// Secret is a named secret, the value is encrypted with the master-key
+type Secret struct {
+	datastore.Base
+
+	// Name of the secret
+	Name string `json:"name" yaml:"name"`
+
+	// Value of the secret, it is never returned by the service
+	Value string `json:"value" yaml:"value"`
+}
+
+// SecretDeleteRequest is the input-object for deleting a secret
+type SecretDeleteRequest struct {
+
+	// Name of the secret
+	Name string `json:"name" yaml:"name"`
+}
+
+// SecretDeleteResponse is the output-object for deleting a secret
+type SecretDeleteResponse struct {
+}
+
+// SecretListRequest is the input-object for listing the secrets
+type SecretListRequest struct {
+}
+
+// SecretListResponse is the output-object for listing the secrets
+type SecretListResponse struct {
+	Secrets []Secret `json:"secrets" yaml:"secrets"`
+}
+
+// SecretSetRequest is the input-object for setting a secret
+type SecretSetRequest struct {
+
+	// Name of the secret
+	Name string `json:"name" yaml:"name"`
+
+	// Value of the secret
+	Value string `json:"value" yaml:"value"`
+}
+
+// SecretSetResponse is the output-object for setting a secret
+type SecretSetResponse struct {
+
+	// Secret that has been set
+	Secret Secret `json:"secret" yaml:"secret"`
+}
+
 // Server is the main-struct for the servers
 type Server struct {
 	datastore.Base
@@ -1737,12 +4115,16 @@ type Server struct {
 	// Username for connection to the server
 	Username string `json:"username" yaml:"username"`
 
-	// Password for connection to the server
+	// Password for connection to the server, it is write-only and masked in the
+	// responses - or a reference (env:<name>, file:<path> or secret:<name>)
 	Password string `json:"password" yaml:"password"`
 
 	// NuixPath to know where to run Nuix
 	NuixPath string `json:"nuixPath" yaml:"nuixPath"`
 
+	// Engine for the scripts of the runners on the server (ruby or python)
+	Engine string `json:"engine" yaml:"engine"`
+
 	// Active - if the server has an active job
 	Active bool `json:"active" yaml:"active"`
 }
@@ -1760,6 +4142,8 @@ type ServerApplyRequest struct {
 	Password string `json:"password" yaml:"password"`
 
 	NuixPath string `json:"nuixPath" yaml:"nuixPath"`
+
+	Engine string `json:"engine" yaml:"engine"`
 }
 
 // ServerApplyResponse is the output-object for Apply in the server-service
@@ -1767,13 +4151,160 @@ type ServerApplyResponse struct {
 	Server Server `json:"server" yaml:"server"`
 }
 
+// ServerDeleteRequest is the input-object for Delete in the server-service
+type ServerDeleteRequest struct {
+
+	// Hostname of the server
+	Hostname string `json:"hostname" yaml:"hostname"`
+}
+
+// ServerDeleteResponse is the output-object for Delete in the server-service
+type ServerDeleteResponse struct {
+}
+
+// ServerGetRequest is the input-object for Get in the server-service
+type ServerGetRequest struct {
+
+	// Hostname of the server
+	Hostname string `json:"hostname" yaml:"hostname"`
+}
+
+// ServerGetResponse is the output-object for Get in the server-service
+type ServerGetResponse struct {
+	Server Server `json:"server" yaml:"server"`
+}
+
 // ServerListRequest is the input-object for List in the server-service
 type ServerListRequest struct {
+
+	// Hostname to filter the servers with, a glob-pattern (for example dev*)
+	Hostname string `json:"hostname" yaml:"hostname"`
+
+	// Sort is the field to sort by (id, hostname or created), prefixed with - for
+	// descending order
+	Sort string `json:"sort" yaml:"sort"`
+
+	// Limit is the max amount of servers to return (defaults to 100)
+	Limit int64 `json:"limit" yaml:"limit"`
+
+	// PageToken is the token for the page to return, from NextPageToken of the
+	// previous page
+	PageToken string `json:"pageToken" yaml:"pageToken"`
 }
 
 // ServerListResponse is the output-object for List in the server-service
 type ServerListResponse struct {
 	Servers []Server `json:"servers" yaml:"servers"`
+
+	// NextPageToken is the token for the next page, empty if it is the last page
+	NextPageToken string `json:"nextPageToken" yaml:"nextPageToken"`
+}
+
+// ServerVerifyPasswordRequest is the input-object for VerifyPassword in the
+// server-service
+type ServerVerifyPasswordRequest struct {
+
+	// Hostname of the server
+	Hostname string `json:"hostname" yaml:"hostname"`
+
+	// Password to verify
+	Password string `json:"password" yaml:"password"`
+}
+
+// ServerVerifyPasswordResponse is the output-object for VerifyPassword in the
+// server-service
+type ServerVerifyPasswordResponse struct {
+
+	// Match - if the password matches the stored password
+	Match bool `json:"match" yaml:"match"`
+}
+
+// StageProgressRequest is the input-object for setting the progress for a stage
+type StageProgressRequest struct {
+	Runner string `json:"runner" yaml:"runner"`
+
+	RunID string `json:"runID" yaml:"runID"`
+
+	StageID uint `json:"stageID" yaml:"stageID"`
+
+	// Total is the estimated amount of items, search-hits or batches for the stage
+	Total int64 `json:"total" yaml:"total"`
+
+	// Count is the amount that is done for the stage
+	Count int64 `json:"count" yaml:"count"`
+}
+
+// Token is an api-token for the clients of the service
+type Token struct {
+	datastore.Base
+
+	// Name describes what the token is used for
+	Name string `json:"name" yaml:"name"`
+
+	// Key identifies the token in the requests
+	Key string `json:"key" yaml:"key"`
+
+	// Secret to verify the signatures of the requests with
+	Secret string `json:"secret" yaml:"secret"`
+
+	// RunnerID is set for the tokens of the runner-scripts, these tokens are only
+	// valid for the callbacks of the runner
+	RunnerID uint `json:"runnerID" yaml:"runnerID"`
+
+	// Runner the token is valid for
+	Runner string `json:"runner" yaml:"runner"`
+
+	// ExpiresAt is when the token expires (unix-time), zero if the token never expires
+	ExpiresAt int64 `json:"expiresAt" yaml:"expiresAt"`
+
+	// Admin is set for the tokens that can verify the stored passwords and create
+	// other admin-tokens
+	Admin bool `json:"admin" yaml:"admin"`
+}
+
+// TokenCreateRequest is the input-object for creating an api-token
+type TokenCreateRequest struct {
+
+	// Name describes what the token is used for
+	Name string `json:"name" yaml:"name"`
+
+	// Expires is the duration (for example 720h) the token is valid for, empty if it
+	// never expires
+	Expires string `json:"expires" yaml:"expires"`
+
+	// Admin creates an admin-token, the token for the request must be an admin-token
+	Admin bool `json:"admin" yaml:"admin"`
+}
+
+// TokenCreateResponse is the output-object for creating an api-token
+type TokenCreateResponse struct {
+
+	// Token that has been created
+	Token Token `json:"token" yaml:"token"`
+
+	// Value of the token (key.secret) for the clients, it is only returned when the
+	// token is created
+	Value string `json:"value" yaml:"value"`
+}
+
+// TokenDeleteRequest is the input-object for revoking an api-token
+type TokenDeleteRequest struct {
+
+	// Key for the token to revoke
+	Key string `json:"key" yaml:"key"`
+}
+
+// TokenDeleteResponse is the output-object for revoking an api-token
+type TokenDeleteResponse struct {
+}
+
+// TokenListRequest is the input-object for listing the api-tokens
+type TokenListRequest struct {
+}
+
+// TokenListResponse is the output-object for listing the api-tokens
+type TokenListResponse struct {
+	Tokens []Token `json:"tokens" yaml:"tokens"`
 }
 
 // Type holds information for a type
@@ -1790,7 +4321,10 @@ type Type struct {
 	Status int64 `json:"status" yaml:"status"`
 }
 
-func generateSignature(message, secret []byte) (string, error) {
+// generateSignature signs the method, request-uri,
+// timestamp, nonce and body (separated by newlines)
+func generateSignature(method, uri, timestamp, nonce string, body, secret []byte) (string, error) {
+	message := append([]byte(strings.Join([]string{method, uri, timestamp, nonce}, "\n")+"\n"), body...)
 	mac := hmac.New(sha256.New, secret)
 	if _, err := mac.Write(message); err != nil {
 		return "", err
@@ -1798,3 +4332,13 @@ func generateSignature(message, secret []byte) (string, error) {
 	sig := base64.StdEncoding.EncodeToString(mac.Sum(nil))
 	return sig, nil
 }
+
+// generateNonce returns a random nonce for a request,
+// the service rejects the requests with a nonce it has seen
+func generateNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,102 @@
+package avian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/gorilla/websocket"
+)
+
+// Watch streams the events for the runner and the matter (empty values
+// matches all events) from the service, fn is called for every event
+// until the context is done or the connection is closed
+func (c *Client) Watch(ctx context.Context, runner, matter string, fn func(events.Event)) error {
+	u, err := url.Parse(strings.TrimSuffix(c.RemoteHost, "oto/") + "events")
+	if err != nil {
+		return fmt.Errorf("invalid remote-host: %v", err)
+	}
+	u.Scheme = strings.Replace(u.Scheme, "http", "ws", 1)
+	query := url.Values{}
+	if runner != "" {
+		query.Set("runner", runner)
+	}
+	if matter != "" {
+		query.Set("matter", matter)
+	}
+	u.RawQuery = query.Encode()
+
+	// the request-uri is signed for the event-stream (without a body)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return fmt.Errorf("generate nonce: %v", err)
+	}
+	signature, err := generateSignature(http.MethodGet, u.RequestURI(), timestamp, nonce, nil, c.secret)
+	if err != nil {
+		return fmt.Errorf("generate signature: %v", err)
+	}
+	header := http.Header{}
+	header.Set("X-API-KEY", c.key)
+	header.Set("X-API-TIMESTAMP", timestamp)
+	header.Set("X-API-NONCE", nonce)
+	header.Set("X-API-SIGNATURE", signature)
+
+	dialer := *websocket.DefaultDialer
+	if transport, ok := c.HTTPClient.Transport.(*http.Transport); ok {
+		dialer.TLSClientConfig = transport.TLSClientConfig
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("(%d) %s", resp.StatusCode, responseError(resp))
+		}
+		return err
+	}
+	defer conn.Close()
+
+	// close the connection when the context is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		var e events.Event
+		if err := conn.ReadJSON(&e); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		fn(e)
+	}
+}
+
+// responseError returns the error from the response of a failed handshake
+func responseError(resp *http.Response) string {
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.Status
+	}
+
+	var response struct{ Error string }
+	if err := json.Unmarshal(b, &response); err != nil || response.Error == "" {
+		return strings.TrimSpace(string(b))
+	}
+	return response.Error
+}
@@ -0,0 +1,56 @@
+package avian
+
+import (
+	"fmt"
+	"time"
+)
+
+// EstimateFinish returns the estimated time (unix) for when a stage
+// will finish, based on the throughput since the stage was started
+func EstimateFinish(startedAt, now, total, count int64) int64 {
+	if startedAt == 0 || now <= startedAt || total <= 0 || count <= 0 {
+		return 0
+	}
+
+	if count >= total {
+		return now
+	}
+
+	elapsed := float64(now - startedAt)
+	remaining := float64(total-count) * elapsed / float64(count)
+	return now + int64(remaining)
+}
+
+// Percentage returns the progress for the stage as a percentage,
+// a running stage is at most at 99% since the total is an estimate
+func (s *Stage) Percentage() string {
+	switch s.Status() {
+	case "Finished":
+		return "100%"
+	case "Waiting":
+		return "0%"
+	}
+
+	if s.Total <= 0 {
+		return "-"
+	}
+
+	percentage := s.Progress * 100 / s.Total
+	if percentage > 99 {
+		percentage = 99
+	}
+	return fmt.Sprintf("%d%%", percentage)
+}
+
+// ETA returns the estimated time left for a running stage
+func (s *Stage) ETA() string {
+	if s.Status() != "Running" || s.EstimatedFinish == 0 {
+		return "-"
+	}
+
+	left := time.Until(time.Unix(s.EstimatedFinish, 0)).Round(time.Second)
+	if left < 0 {
+		left = 0
+	}
+	return left.String()
+}
@@ -0,0 +1,24 @@
+package avian_test
+
+import (
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/matryer/is"
+)
+
+func TestEstimateFinish(t *testing.T) {
+	is := is.New(t)
+
+	// 100 of 400 items in 60 seconds leaves 180 seconds
+	is.Equal(avian.EstimateFinish(1000, 1060, 400, 100), int64(1240))
+
+	// the stage is done (or the estimate was too low)
+	is.Equal(avian.EstimateFinish(1000, 1060, 400, 400), int64(1060))
+	is.Equal(avian.EstimateFinish(1000, 1060, 400, 500), int64(1060))
+
+	// nothing to estimate from
+	is.Equal(avian.EstimateFinish(0, 1060, 400, 100), int64(0))
+	is.Equal(avian.EstimateFinish(1000, 1060, 0, 100), int64(0))
+	is.Equal(avian.EstimateFinish(1000, 1060, 400, 0), int64(0))
+}
@@ -0,0 +1,61 @@
+package certs_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	"github.com/avian-digital-forensics/auto-processing/pkg/certs"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func TestTLS(t *testing.T) {
+	is := is.New(t)
+
+	dir, err := ioutil.TempDir("", "certs")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+
+	serverCert, serverKey := filepath.Join(dir, "avian.crt"), filepath.Join(dir, "avian.key")
+	clientCert, clientKey := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	is.True(!certs.Exists(serverCert))
+	is.NoErr(certs.Generate(serverCert, serverKey, []string{"127.0.0.1", "localhost"}))
+	is.NoErr(certs.Generate(clientCert, clientKey, []string{"cli"}))
+	is.True(certs.Exists(serverCert))
+
+	// the client-certificate is its own CA
+	serverConfig, err := certs.ServerConfig(serverCert, serverKey, clientCert)
+	is.NoErr(err)
+
+	handler := auth.RequireClientCert(zap.NewNop(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv := httptest.NewUnstartedServer(handler)
+	srv.TLS = serverConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	post := func(ca, cert, key, method string) int {
+		config, err := certs.ClientConfig(ca, cert, key)
+		is.NoErr(err)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+		resp, err := client.Post(srv.URL+"/oto/"+method, "application/json", strings.NewReader("{}"))
+		if err != nil {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// the service is verified with the pinned CA
+	is.Equal(post(serverCert, clientCert, clientKey, "RunnerService.List"), http.StatusOK)
+	is.Equal(post(clientCert, clientCert, clientKey, "RunnerService.List"), 0) // unknown CA
+
+	// the client-certificate is required, except for the runner-callbacks
+	is.Equal(post(serverCert, "", "", "RunnerService.List"), http.StatusUnauthorized)
+	is.Equal(post(serverCert, "", "", "RunnerService.Heartbeat"), http.StatusOK)
+}
@@ -0,0 +1,297 @@
+// Package backup backs up and restores the db, either as a snapshot
+// of the sqlite-file or as a dump of the db-tables (for all the drivers),
+// and exports the processing-history as portable json
+package backup
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// Format is the version for the format of the dumps
+	Format = 1
+
+	// sqliteHeader is the header for the sqlite-files
+	sqliteHeader = "SQLite format 3\x00"
+)
+
+// Dump is a backup of the rows in all the db-tables
+type Dump struct {
+	// Format of the dump
+	Format int `json:"format"`
+
+	// SchemaVersion of the db that was dumped
+	SchemaVersion int `json:"schemaVersion"`
+
+	// Driver for the db that was dumped
+	Driver string `json:"driver"`
+
+	// Created is when the dump was created (unix-time)
+	Created int64 `json:"created"`
+
+	// Tables with their rows
+	Tables []Table `json:"tables"`
+}
+
+// Table holds the rows for a db-table
+type Table struct {
+	Name string          `json:"name"`
+	Rows json.RawMessage `json:"rows"`
+}
+
+// Snapshot writes an online, consistent snapshot of the sqlite-db
+// to the path, the path must not exist and the snapshot is only
+// readable by the owner (it contains the credentials and tokens)
+func Snapshot(db *gorm.DB, path string) error {
+	if db.Dialect().GetName() != datastore.DriverSQLite {
+		return fmt.Errorf("snapshots are only supported for %s - use a dump for %s", datastore.DriverSQLite, db.Dialect().GetName())
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err := db.Exec("VACUUM INTO ?", path).Error; err != nil {
+		return fmt.Errorf("cannot snapshot the db: %v", err)
+	}
+	return os.Chmod(path, 0600)
+}
+
+// IsSnapshot returns true if the file is a snapshot of a sqlite-db
+func IsSnapshot(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	header := make([]byte, len(sqliteHeader))
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false, nil
+	}
+	return string(header) == sqliteHeader, nil
+}
+
+// Write writes a dump of all the db-tables to w, the
+// tables are read in one (read-only) transaction
+func Write(db *gorm.DB, w io.Writer) error {
+	opts := &sql.TxOptions{ReadOnly: true}
+	if db.Dialect().GetName() != datastore.DriverSQLite {
+		opts.Isolation = sql.LevelRepeatableRead
+	}
+	tx := db.BeginTx(context.Background(), opts)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer tx.Rollback()
+
+	version, err := tables.Version(tx)
+	if err != nil {
+		return err
+	}
+	dump := Dump{
+		Format:        Format,
+		SchemaVersion: version,
+		Driver:        db.Dialect().GetName(),
+		Created:       time.Now().Unix(),
+	}
+
+	for _, model := range tables.Models() {
+		rows := reflect.New(reflect.SliceOf(reflect.TypeOf(model).Elem()))
+		if err := tx.Order("id").Find(rows.Interface()).Error; err != nil {
+			return fmt.Errorf("cannot read %s: %v", tx.NewScope(model).TableName(), err)
+		}
+		b, err := json.Marshal(rows.Interface())
+		if err != nil {
+			return err
+		}
+		dump.Tables = append(dump.Tables, Table{Name: tx.NewScope(model).TableName(), Rows: b})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dump)
+}
+
+// Restore restores the dump from r to the db, the db must be empty
+// unless force is set - then the existing rows are replaced. The
+// dump is decoded and validated before the db is changed, the db is
+// migrated to the latest schema-version and the rows are replaced in
+// one transaction - so the existing rows are kept if the restore fails.
+// The rows from the dumps of older schema-versions are restored to the
+// latest version (the columns added since then get their zero-values)
+func Restore(db *gorm.DB, r io.Reader, force bool) error {
+	rows, err := decode(db, r)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tables.MigrateTo(db, 0); err != nil {
+		return err
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if force {
+		for _, model := range tables.Models() {
+			if err := tx.Exec("DELETE FROM " + tx.NewScope(model).QuotedTableName()).Error; err != nil {
+				tx.Rollback()
+				return fmt.Errorf("cannot delete the rows in %s: %v", tx.NewScope(model).TableName(), err)
+			}
+		}
+	} else if err := empty(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, table := range rows {
+		if err := restoreTable(tx, table.model, table.rows); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("cannot restore %s: %v", table.name, err)
+		}
+	}
+	return tx.Commit().Error
+}
+
+// tableRows are the decoded rows for a db-table in a dump
+type tableRows struct {
+	name  string
+	model interface{}
+	rows  reflect.Value
+}
+
+// decode decodes and validates the dump, the rows for the
+// tables are decoded to the models for the tables
+func decode(db *gorm.DB, r io.Reader) ([]tableRows, error) {
+	var dump Dump
+	if err := json.NewDecoder(r).Decode(&dump); err != nil {
+		return nil, fmt.Errorf("invalid dump: %v", err)
+	}
+	if dump.Format != Format {
+		return nil, fmt.Errorf("unsupported format for the dump: %d - expected %d", dump.Format, Format)
+	}
+	if dump.SchemaVersion < 1 || dump.SchemaVersion > tables.Latest() {
+		return nil, fmt.Errorf("unsupported schema-version for the dump: %d - the latest version is %d", dump.SchemaVersion, tables.Latest())
+	}
+
+	models := make(map[string]interface{})
+	for _, model := range tables.Models() {
+		models[db.NewScope(model).TableName()] = model
+	}
+
+	var decoded []tableRows
+	seen := make(map[string]bool)
+	for _, table := range dump.Tables {
+		model, ok := models[table.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown table in the dump: %s", table.Name)
+		}
+		if seen[table.Name] {
+			return nil, fmt.Errorf("the table %s is in the dump more than once", table.Name)
+		}
+		seen[table.Name] = true
+
+		slice := reflect.New(reflect.SliceOf(reflect.TypeOf(model).Elem()))
+		if err := json.Unmarshal(table.Rows, slice.Interface()); err != nil {
+			return nil, fmt.Errorf("invalid rows for %s in the dump: %v", table.Name, err)
+		}
+		decoded = append(decoded, tableRows{name: table.Name, model: model, rows: slice.Elem()})
+	}
+	return decoded, nil
+}
+
+// restoreTable inserts the rows for the model, the rows are
+// inserted as they are - without the hooks and associations
+func restoreTable(tx *gorm.DB, model interface{}, rows reflect.Value) error {
+	for i := 0; i < rows.Len(); i++ {
+		if err := insert(tx, rows.Index(i).Addr().Interface()); err != nil {
+			return err
+		}
+	}
+
+	// the sequence for the ids isn't updated by the inserts with the ids
+	if tx.Dialect().GetName() == datastore.DriverPostgres {
+		table := tx.NewScope(model).QuotedTableName()
+		stmt := fmt.Sprintf("SELECT setval(pg_get_serial_sequence(?, 'id'), (SELECT COALESCE(MAX(id), 0) + 1 FROM %s), false)", table)
+		if err := tx.Exec(stmt, tx.NewScope(model).TableName()).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insert inserts the row with all its columns
+func insert(tx *gorm.DB, row interface{}) error {
+	scope := tx.NewScope(row)
+	var columns, marks []string
+	var values []interface{}
+	for _, field := range scope.Fields() {
+		if !field.IsNormal || field.IsIgnored {
+			continue
+		}
+		columns = append(columns, scope.Quote(field.DBName))
+		marks = append(marks, "?")
+		values = append(values, field.Field.Interface())
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", scope.QuotedTableName(), strings.Join(columns, ", "), strings.Join(marks, ", "))
+	return tx.Exec(stmt, values...).Error
+}
+
+// empty returns an error if any of the db-tables have rows
+func empty(db *gorm.DB) error {
+	for _, model := range tables.Models() {
+		if !db.HasTable(model) {
+			continue
+		}
+		var count int
+		if err := db.Model(model).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != 0 {
+			return fmt.Errorf("the db isn't empty (%s has %d rows) - use force to replace it", db.NewScope(model).TableName(), count)
+		}
+	}
+	return nil
+}
+
+// CopySnapshot copies the snapshot to the path for the sqlite-db,
+// the path must not exist unless force is set (the service must
+// be stopped while the db is replaced)
+func CopySnapshot(snapshot, path string, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists - use force to replace it", path)
+	}
+
+	b, err := ioutil.ReadFile(snapshot)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(b, []byte(sqliteHeader)) {
+		return fmt.Errorf("%s is not a snapshot of a sqlite-db", snapshot)
+	}
+
+	tmp := path + ".restore"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package backup_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/backup"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/dbtest"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"github.com/jinzhu/gorm"
+	"github.com/matryer/is"
+)
+
+// seed creates a runner with a stage, a server
+// and a nms with a licence in the db
+func seed(is *is.I, db *gorm.DB) {
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01", Password: "enc:v1:1234:abcd"}).Error)
+	is.NoErr(db.Create(&api.Nms{Address: "nms01", Password: secrets.SecretReference("nms"), Licences: []api.Licence{
+		{Type: "enterprise-workstation", Amount: 4},
+	}}).Error)
+	is.NoErr(db.Create(&api.Runner{Name: "case-01", Hostname: "dev01", Nms: "nms01", Stages: []*api.Stage{
+		{Index: 0, Process: &api.Process{Profile: "Default"}},
+	}}).Error)
+}
+
+func TestSnapshot(t *testing.T) {
+	is := is.New(t)
+
+	dir, err := ioutil.TempDir("", "backup")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+
+	db, err := datastore.Open(datastore.DriverSQLite, filepath.Join(dir, "avian.db"))
+	is.NoErr(err)
+	defer db.Close()
+	is.NoErr(tables.Migrate(db))
+	seed(is, db)
+
+	path := filepath.Join(dir, "snapshot.db")
+	is.NoErr(backup.Snapshot(db, path))
+	is.True(backup.Snapshot(db, path) != nil) // the snapshot isn't overwritten
+
+	snapshot, err := backup.IsSnapshot(path)
+	is.NoErr(err)
+	is.True(snapshot)
+
+	restored := filepath.Join(dir, "restored.db")
+	is.NoErr(backup.CopySnapshot(path, restored, false))
+	is.True(backup.CopySnapshot(path, restored, false) != nil) // the db isn't replaced without force
+	is.NoErr(backup.CopySnapshot(path, restored, true))
+
+	copy, err := datastore.Open(datastore.DriverSQLite, restored)
+	is.NoErr(err)
+	defer copy.Close()
+
+	var runner api.Runner
+	is.NoErr(copy.Preload("Stages.Process").First(&runner, "name = ?", "case-01").Error)
+	is.Equal(len(runner.Stages), 1)
+	is.Equal(runner.Stages[0].Process.Profile, "Default")
+
+	version, err := tables.Version(copy)
+	is.NoErr(err)
+	is.Equal(version, tables.Latest())
+}
+
+func TestDump(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	is.NoErr(tables.Migrate(db))
+	seed(is, db)
+
+	var before api.Runner
+	is.NoErr(db.First(&before, "name = ?", "case-01").Error)
+
+	var buf bytes.Buffer
+	is.NoErr(backup.Write(db, &buf))
+	dump := buf.Bytes()
+
+	snapshot, err := backup.IsSnapshot(writeTemp(t, is, dump))
+	is.NoErr(err)
+	is.True(!snapshot)
+
+	// the db must be empty unless it is forced
+	is.True(backup.Restore(db, bytes.NewReader(dump), false) != nil)
+
+	is.NoErr(db.Delete(&api.Server{}, "hostname = ?", "dev01").Error)
+	is.NoErr(backup.Restore(db, bytes.NewReader(dump), true))
+
+	var server api.Server
+	is.NoErr(db.First(&server, "hostname = ?", "dev01").Error)
+	is.Equal(server.Password, "enc:v1:1234:abcd")
+
+	// the rows are restored as they are
+	var after api.Runner
+	is.NoErr(db.Preload("Stages.Process").First(&after, "name = ?", "case-01").Error)
+	is.Equal(after.ID, before.ID)
+	is.Equal(after.CTime, before.CTime)
+	is.Equal(after.MTime, before.MTime)
+	is.Equal(len(after.Stages), 1)
+	is.Equal(after.Stages[0].Process.Profile, "Default")
+
+	var nms api.Nms
+	is.NoErr(db.Preload("Licences").First(&nms, "address = ?", "nms01").Error)
+	is.Equal(len(nms.Licences), 1)
+
+	version, err := tables.Version(db)
+	is.NoErr(err)
+	is.Equal(version, tables.Latest())
+
+	// the new rows get new ids after the restore
+	next := api.Server{Hostname: "dev02"}
+	is.NoErr(db.Create(&next).Error)
+	is.True(next.ID > server.ID)
+
+	// the dumps from newer versions are rejected
+	var newer backup.Dump
+	is.NoErr(json.Unmarshal(dump, &newer))
+	newer.SchemaVersion = tables.Latest() + 1
+	b, err := json.Marshal(newer)
+	is.NoErr(err)
+	is.True(backup.Restore(db, bytes.NewReader(b), true) != nil)
+
+	// the existing rows are kept if the dump can't be restored
+	invalid := [][]byte{
+		dump[:len(dump)/2], // truncated
+		change(is, dump, func(d *backup.Dump) {
+			d.Tables = append(d.Tables, backup.Table{Name: "unknown", Rows: json.RawMessage("[]")})
+		}),
+		change(is, dump, func(d *backup.Dump) {
+			d.Tables[0].Rows = json.RawMessage(`[{"id": "x"}]`)
+		}),
+		change(is, dump, func(d *backup.Dump) {
+			// the second row with the same id fails the insert
+			for i := range d.Tables {
+				if d.Tables[i].Name == "runners" {
+					var rows []json.RawMessage
+					is.NoErr(json.Unmarshal(d.Tables[i].Rows, &rows))
+					b, err := json.Marshal(append(rows, rows...))
+					is.NoErr(err)
+					d.Tables[i].Rows = b
+				}
+			}
+		}),
+	}
+	for _, b := range invalid {
+		is.True(backup.Restore(db, bytes.NewReader(b), true) != nil)
+		is.NoErr(db.First(&next, "hostname = ?", "dev02").Error)
+		is.NoErr(db.First(&after, "name = ?", "case-01").Error)
+	}
+
+	// the dumps from older versions are restored to the latest version
+	older := change(is, dump, func(d *backup.Dump) { d.SchemaVersion = 1 })
+	is.NoErr(backup.Restore(db, bytes.NewReader(older), true))
+	is.True(db.First(&next, "hostname = ?", "dev02").Error != nil) // replaced
+	is.NoErr(db.First(&after, "name = ?", "case-01").Error)
+	version, err = tables.Version(db)
+	is.NoErr(err)
+	is.Equal(version, tables.Latest())
+}
+
+// change returns the dump changed with fn
+func change(is *is.I, dump []byte, fn func(d *backup.Dump)) []byte {
+	var d backup.Dump
+	is.NoErr(json.Unmarshal(dump, &d))
+	fn(&d)
+	b, err := json.Marshal(d)
+	is.NoErr(err)
+	return b
+}
+
+func TestExport(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	is.NoErr(tables.Migrate(db))
+	seed(is, db)
+
+	var buf bytes.Buffer
+	is.NoErr(backup.WriteExport(db, &buf, backup.FormatJSON))
+	is.True(backup.WriteExport(db, &buf, "xml") != nil)
+
+	var export backup.Export
+	is.NoErr(json.NewDecoder(&buf).Decode(&export))
+	is.True(export.Exported != 0)
+
+	is.Equal(len(export.Servers), 1)
+	is.Equal(export.Servers[0].Password, secrets.Masked)
+
+	is.Equal(len(export.Nms), 1)
+	is.Equal(export.Nms[0].Password, secrets.SecretReference("nms"))
+	is.Equal(len(export.Nms[0].Licences), 1)
+	is.Equal(export.Nms[0].Licences[0].Amount, int64(4))
+
+	is.Equal(len(export.Runners), 1)
+	is.Equal(len(export.Runners[0].Stages), 1)
+	is.Equal(export.Runners[0].Stages[0].Process.Profile, "Default")
+}
+
+// writeTemp writes b to a temp-file that
+// is removed when the test is done
+func writeTemp(t *testing.T, is *is.I, b []byte) string {
+	f, err := ioutil.TempFile("", "dump")
+	is.NoErr(err)
+	t.Cleanup(func() { os.Remove(f.Name()) })
+	defer f.Close()
+	_, err = f.Write(b)
+	is.NoErr(err)
+	return f.Name()
+}
@@ -0,0 +1,68 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"github.com/jinzhu/gorm"
+)
+
+// FormatJSON is the format for the exports
+const FormatJSON = "json"
+
+// Export is the portable export of the runners (with their
+// stages), the servers and the nms-servers (with their licences)
+type Export struct {
+	// Exported is when the export was created (unix-time)
+	Exported int64 `json:"exported"`
+
+	Servers []api.Server `json:"servers"`
+	Nms     []api.Nms    `json:"nms"`
+	Runners []api.Runner `json:"runners"`
+}
+
+// WriteExport writes the export to w in the format, the
+// credentials are masked (the references are kept)
+func WriteExport(db *gorm.DB, w io.Writer, format string) error {
+	if format != FormatJSON {
+		return fmt.Errorf("unsupported format for the export: %q - use %s", format, FormatJSON)
+	}
+
+	export := Export{Exported: time.Now().Unix()}
+	if err := db.Order("hostname").Find(&export.Servers).Error; err != nil {
+		return fmt.Errorf("cannot get the servers: %v", err)
+	}
+	if err := db.Preload("Licences").Order("address").Find(&export.Nms).Error; err != nil {
+		return fmt.Errorf("cannot get the nms: %v", err)
+	}
+	if err := db.Preload("Stages.Process.EvidenceStore.Paths").
+		Preload("Stages.Process.EvidenceStore.Filters").
+		Preload("Stages.Process.EvidenceStore.Metadata").
+		Preload("Stages.SearchAndTag.Files").
+		Preload("Stages.Exclude").
+		Preload("Stages.Ocr").
+		Preload("Stages.Reload").
+		Preload("Stages.Populate.Types").
+		Preload("CaseSettings.Case").
+		Preload("CaseSettings.CompoundCase").
+		Preload("CaseSettings.ReviewCompound").
+		Preload("Switches").
+		Order("name").Find(&export.Runners).Error; err != nil {
+		return fmt.Errorf("cannot get the runners: %v", err)
+	}
+
+	for i := range export.Servers {
+		export.Servers[i].Password = secrets.Mask(export.Servers[i].Password)
+	}
+	for i := range export.Nms {
+		export.Nms[i].Password = secrets.Mask(export.Nms[i].Password)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(export)
+}
@@ -27,3 +27,17 @@ func (b *Base) BeforeDelete(scope *gorm.Scope) (err error) {
 	scope.SetColumn("DTime", time.Now().Unix())
 	return nil
 }
+
+// NotDeleted scopes the query to the rows that
+// aren't soft-deleted (in the trash), use it with:
+//
+//	db.Scopes(datastore.NotDeleted)
+func NotDeleted(db *gorm.DB) *gorm.DB {
+	return db.Where("d_time IS NULL")
+}
+
+// Deleted scopes the query to the rows
+// that are soft-deleted (in the trash)
+func Deleted(db *gorm.DB) *gorm.DB {
+	return db.Where("d_time IS NOT NULL")
+}
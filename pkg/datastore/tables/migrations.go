@@ -0,0 +1,376 @@
+package tables
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Migration is a numbered step for the schema, the steps are applied
+// in order and the applied versions are recorded in schema_version.
+//
+// The first step creates the baseline schema (from before the versioned
+// migrations) and the next steps are the changes to it. The dbs from
+// before the versioned migrations can already have some of the changes
+// (they were added by AutoMigrate), so a step must check the schema
+// before it changes it (like HasColumn before DropColumn) - and a
+// step must not be changed once it has been released
+type Migration struct {
+	// Version of the schema after the step
+	Version int
+
+	// Description of the step
+	Description string
+
+	// Up applies the step, it is applied in a transaction
+	Up func(tx *gorm.DB) error
+}
+
+// migrations are the steps for the schema, new steps
+// are added last with the next version
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create the baseline tables and indexes",
+		Up: func(tx *gorm.DB) error {
+			if err := createTables(tx, baseline...); err != nil {
+				return err
+			}
+			if err := addIndex(tx, false, "idx_nms_address", "nms", "address"); err != nil {
+				return err
+			}
+			if err := addIndex(tx, false, "idx_server_hostname", "servers", "hostname"); err != nil {
+				return err
+			}
+			return addIndex(tx, false, "idx_runner_name", "runners", "name")
+		},
+	},
+	{
+		Version:     2,
+		Description: "add the paths, path-lists, load-files, filters and metadata for the evidence",
+		Up: func(tx *gorm.DB) error {
+			err := createTables(tx,
+				table{"evidence_paths", []column{
+					{"evidence_id", typeUint},
+					{"path", typeString},
+				}},
+				table{"evidence_filters", []column{
+					{"evidence_id", typeUint},
+					{"pattern", typeString},
+					{"exclude", typeBool},
+				}},
+				table{"evidence_metadata", []column{
+					{"evidence_id", typeUint},
+					{"key", typeString},
+					{"value", typeString},
+				}},
+			)
+			if err != nil {
+				return err
+			}
+			return addColumns(tx, "evidences",
+				column{"path_list", typeString},
+				column{"load_file", typeString},
+			)
+		},
+	},
+	{
+		Version:     3,
+		Description: "add the chain-of-custody manifests",
+		Up: func(tx *gorm.DB) error {
+			err := createTables(tx,
+				table{"manifests", []column{
+					{"runner_id", typeUint},
+					{"phase", typeString},
+					{"file_count", typeInt},
+					{"total_size", typeInt},
+					{"verified", typeBool},
+				}},
+				table{"manifest_evidences", []column{
+					{"manifest_id", typeUint},
+					{"name", typeString},
+					{"path", typeString},
+					{"file_count", typeInt},
+					{"total_size", typeInt},
+				}},
+				table{"manifest_files", []column{
+					{"manifest_evidence_id", typeUint},
+					{"path", typeString},
+					{"size", typeInt},
+					{"hash", typeString},
+				}},
+				table{"manifest_changes", []column{
+					{"manifest_id", typeUint},
+					{"path", typeString},
+					{"change", typeString},
+				}},
+			)
+			if err != nil {
+				return err
+			}
+			return addColumns(tx, "processes", column{"manifest", typeBool})
+		},
+	},
+	{
+		Version:     4,
+		Description: "add the script-engine for the runners and servers",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, "runners", column{"engine", typeString}); err != nil {
+				return err
+			}
+			return addColumns(tx, "servers", column{"engine", typeString})
+		},
+	},
+	{
+		Version:     5,
+		Description: "add the progress for the stages",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, "stages",
+				column{"total", typeInt},
+				column{"progress", typeInt},
+				column{"started_at", typeInt},
+				column{"estimated_finish", typeInt},
+			)
+		},
+	},
+	{
+		Version:     6,
+		Description: "add the api-tokens",
+		Up: func(tx *gorm.DB) error {
+			err := createTables(tx, table{"tokens", []column{
+				{"name", typeString},
+				{"key", typeString},
+				{"secret", typeString},
+				{"runner_id", typeUint},
+				{"runner", typeString},
+				{"expires_at", typeInt},
+			}})
+			if err != nil {
+				return err
+			}
+			return addIndex(tx, true, "idx_token_key", "tokens", "key")
+		},
+	},
+	{
+		Version:     7,
+		Description: "add the notifications and the deliveries",
+		Up: func(tx *gorm.DB) error {
+			err := createTables(tx,
+				table{"notifications", []column{
+					{"name", typeString},
+					{"runner", typeString},
+					{"triggers", typeString},
+					{"type", typeString},
+					{"endpoint", typeString},
+					{"body", typeString},
+					{"recipients", typeString},
+					{"subject", typeString},
+				}},
+				table{"deliveries", []column{
+					{"notification_id", typeUint},
+					{"notification", typeString},
+					{"runner", typeString},
+					{"trigger", typeString},
+					{"status", typeString},
+					{"attempts", typeInt},
+					{"last_error", typeString},
+					{"delivered_at", typeInt},
+				}},
+			)
+			if err != nil {
+				return err
+			}
+			if err := addIndex(tx, true, "idx_notification_name", "notifications", "name"); err != nil {
+				return err
+			}
+			return addIndex(tx, false, "idx_delivery_notification", "deliveries", "notification")
+		},
+	},
+	{
+		Version:     8,
+		Description: "add the run-id for the runners",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, "runners", column{"run_id", typeString})
+		},
+	},
+	{
+		Version:     9,
+		Description: "add the named secrets",
+		Up: func(tx *gorm.DB) error {
+			err := createTables(tx, table{"secrets", []column{
+				{"name", typeString},
+				{"value", typeString},
+			}})
+			if err != nil {
+				return err
+			}
+			return addIndex(tx, true, "idx_secret_name", "secrets", "name")
+		},
+	},
+	{
+		Version:     10,
+		Description: "add the audit-log",
+		Up: func(tx *gorm.DB) error {
+			err := createTables(tx, table{"audit_entries", []column{
+				{"actor", typeString},
+				{"key", typeString},
+				{"remote", typeString},
+				{"method", typeString},
+				{"request", typeString},
+				{"outcome", typeString},
+				{"reason", typeString},
+			}})
+			if err != nil {
+				return err
+			}
+			if err := addIndex(tx, false, "idx_audit_entry_time", "audit_entries", "c_time"); err != nil {
+				return err
+			}
+			return addIndex(tx, false, "idx_audit_entry_actor", "audit_entries", "actor")
+		},
+	},
+	{
+		Version:     11,
+		Description: "change the long columns to text for mysql",
+		Up:          textColumns,
+	},
+	{
+		Version:     12,
+		Description: "add the event to the deliveries",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, "deliveries", column{"event", typeText})
+		},
+	},
+	{
+		Version:     13,
+		Description: "add the admin-tokens",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, "tokens", column{"admin", typeBool}); err != nil {
+				return err
+			}
+			// the existing tokens for the clients keep the access they had
+			return tx.Table("tokens").Where("runner_id = ?", 0).UpdateColumn("admin", true).Error
+		},
+	},
+}
+
+// SchemaVersion is an applied migration
+type SchemaVersion struct {
+	// Version of the schema after the migration
+	Version int `gorm:"primary_key;auto_increment:false"`
+
+	// Description of the migration
+	Description string
+
+	// AppliedAt is when the migration was applied (unix-time)
+	AppliedAt int64
+}
+
+// TableName is the table for the schema-versions
+func (SchemaVersion) TableName() string {
+	return "schema_version"
+}
+
+// Migrations returns the steps for the schema
+func Migrations() []Migration {
+	return migrations
+}
+
+// Latest returns the latest version for the schema
+func Latest() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// Version returns the current version for the schema,
+// it is 0 for the dbs without any applied migrations
+func Version(db *gorm.DB) (int, error) {
+	if !db.HasTable(&SchemaVersion{}) {
+		return 0, nil
+	}
+
+	var version SchemaVersion
+	if err := db.Order("version desc").First(&version).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("cannot get the schema-version: %v", err)
+	}
+	return version.Version, nil
+}
+
+// Pending returns the migrations to apply to reach the
+// version, to is the latest version if it is 0
+func Pending(db *gorm.DB, to int) ([]Migration, error) {
+	if to == 0 {
+		to = Latest()
+	}
+	if to < 0 || to > Latest() {
+		return nil, fmt.Errorf("invalid schema-version: %d - the latest version is %d", to, Latest())
+	}
+
+	current, err := Version(db)
+	if err != nil {
+		return nil, err
+	}
+	if current > Latest() {
+		return nil, fmt.Errorf("the schema-version of the db (%d) is newer than the latest version (%d) - upgrade avian", current, Latest())
+	}
+	if to < current {
+		return nil, fmt.Errorf("cannot migrate to version %d - the db is at version %d and downgrades are not supported", to, current)
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > current && m.Version <= to {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// MigrateTo applies the pending migrations to reach the version, to is
+// the latest version if it is 0, it returns the applied migrations
+func MigrateTo(db *gorm.DB, to int) ([]Migration, error) {
+	pending, err := Pending(db, to)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&SchemaVersion{}).Error; err != nil {
+		return nil, fmt.Errorf("cannot create the schema_version table: %v", err)
+	}
+
+	var applied []Migration
+	for _, m := range pending {
+		if err := apply(db, m); err != nil {
+			return applied, fmt.Errorf("cannot migrate to version %d (%s): %v", m.Version, m.Description, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// Migrate applies the pending migrations to reach the latest version
+func Migrate(db *gorm.DB) error {
+	_, err := MigrateTo(db, 0)
+	return err
+}
+
+// apply applies the migration and records the version in a transaction
+func apply(db *gorm.DB, m Migration) error {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := m.Up(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	version := SchemaVersion{Version: m.Version, Description: m.Description, AppliedAt: time.Now().Unix()}
+	if err := tx.Create(&version).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
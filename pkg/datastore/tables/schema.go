@@ -0,0 +1,262 @@
+package tables
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
+	"github.com/jinzhu/gorm"
+)
+
+// the types for the columns in the migrations, they are
+// mapped to the types for the dialect (the same types
+// as AutoMigrate would use for the fields of the models)
+const (
+	typeID     = "id"     // uint primary-key
+	typeUint   = "uint"   // uint (foreign-keys)
+	typeInt    = "int"    // int64
+	typeString = "string" // string
+	typeText   = "text"   // string (longer than 255 characters)
+	typeBool   = "bool"   // bool
+	typeTime   = "time"   // *time.Time
+)
+
+// types are the types for the columns by dialect
+var types = map[string]map[string]string{
+	datastore.DriverSQLite: {
+		typeID:     "integer primary key autoincrement",
+		typeUint:   "integer",
+		typeInt:    "bigint",
+		typeString: "varchar(255)",
+		typeText:   "varchar(255)",
+		typeBool:   "bool",
+		typeTime:   "datetime",
+	},
+	datastore.DriverPostgres: {
+		typeID:     "serial primary key",
+		typeUint:   "integer",
+		typeInt:    "bigint",
+		typeString: "text",
+		typeText:   "text",
+		typeBool:   "boolean",
+		typeTime:   "timestamp with time zone",
+	},
+	datastore.DriverMySQL: {
+		typeID:     "int unsigned AUTO_INCREMENT primary key",
+		typeUint:   "int unsigned",
+		typeInt:    "bigint",
+		typeString: "varchar(255)",
+		typeText:   "text",
+		typeBool:   "boolean",
+		typeTime:   "DATETIME NULL",
+	},
+}
+
+// column is a column for a table in the migrations
+type column struct {
+	name     string
+	dataType string
+}
+
+// table is a table in the migrations, the columns for
+// datastore.Base (id, c_time, m_time and d_time) are
+// added to all the tables
+type table struct {
+	name    string
+	columns []column
+}
+
+// base are the columns for datastore.Base
+var base = []column{
+	{"id", typeID},
+	{"c_time", typeInt},
+	{"m_time", typeInt},
+	{"d_time", typeInt},
+}
+
+// sqlType returns the type for the column for the dialect
+func sqlType(db *gorm.DB, dataType string) (string, error) {
+	dialect := db.Dialect().GetName()
+	sqlType, ok := types[dialect][dataType]
+	if !ok {
+		return "", fmt.Errorf("no type for %s columns with %s", dataType, dialect)
+	}
+	return sqlType, nil
+}
+
+// createTables creates the tables that don't exist, with
+// the index for d_time (the soft-deletes) for the tables
+func createTables(db *gorm.DB, tables ...table) error {
+	for _, t := range tables {
+		if db.HasTable(t.name) {
+			continue
+		}
+
+		var columns []string
+		for _, c := range append(append([]column(nil), base...), t.columns...) {
+			sqlType, err := sqlType(db, c.dataType)
+			if err != nil {
+				return err
+			}
+			columns = append(columns, db.Dialect().Quote(c.name)+" "+sqlType)
+		}
+		stmt := fmt.Sprintf("CREATE TABLE %s (%s)", db.Dialect().Quote(t.name), strings.Join(columns, ","))
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("unable to create table %s: %v", t.name, err)
+		}
+		if err := db.Table(t.name).AddIndex("idx_"+t.name+"_d_time", "d_time").Error; err != nil {
+			return fmt.Errorf("unable to add index to %s d_time: %v", t.name, err)
+		}
+	}
+	return nil
+}
+
+// addColumns adds the columns that don't exist to the table
+func addColumns(db *gorm.DB, name string, columns ...column) error {
+	for _, c := range columns {
+		if db.Dialect().HasColumn(name, c.name) {
+			continue
+		}
+		sqlType, err := sqlType(db, c.dataType)
+		if err != nil {
+			return err
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD %s %s", db.Dialect().Quote(name), db.Dialect().Quote(c.name), sqlType)
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("unable to add column %s.%s: %v", name, c.name, err)
+		}
+	}
+	return nil
+}
+
+// addIndex adds the index to the table, if it doesn't exist
+func addIndex(db *gorm.DB, unique bool, name, table string, columns ...string) error {
+	query := db.Table(table)
+	if unique {
+		query = query.AddUniqueIndex(name, columns...)
+	} else {
+		query = query.AddIndex(name, columns...)
+	}
+	if err := query.Error; err != nil {
+		return fmt.Errorf("unable to add index %s to %s: %v", name, table, err)
+	}
+	return nil
+}
+
+// baseline are the tables before the versioned migrations, as
+// they were created by AutoMigrate before the first release
+// with the migrations - they must not be changed, the changes
+// to the schema are added as new migrations
+var baseline = []table{
+	{"nms", []column{
+		{"address", typeString},
+		{"port", typeInt},
+		{"username", typeString},
+		{"password", typeString},
+		{"workers", typeInt},
+		{"in_use", typeInt},
+	}},
+	{"licences", []column{
+		{"nms_id", typeUint},
+		{"type", typeString},
+		{"amount", typeInt},
+		{"in_use", typeInt},
+	}},
+	{"servers", []column{
+		{"hostname", typeString},
+		{"port", typeInt},
+		{"operating_system", typeString},
+		{"username", typeString},
+		{"password", typeString},
+		{"nuix_path", typeString},
+		{"active", typeBool},
+	}},
+	{"runners", []column{
+		{"name", typeString},
+		{"hostname", typeString},
+		{"nms", typeString},
+		{"licence", typeString},
+		{"xmx", typeString},
+		{"workers", typeInt},
+		{"active", typeBool},
+		{"status", typeInt},
+		{"healthy_at", typeTime},
+		{"case_settings_id", typeUint},
+	}},
+	{"nuix_switches", []column{
+		{"runner_id", typeUint},
+		{"value", typeString},
+	}},
+	{"case_settings", []column{
+		{"case_location", typeString},
+		{"case_id", typeUint},
+		{"compound_case_id", typeUint},
+		{"review_compound_id", typeUint},
+	}},
+	{"cases", []column{
+		{"name", typeString},
+		{"directory", typeString},
+		{"description", typeString},
+		{"investigator", typeString},
+	}},
+	{"evidences", []column{
+		{"process_id", typeUint},
+		{"name", typeString},
+		{"directory", typeString},
+		{"description", typeString},
+		{"encoding", typeString},
+		{"time_zone", typeString},
+		{"custodian", typeString},
+		{"locale", typeString},
+	}},
+	{"stages", []column{
+		{"runner_id", typeUint},
+		{"index", typeUint},
+	}},
+	{"processes", []column{
+		{"stage_id", typeUint},
+		{"profile", typeString},
+		{"profile_path", typeString},
+		{"status", typeInt},
+	}},
+	{"search_and_tags", []column{
+		{"stage_id", typeUint},
+		{"search", typeString},
+		{"tag", typeString},
+		{"status", typeInt},
+	}},
+	{"excludes", []column{
+		{"stage_id", typeUint},
+		{"search", typeString},
+		{"reason", typeString},
+		{"status", typeInt},
+	}},
+	{"populates", []column{
+		{"stage_id", typeUint},
+		{"search", typeString},
+		{"status", typeInt},
+	}},
+	{"reloads", []column{
+		{"stage_id", typeUint},
+		{"profile", typeString},
+		{"profile_path", typeString},
+		{"search", typeString},
+		{"status", typeInt},
+	}},
+	{"ocrs", []column{
+		{"stage_id", typeUint},
+		{"profile", typeString},
+		{"profile_path", typeString},
+		{"search", typeString},
+		{"status", typeInt},
+	}},
+	{"files", []column{
+		{"search_and_tag_id", typeUint},
+		{"path", typeString},
+	}},
+	{"types", []column{
+		{"populate_id", typeUint},
+		{"type", typeString},
+		{"status", typeInt},
+	}},
+}
@@ -4,47 +4,90 @@ import (
 	"fmt"
 
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
 	"github.com/jinzhu/gorm"
 )
 
-// Migrate the db-tables
-func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&api.Nms{},
-		&api.Licence{},
-		&api.Server{},
-		&api.Runner{},
-		&api.NuixSwitch{},
-		&api.CaseSettings{},
-		&api.Case{},
-		&api.Evidence{},
-		&api.Stage{},
-		&api.Process{},
-		&api.SearchAndTag{},
-		&api.Exclude{},
-		&api.Populate{},
-		&api.Reload{},
-		&api.Ocr{},
-		&api.File{},
-		&api.Type{},
-	).Error
+// models are the models for the db-tables
+var models = []interface{}{
+	&api.Nms{},
+	&api.Licence{},
+	&api.Server{},
+	&api.Runner{},
+	&api.NuixSwitch{},
+	&api.CaseSettings{},
+	&api.Case{},
+	&api.Evidence{},
+	&api.EvidencePath{},
+	&api.EvidenceFilter{},
+	&api.EvidenceMetadata{},
+	&api.Stage{},
+	&api.Process{},
+	&api.SearchAndTag{},
+	&api.Exclude{},
+	&api.Populate{},
+	&api.Reload{},
+	&api.Ocr{},
+	&api.File{},
+	&api.Type{},
+	&api.Manifest{},
+	&api.ManifestEvidence{},
+	&api.ManifestFile{},
+	&api.ManifestChange{},
+	&api.Token{},
+	&api.Notification{},
+	&api.Delivery{},
+	&api.Secret{},
+	&api.AuditEntry{},
 }
 
-// Index the tables
-func Index(db *gorm.DB) error {
-	// add index to nms-address
-	if err := db.Model(&api.Nms{}).AddIndex("idx_nms_address", "address").Error; err != nil {
-		return fmt.Errorf("unable to add index to nms-address")
-	}
+// texts are the columns that can be longer than 255 characters,
+// they are changed to text for the dialects with varchar(255)
+// as the default for the strings (mysql)
+var texts = []struct {
+	table   string
+	columns []string
+}{
+	{"notifications", []string{"endpoint", "body", "recipients"}},
+	{"deliveries", []string{"last_error"}},
+	{"secrets", []string{"value"}},
+	{"audit_entries", []string{"request", "reason"}},
+	{"cases", []string{"description"}},
+	{"evidences", []string{"description"}},
+	{"evidence_metadata", []string{"value"}},
+	{"search_and_tags", []string{"search"}},
+	{"populates", []string{"search"}},
+	{"ocrs", []string{"search"}},
+	{"excludes", []string{"search"}},
+	{"reloads", []string{"search"}},
+}
 
-	// add index to server-hostname
-	if err := db.Model(&api.Server{}).AddIndex("idx_server_hostname", "hostname").Error; err != nil {
-		return fmt.Errorf("unable to add index to server-hostname")
-	}
+// Models returns the models for the db-tables
+func Models() []interface{} {
+	return append([]interface{}(nil), models...)
+}
 
-	// add index to runner-name
-	if err := db.Model(&api.Runner{}).AddIndex("idx_runner_name", "name").Error; err != nil {
-		return fmt.Errorf("unable to add index to server-hostname")
+// textColumns changes the long columns to text for the dialects
+// with varchar(255) as the default for the strings (mysql)
+func textColumns(db *gorm.DB) error {
+	if db.Dialect().GetName() != datastore.DriverMySQL {
+		return nil
+	}
+	for _, text := range texts {
+		for _, column := range text.columns {
+			var dataType string
+			row := db.Raw("SELECT data_type FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?",
+				text.table, column).Row()
+			if err := row.Scan(&dataType); err != nil {
+				return fmt.Errorf("unable to get the type for %s.%s: %v", text.table, column, err)
+			}
+			if dataType == "text" {
+				continue
+			}
+			if err := db.Table(text.table).ModifyColumn(column, "text").Error; err != nil {
+				return fmt.Errorf("unable to change %s.%s to text: %v", text.table, column, err)
+			}
+		}
 	}
 	return nil
 }
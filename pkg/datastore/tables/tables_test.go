@@ -0,0 +1,187 @@
+package tables_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/dbtest"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/jinzhu/gorm"
+	"github.com/matryer/is"
+)
+
+// envCopy is the env-variable for a copy of a production-db (sqlite)
+// to test the migrations against, instead of testdata/legacy.sql
+const envCopy = "AVIAN_TEST_DB_COPY"
+
+func TestMigrate(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+
+	version, err := tables.Version(db)
+	is.NoErr(err)
+	is.Equal(version, 0)
+
+	pending, err := tables.Pending(db, 0)
+	is.NoErr(err)
+	is.Equal(len(pending), len(tables.Migrations()))
+
+	applied, err := tables.MigrateTo(db, 0)
+	is.NoErr(err)
+	is.Equal(len(applied), len(tables.Migrations()))
+
+	version, err = tables.Version(db)
+	is.NoErr(err)
+	is.Equal(version, tables.Latest())
+
+	// the migrations are only applied once
+	applied, err = tables.MigrateTo(db, 0)
+	is.NoErr(err)
+	is.Equal(len(applied), 0)
+
+	// the steps create all the tables and columns for the models
+	complete(is, db)
+
+	// the unique indexes are created
+	is.NoErr(db.Create(&api.Secret{Name: "nms", Value: "pw"}).Error)
+	is.True(db.Create(&api.Secret{Name: "nms", Value: "pw"}).Error != nil)
+
+	// the long values fit in the columns for all the dialects
+	entry := api.AuditEntry{Method: "RunnerService.Apply", Request: strings.Repeat("a", 4096)}
+	is.NoErr(db.Create(&entry).Error)
+	var stored api.AuditEntry
+	is.NoErr(db.First(&stored, entry.ID).Error)
+	is.Equal(len(stored.Request), 4096)
+
+	// the versions out of range are rejected
+	_, err = tables.Pending(db, tables.Latest()+1)
+	is.True(err != nil)
+}
+
+// TestMigrations applies the migrations one step at a time to a
+// db from before the versioned migrations (testdata/legacy.sql),
+// or to a copy of a production-db from AVIAN_TEST_DB_COPY
+func TestMigrations(t *testing.T) {
+	is := is.New(t)
+
+	dir, err := ioutil.TempDir("", "migrations")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "avian.db")
+
+	if src := os.Getenv(envCopy); src != "" {
+		b, err := ioutil.ReadFile(src)
+		is.NoErr(err)
+		is.NoErr(ioutil.WriteFile(path, b, 0600))
+	}
+	db, err := datastore.Open(datastore.DriverSQLite, path)
+	is.NoErr(err)
+	defer db.Close()
+	if os.Getenv(envCopy) == "" {
+		load(is, db, "testdata/legacy.sql")
+	}
+
+	before := count(is, db)
+	current, err := tables.Version(db)
+	is.NoErr(err)
+
+	for _, m := range tables.Migrations() {
+		if m.Version <= current {
+			continue
+		}
+
+		applied, err := tables.MigrateTo(db, m.Version)
+		if err != nil {
+			t.Fatalf("migration %d (%s): %v", m.Version, m.Description, err)
+		}
+		is.Equal(len(applied), 1)
+
+		version, err := tables.Version(db)
+		is.NoErr(err)
+		is.Equal(version, m.Version)
+
+		// the data is kept by the migrations
+		is.Equal(count(is, db), before)
+	}
+
+	complete(is, db)
+	if os.Getenv(envCopy) == "" {
+		var runner api.Runner
+		is.NoErr(db.Preload("Stages.Process.EvidenceStore").First(&runner, "name = ?", "case-1").Error)
+		is.Equal(runner.CaseSettingsID, uint(1))
+		is.Equal(len(runner.Stages), 1)
+		is.Equal(runner.Stages[0].Process.Profile, "Default")
+		is.Equal(len(runner.Stages[0].Process.EvidenceStore), 1)
+		is.Equal(runner.Stages[0].Process.EvidenceStore[0].Directory, `C:\Evidence\1`)
+	}
+
+	// the downgrades are not supported
+	if tables.Latest() > 1 {
+		_, err = tables.MigrateTo(db, 1)
+		is.True(err != nil)
+	}
+}
+
+// TestMigrateAutoMigrated applies the steps to a db where all the
+// tables were created by AutoMigrate and recorded as version 1
+// (by the first release with the versioned migrations)
+func TestMigrateAutoMigrated(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+
+	is.NoErr(db.AutoMigrate(tables.Models()...).Error)
+	is.NoErr(db.AutoMigrate(&tables.SchemaVersion{}).Error)
+	is.NoErr(db.Create(&tables.SchemaVersion{Version: 1, Description: "create the tables and indexes"}).Error)
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01"}).Error)
+
+	applied, err := tables.MigrateTo(db, 0)
+	is.NoErr(err)
+	is.Equal(len(applied), tables.Latest()-1)
+	complete(is, db)
+
+	var server api.Server
+	is.NoErr(db.First(&server, "hostname = ?", "dev01").Error)
+}
+
+// complete checks that the db has the tables
+// and the columns for all the models
+func complete(is *is.I, db *gorm.DB) {
+	for _, model := range tables.Models() {
+		scope := db.NewScope(model)
+		is.True(db.HasTable(model))
+		for _, field := range scope.Fields() {
+			if !field.IsNormal || field.IsIgnored {
+				continue
+			}
+			if !db.Dialect().HasColumn(scope.TableName(), field.DBName) {
+				is.Fail() // missing column
+			}
+		}
+	}
+}
+
+// load executes the statements in the sql-file
+func load(is *is.I, db *gorm.DB, path string) {
+	b, err := ioutil.ReadFile(path)
+	is.NoErr(err)
+	for _, stmt := range strings.Split(string(b), ";\n") {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+		is.NoErr(db.Exec(stmt).Error)
+	}
+}
+
+// count returns the amount of rows for the runners, servers and nms
+func count(is *is.I, db *gorm.DB) [3]int {
+	var c [3]int
+	is.NoErr(db.Model(&api.Runner{}).Count(&c[0]).Error)
+	is.NoErr(db.Model(&api.Server{}).Count(&c[1]).Error)
+	is.NoErr(db.Model(&api.Nms{}).Count(&c[2]).Error)
+	return c
+}
@@ -0,0 +1,51 @@
+-- legacy.sql is the schema for sqlite from before the versioned
+-- migrations (created with AutoMigrate and Index at the baseline,
+-- before the series of changes that are migrated by the steps)
+-- with some data, the migrations are tested against it
+CREATE TABLE "case_settings" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"case_location" varchar(255),"case_id" integer,"compound_case_id" integer,"review_compound_id" integer );
+CREATE TABLE "cases" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"name" varchar(255),"directory" varchar(255),"description" varchar(255),"investigator" varchar(255) );
+CREATE TABLE "evidences" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"process_id" integer,"name" varchar(255),"directory" varchar(255),"description" varchar(255),"encoding" varchar(255),"time_zone" varchar(255),"custodian" varchar(255),"locale" varchar(255) );
+CREATE TABLE "excludes" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"stage_id" integer,"search" varchar(255),"reason" varchar(255),"status" bigint );
+CREATE TABLE "files" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"search_and_tag_id" integer,"path" varchar(255) );
+CREATE TABLE "licences" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"nms_id" integer,"type" varchar(255),"amount" bigint,"in_use" bigint );
+CREATE TABLE "nms" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"address" varchar(255),"port" bigint,"username" varchar(255),"password" varchar(255),"workers" bigint,"in_use" bigint );
+CREATE TABLE "nuix_switches" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"runner_id" integer,"value" varchar(255) );
+CREATE TABLE "ocrs" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"stage_id" integer,"profile" varchar(255),"profile_path" varchar(255),"search" varchar(255),"status" bigint );
+CREATE TABLE "populates" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"stage_id" integer,"search" varchar(255),"status" bigint );
+CREATE TABLE "processes" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"stage_id" integer,"profile" varchar(255),"profile_path" varchar(255),"status" bigint );
+CREATE TABLE "reloads" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"stage_id" integer,"profile" varchar(255),"profile_path" varchar(255),"search" varchar(255),"status" bigint );
+CREATE TABLE "runners" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"name" varchar(255),"hostname" varchar(255),"nms" varchar(255),"licence" varchar(255),"xmx" varchar(255),"workers" bigint,"active" bool,"status" bigint,"healthy_at" datetime,"case_settings_id" integer );
+CREATE TABLE "search_and_tags" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"stage_id" integer,"search" varchar(255),"tag" varchar(255),"status" bigint );
+CREATE TABLE "servers" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"hostname" varchar(255),"port" bigint,"operating_system" varchar(255),"username" varchar(255),"password" varchar(255),"nuix_path" varchar(255),"active" bool );
+CREATE TABLE "stages" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"runner_id" integer,"index" integer );
+CREATE TABLE "types" ("id" integer primary key autoincrement,"c_time" bigint,"m_time" bigint,"d_time" bigint,"populate_id" integer,"type" varchar(255),"status" bigint );
+CREATE INDEX idx_case_settings_d_time ON "case_settings"(d_time) ;
+CREATE INDEX idx_cases_d_time ON "cases"(d_time) ;
+CREATE INDEX idx_evidences_d_time ON "evidences"(d_time) ;
+CREATE INDEX idx_excludes_d_time ON "excludes"(d_time) ;
+CREATE INDEX idx_files_d_time ON "files"(d_time) ;
+CREATE INDEX idx_licences_d_time ON "licences"(d_time) ;
+CREATE INDEX idx_nms_address ON "nms"("address") ;
+CREATE INDEX idx_nms_d_time ON "nms"(d_time) ;
+CREATE INDEX idx_nuix_switches_d_time ON "nuix_switches"(d_time) ;
+CREATE INDEX idx_ocrs_d_time ON "ocrs"(d_time) ;
+CREATE INDEX idx_populates_d_time ON "populates"(d_time) ;
+CREATE INDEX idx_processes_d_time ON "processes"(d_time) ;
+CREATE INDEX idx_reloads_d_time ON "reloads"(d_time) ;
+CREATE INDEX idx_runner_name ON "runners"("name") ;
+CREATE INDEX idx_runners_d_time ON "runners"(d_time) ;
+CREATE INDEX idx_search_and_tags_d_time ON "search_and_tags"(d_time) ;
+CREATE INDEX idx_server_hostname ON "servers"("hostname") ;
+CREATE INDEX idx_servers_d_time ON "servers"(d_time) ;
+CREATE INDEX idx_stages_d_time ON "stages"(d_time) ;
+CREATE INDEX idx_types_d_time ON "types"(d_time) ;
+INSERT INTO "servers" ("c_time","m_time","hostname","port","operating_system","username","password","nuix_path","active") VALUES (1590000000,1590000000,'dev01',5985,'windows','avian','server-pw','C:\Program Files\Nuix\Nuix 8.4',0);
+INSERT INTO "nms" ("c_time","m_time","address","port","username","password","workers","in_use") VALUES (1590000000,1590000000,'license.avian.dk',27443,'avian','nms-pw',8,0);
+INSERT INTO "licences" ("c_time","m_time","nms_id","type","amount","in_use") VALUES (1590000000,1590000000,1,'enterprise-workstation',2,0);
+INSERT INTO "cases" ("c_time","m_time","name","directory","description","investigator") VALUES (1590000000,1590000000,'case-1','C:\Cases\case-1','','sja');
+INSERT INTO "case_settings" ("c_time","m_time","case_location","case_id","compound_case_id","review_compound_id") VALUES (1590000000,1590000000,'C:\Cases',1,0,0);
+INSERT INTO "runners" ("c_time","m_time","name","hostname","nms","licence","xmx","workers","active","status","case_settings_id") VALUES (1590000000,1590000100,'case-1','dev01','license.avian.dk','enterprise-workstation','-Xmx16g',4,0,3,1);
+INSERT INTO "nuix_switches" ("c_time","m_time","runner_id","value") VALUES (1590000000,1590000000,1,'-Dnuix.logdir=C:\Logs');
+INSERT INTO "stages" ("c_time","m_time","runner_id","index") VALUES (1590000000,1590000000,1,0);
+INSERT INTO "processes" ("c_time","m_time","stage_id","profile","profile_path","status") VALUES (1590000000,1590000000,1,'Default','',3);
+INSERT INTO "evidences" ("c_time","m_time","process_id","name","directory","description","encoding","time_zone","custodian","locale") VALUES (1590000000,1590000000,1,'evidence-1','C:\Evidence\1','','UTF-8','Europe/Stockholm','avian','en-US');
@@ -0,0 +1,125 @@
+// Package events streams the events for
+// the runners to the subscribed clients
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Types of the events
+const (
+	TypeRunner   = "runner"
+	TypeStage    = "stage"
+	TypeProgress = "progress"
+	TypeLog      = "log"
+)
+
+// bufferSize is the amount of events buffered for a subscriber,
+// the events are dropped for subscribers that can't keep up
+const bufferSize = 256
+
+// Event is an event for a runner
+type Event struct {
+	// Type of the event (runner, stage, progress or log)
+	Type string `json:"type"`
+
+	// Time of the event
+	Time time.Time `json:"time"`
+
+	// Runner the event is for
+	Runner string `json:"runner"`
+
+	// Matter is the name of the compound-case for the runner
+	Matter string `json:"matter,omitempty"`
+
+	// Stage and StageID for the stage-, progress- and log-events
+	Stage   string `json:"stage,omitempty"`
+	StageID uint   `json:"stageID,omitempty"`
+
+	// Status for the runner- and stage-events
+	Status string `json:"status,omitempty"`
+
+	// Level and Message for the log-events
+	Level   string `json:"level,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// Total, Progress and EstimatedFinish for the progress-events
+	Total           int64 `json:"total,omitempty"`
+	Progress        int64 `json:"progress,omitempty"`
+	EstimatedFinish int64 `json:"estimatedFinish,omitempty"`
+}
+
+// Filter for the events, empty values matches all events
+type Filter struct {
+	Runner string
+	Matter string
+}
+
+// Match returns true if the event matches the filter
+func (f Filter) Match(e Event) bool {
+	if f.Runner != "" && f.Runner != e.Runner {
+		return false
+	}
+	if f.Matter != "" && f.Matter != e.Matter {
+		return false
+	}
+	return true
+}
+
+// Broker publishes the events to the subscribers
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]Filter
+}
+
+// NewBroker returns a new broker
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[chan Event]Filter)}
+}
+
+// Subscribed returns true if there are any subscribers
+func (b *Broker) Subscribed() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers) != 0
+}
+
+// Subscribe returns a channel with the events that matches the filter,
+// the subscription is closed with the returned function
+func (b *Broker) Subscribe(filter Filter) (<-chan Event, func()) {
+	ch := make(chan Event, bufferSize)
+	b.mu.Lock()
+	b.subscribers[ch] = filter
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish publishes the event to the subscribers
+func (b *Broker) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch, filter := range b.subscribers {
+		if !filter.Match(e) {
+			continue
+		}
+		// don't block the publisher for a slow subscriber
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
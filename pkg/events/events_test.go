@@ -0,0 +1,68 @@
+package events_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func TestFilter(t *testing.T) {
+	is := is.New(t)
+
+	e := events.Event{Runner: "runner", Matter: "matter"}
+	is.True(events.Filter{}.Match(e))
+	is.True(events.Filter{Runner: "runner"}.Match(e))
+	is.True(events.Filter{Runner: "runner", Matter: "matter"}.Match(e))
+	is.True(!events.Filter{Runner: "other"}.Match(e))
+	is.True(!events.Filter{Runner: "runner", Matter: "other"}.Match(e))
+}
+
+func TestWatch(t *testing.T) {
+	is := is.New(t)
+
+	broker := events.NewBroker()
+	mux := http.NewServeMux()
+	mux.Handle("/events", events.Handler(broker, zap.NewNop()))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// publish the events when the client has subscribed
+	go func() {
+		for !broker.Subscribed() {
+			time.Sleep(10 * time.Millisecond)
+		}
+		broker.Publish(events.Event{Type: events.TypeLog, Runner: "other", Message: "filtered"})
+		broker.Publish(events.Event{Type: events.TypeStage, Runner: "runner", Status: "Running"})
+		broker.Publish(events.Event{Type: events.TypeRunner, Runner: "runner", Status: "Finished"})
+	}()
+
+	var got []events.Event
+	client := avian.New(srv.URL+"/oto/", "")
+	err := client.Watch(ctx, "runner", "", func(e events.Event) {
+		got = append(got, e)
+		if e.Type == events.TypeRunner {
+			cancel()
+		}
+	})
+	is.NoErr(err)
+	is.Equal(len(got), 2)
+	is.Equal(got[0].Type, events.TypeStage)
+	is.Equal(got[1].Status, "Finished")
+	is.True(!got[1].Time.IsZero())
+
+	// the subscription is closed with the connection
+	for i := 0; i < 100 && broker.Subscribed(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	is.True(!broker.Subscribed())
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// pingInterval is how often the connections are pinged
+	pingInterval = 30 * time.Second
+
+	// writeTimeout is the timeout for writing a message
+	writeTimeout = 10 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// Handler streams the events to the clients over websocket,
+// the events are filtered with the query-parameters runner and matter
+func Handler(broker *Broker, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter := Filter{
+			Runner: r.URL.Query().Get("runner"),
+			Matter: r.URL.Query().Get("matter"),
+		}
+		logger := logger.With(
+			zap.String("remote", r.RemoteAddr),
+			zap.String("runner", filter.Runner),
+			zap.String("matter", filter.Matter),
+		)
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			logger.Error("Cannot upgrade to websocket", zap.String("exception", err.Error()))
+			return
+		}
+		defer conn.Close()
+
+		events, unsubscribe := broker.Subscribe(filter)
+		defer unsubscribe()
+		logger.Debug("Subscribed to events")
+
+		// read from the connection to handle
+		// the control-messages and the close
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case e := <-events:
+				conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+				if err := conn.WriteJSON(e); err != nil {
+					logger.Debug("Cannot write event", zap.String("exception", err.Error()))
+					return
+				}
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+					logger.Debug("Cannot ping connection", zap.String("exception", err.Error()))
+					return
+				}
+			case <-closed:
+				logger.Debug("Unsubscribed from events")
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,126 @@
+// Package health handles the health- and
+// readiness-checks for the service
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// Check returns an error if the checked component isn't healthy
+type Check func() error
+
+type check struct {
+	name  string
+	check Check
+}
+
+// Checker runs the checks for the health- and readiness-endpoints
+type Checker struct {
+	mu       sync.RWMutex
+	live     []check
+	ready    []check
+	stopping int32
+}
+
+// New returns a new checker
+func New() *Checker {
+	return &Checker{}
+}
+
+// Live adds a check for the liveness of the service,
+// the live-checks are also checked for the readiness
+func (c *Checker) Live(name string, fn Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.live = append(c.live, check{name, fn})
+}
+
+// Ready adds a check for the readiness of the service
+func (c *Checker) Ready(name string, fn Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ready = append(c.ready, check{name, fn})
+}
+
+// Stop sets the service as stopping,
+// it isn't ready after it has been stopped
+func (c *Checker) Stop() {
+	atomic.StoreInt32(&c.stopping, 1)
+}
+
+// Stopping returns true if the service is stopping
+func (c *Checker) Stopping() bool {
+	return atomic.LoadInt32(&c.stopping) == 1
+}
+
+// Response is the response from the endpoints
+type Response struct {
+	// Status is ok if all checks passed, else unavailable
+	Status string `json:"status"`
+
+	// Checks with the result for every check (ok or the error)
+	Checks map[string]string `json:"checks"`
+}
+
+// Healthz returns the handler for the liveness-checks
+func (c *Checker) Healthz() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.RLock()
+		checks := c.live
+		c.mu.RUnlock()
+		write(w, run(checks))
+	})
+}
+
+// Readyz returns the handler for the readiness-checks
+func (c *Checker) Readyz() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.RLock()
+		checks := append(append([]check{}, c.live...), c.ready...)
+		c.mu.RUnlock()
+
+		resp := run(checks)
+		if c.Stopping() {
+			resp.Status = "unavailable"
+			resp.Checks["service"] = "stopping"
+		}
+		write(w, resp)
+	})
+}
+
+// run runs the checks concurrently
+func run(checks []check) Response {
+	resp := Response{Status: "ok", Checks: make(map[string]string)}
+	results := make([]error, len(checks))
+
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = checks[i].check()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range results {
+		if err != nil {
+			resp.Status = "unavailable"
+			resp.Checks[checks[i].name] = err.Error()
+			continue
+		}
+		resp.Checks[checks[i].name] = "ok"
+	}
+	return resp
+}
+
+func write(w http.ResponseWriter, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,56 @@
+package health_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/health"
+	"github.com/matryer/is"
+)
+
+func get(is *is.I, handler http.Handler) (int, health.Response) {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var resp health.Response
+	is.NoErr(json.NewDecoder(w.Body).Decode(&resp))
+	return w.Code, resp
+}
+
+func TestChecker(t *testing.T) {
+	is := is.New(t)
+
+	var dbErr error
+	checker := health.New()
+	checker.Live("queue", func() error { return nil })
+	checker.Ready("db", func() error { return dbErr })
+
+	code, resp := get(is, checker.Readyz())
+	is.Equal(code, http.StatusOK)
+	is.Equal(resp.Status, "ok")
+	is.Equal(resp.Checks, map[string]string{"queue": "ok", "db": "ok"})
+
+	// the readiness fails with the db, but not the liveness
+	dbErr = errors.New("database is locked")
+	code, resp = get(is, checker.Readyz())
+	is.Equal(code, http.StatusServiceUnavailable)
+	is.Equal(resp.Status, "unavailable")
+	is.Equal(resp.Checks["db"], "database is locked")
+
+	code, resp = get(is, checker.Healthz())
+	is.Equal(code, http.StatusOK)
+	is.Equal(resp.Checks, map[string]string{"queue": "ok"})
+
+	// the service isn't ready when it is stopping
+	dbErr = nil
+	checker.Stop()
+	code, resp = get(is, checker.Readyz())
+	is.Equal(code, http.StatusServiceUnavailable)
+	is.Equal(resp.Checks["service"], "stopping")
+
+	code, _ = get(is, checker.Healthz())
+	is.Equal(code, http.StatusOK)
+}
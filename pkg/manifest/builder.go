@@ -0,0 +1,113 @@
+package manifest
+
+import (
+	"fmt"
+	"sync"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/powershell"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"github.com/jinzhu/gorm"
+	ps "github.com/simonjanss/go-powershell"
+	"go.uber.org/zap"
+)
+
+// Builder creates the manifests for the evidence of the runners, every
+// manifest is created in its own powershell-process - so the listing
+// and hashing of the evidence doesn't hold the shell for the service
+type Builder struct {
+	db     *gorm.DB
+	start  func() (ps.Shell, error)
+	cipher *secrets.Cipher
+	logger *zap.Logger
+
+	// wg tracks the manifests that are recorded in the background
+	wg *sync.WaitGroup
+}
+
+// NewBuilder returns a new builder, start starts a new powershell-process
+// for each of the manifests - the manifests that are recorded in the
+// background are added to wg (so the service can wait for them)
+func NewBuilder(db *gorm.DB, start func() (ps.Shell, error), cipher *secrets.Cipher, logger *zap.Logger, wg *sync.WaitGroup) *Builder {
+	return &Builder{
+		db:     db,
+		start:  start,
+		cipher: cipher,
+		logger: logger,
+		wg:     wg,
+	}
+}
+
+// Create creates the manifest for the runner from the server in a
+// new powershell-process, the files are hashed if hash is true
+func (b *Builder) Create(runner api.Runner, server api.Server, phase string, hash bool) (*api.Manifest, error) {
+	var opts powershell.Options
+	opts.Host = server.Hostname
+	if len(server.Username) != 0 {
+		opts.Username = server.Username
+		password, err := secrets.Resolve(b.db, b.cipher, server.Password)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve password for server: %s - %v", server.Hostname, err)
+		}
+		opts.Password = password
+	}
+
+	process, err := b.start()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create powershell-process: %v", err)
+	}
+	defer process.Exit()
+
+	client, err := powershell.NewClient(process, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create remote-client for powershell: %v", err)
+	}
+	defer client.Close()
+
+	return Create(client, runner, phase, hash)
+}
+
+// Record creates the manifest for the runner and saves it, the
+// manifests for the runs are verified against the latest manifest
+func (b *Builder) Record(runner api.Runner, server api.Server, phase string, hash bool) error {
+	logger := b.logger.With(zap.String("runner", runner.Name), zap.String("phase", phase))
+
+	logger.Info("Creating manifest for the evidence")
+	m, err := b.Create(runner, server, phase, hash)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest for runner: %s - %v", runner.Name, err)
+	}
+
+	if phase != PhaseApply {
+		previous, err := Latest(b.db, runner.ID)
+		if err != nil && !gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("failed to get previous manifest for runner: %s - %v", runner.Name, err)
+		}
+		if err == nil {
+			Verify(previous, m)
+			if !m.Verified {
+				logger.Warn("Evidence has changed since the previous manifest", zap.Int("changes", len(m.Changes)))
+			}
+		}
+	}
+
+	if err := b.db.Save(m).Error; err != nil {
+		return fmt.Errorf("failed to save manifest for runner: %s - %v", runner.Name, err)
+	}
+	logger.Debug("Manifest has been created", zap.Int64("files", m.FileCount), zap.Int64("size", m.TotalSize))
+	return nil
+}
+
+// Go records the manifest for the runner in the background
+func (b *Builder) Go(runner api.Runner, server api.Server, phase string, hash bool) {
+	if b == nil {
+		return
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		if err := b.Record(runner, server, phase, hash); err != nil {
+			b.logger.Error("Cannot record manifest for the evidence", zap.String("runner", runner.Name), zap.String("exception", err.Error()))
+		}
+	}()
+}
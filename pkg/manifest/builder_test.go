@@ -0,0 +1,73 @@
+package manifest_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/dbtest"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/manifest"
+	"github.com/matryer/is"
+	ps "github.com/simonjanss/go-powershell"
+	"go.uber.org/zap"
+)
+
+// process is a powershell-process that lists the files
+type process struct {
+	files  string
+	exited bool
+}
+
+func (p *process) Execute(cmd string) (string, string, error) {
+	if strings.Contains(cmd, "Get-ChildItem") {
+		return p.files, "", nil
+	}
+	return "", "", nil
+}
+
+func (p *process) Exit()  { p.exited = true }
+func (p *process) Close() { p.Exit() }
+
+func TestBuilder(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	is.NoErr(tables.Migrate(db))
+
+	// every manifest is created in its own process
+	var processes []*process
+	var wg sync.WaitGroup
+	files := `[{"Path":"C:\\Evidence\\a.pst","Size":10}]`
+	builder := manifest.NewBuilder(db, func() (ps.Shell, error) {
+		p := &process{files: files}
+		processes = append(processes, p)
+		return p, nil
+	}, nil, zap.NewNop(), &wg)
+
+	runner := api.Runner{Name: "case-01", Stages: []*api.Stage{{Process: &api.Process{
+		Manifest:      true,
+		EvidenceStore: []*api.Evidence{{Name: "Evidence", Directory: `C:\Evidence`}},
+	}}}}
+	runner.ID = 1
+	server := api.Server{Hostname: "dev01"}
+
+	is.NoErr(builder.Record(runner, server, manifest.PhaseApply, false))
+
+	// the evidence has changed before the run, the
+	// manifest for the run is recorded in the background
+	files = `[{"Path":"C:\\Evidence\\a.pst","Size":20}]`
+	builder.Go(runner, server, manifest.PhaseRun, true)
+	wg.Wait()
+
+	is.Equal(len(processes), 2)
+	for _, p := range processes {
+		is.True(p.exited)
+	}
+
+	latest, err := manifest.Latest(db, runner.ID)
+	is.NoErr(err)
+	is.Equal(latest.Phase, manifest.PhaseRun)
+	is.True(!latest.Verified)
+	is.Equal(latest.FileCount, int64(1))
+}
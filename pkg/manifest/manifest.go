@@ -0,0 +1,197 @@
+package manifest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/powershell"
+	"github.com/jinzhu/gorm"
+)
+
+// Phases for when a manifest is created
+const (
+	PhaseApply  = "apply"
+	PhaseRun    = "run"
+	PhaseFinish = "finish"
+)
+
+// Changes for a file compared to the previous manifest
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// Enabled returns true if any of the process-stages
+// for the runner should have a manifest
+func Enabled(runner api.Runner) bool {
+	for _, stage := range runner.Stages {
+		if stage.Process != nil && stage.Process.Manifest {
+			return true
+		}
+	}
+	return false
+}
+
+// Create walks the evidence for the process-stages with manifest enabled
+// and returns a new manifest, the files are hashed if hash is true - the
+// path-lists are in the manifest with the paths that are listed in them
+func Create(client *powershell.Client, runner api.Runner, phase string, hash bool) (*api.Manifest, error) {
+	m := &api.Manifest{
+		RunnerID: runner.ID,
+		Phase:    phase,
+		Verified: true,
+	}
+
+	for _, stage := range runner.Stages {
+		if stage.Process == nil || !stage.Process.Manifest {
+			continue
+		}
+
+		for _, evidence := range stage.Process.EvidenceStore {
+			paths := evidence.SourcePaths()
+			if len(evidence.PathList) != 0 {
+				content, err := client.GetContent(evidence.PathList)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read path-list for evidence: %s - %v", evidence.Name, err)
+				}
+				paths = append(paths, evidence.PathList)
+				paths = append(paths, PathList(content)...)
+			}
+
+			for _, path := range paths {
+				var files []powershell.FileInfo
+				var err error
+				if powershell.IsUnc(path) {
+					files, err = client.ListFilesFromHost(path, hash)
+				} else {
+					files, err = client.ListFiles(path, hash)
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to list files for evidence: %s - %v", evidence.Name, err)
+				}
+
+				e := &api.ManifestEvidence{Name: evidence.Name, Path: path}
+				for _, file := range files {
+					e.Files = append(e.Files, &api.ManifestFile{
+						Path: file.Path,
+						Size: file.Size,
+						Hash: file.Hash,
+					})
+					e.FileCount++
+					e.TotalSize += file.Size
+				}
+
+				m.Evidence = append(m.Evidence, e)
+				m.FileCount += e.FileCount
+				m.TotalSize += e.TotalSize
+			}
+		}
+	}
+	return m, nil
+}
+
+// PathList returns the paths that are listed
+// in the content of a path-list (one per line)
+func PathList(content string) []string {
+	var paths []string
+	for _, path := range strings.Split(content, "\n") {
+		path = strings.TrimSpace(path)
+		if len(path) != 0 {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// Verify compares the manifest with the previous manifest,
+// the manifest is verified if there are no changes
+func Verify(previous, m *api.Manifest) {
+	m.Changes = Compare(previous, m)
+	m.Verified = (len(m.Changes) == 0)
+}
+
+// Compare returns the changes for the files
+// in the manifest from the previous manifest
+func Compare(previous, m *api.Manifest) []*api.ManifestChange {
+	before := files(previous)
+	after := files(m)
+
+	var changes []*api.ManifestChange
+	for path, file := range after {
+		old, ok := before[path]
+		if !ok {
+			changes = append(changes, &api.ManifestChange{Path: path, Change: ChangeAdded})
+			continue
+		}
+
+		// the hashes are only compared if both manifests have
+		// them, the manifest for apply only has the sizes
+		if old.Size != file.Size || (old.Hash != "" && file.Hash != "" && old.Hash != file.Hash) {
+			changes = append(changes, &api.ManifestChange{Path: path, Change: ChangeModified})
+		}
+	}
+
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changes = append(changes, &api.ManifestChange{Path: path, Change: ChangeRemoved})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// Latest returns the latest manifest for the runner
+func Latest(db *gorm.DB, runnerID uint) (*api.Manifest, error) {
+	var m api.Manifest
+	err := db.Preload("Evidence.Files").
+		Where("runner_id = ?", runnerID).
+		Order("id desc").
+		First(&m).Error
+	return &m, err
+}
+
+// Delete permanently deletes the manifests for the
+// runner with their evidence, files and changes
+func Delete(db *gorm.DB, runnerID uint) error {
+	var manifests []uint
+	if err := db.Model(&api.Manifest{}).Where("runner_id = ?", runnerID).Pluck("id", &manifests).Error; err != nil {
+		return fmt.Errorf("cannot get the manifests for runner: %v", err)
+	}
+	if len(manifests) == 0 {
+		return nil
+	}
+
+	var evidence []uint
+	if err := db.Model(&api.ManifestEvidence{}).Where("manifest_id IN (?)", manifests).Pluck("id", &evidence).Error; err != nil {
+		return fmt.Errorf("cannot get the evidence for the manifests: %v", err)
+	}
+	if len(evidence) != 0 {
+		if err := db.Where("manifest_evidence_id IN (?)", evidence).Delete(&api.ManifestFile{}).Error; err != nil {
+			return fmt.Errorf("cannot delete the files for the manifests: %v", err)
+		}
+	}
+	if err := db.Where("manifest_id IN (?)", manifests).Delete(&api.ManifestEvidence{}).Error; err != nil {
+		return fmt.Errorf("cannot delete the evidence for the manifests: %v", err)
+	}
+	if err := db.Where("manifest_id IN (?)", manifests).Delete(&api.ManifestChange{}).Error; err != nil {
+		return fmt.Errorf("cannot delete the changes for the manifests: %v", err)
+	}
+	if err := db.Where("id IN (?)", manifests).Delete(&api.Manifest{}).Error; err != nil {
+		return fmt.Errorf("cannot delete the manifests: %v", err)
+	}
+	return nil
+}
+
+func files(m *api.Manifest) map[string]*api.ManifestFile {
+	var files = make(map[string]*api.ManifestFile)
+	for _, e := range m.Evidence {
+		for _, file := range e.Files {
+			files[file.Path] = file
+		}
+	}
+	return files
+}
@@ -0,0 +1,58 @@
+package manifest_test
+
+import (
+	"testing"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/manifest"
+	"github.com/matryer/is"
+)
+
+func newManifest(files ...*api.ManifestFile) *api.Manifest {
+	return &api.Manifest{Evidence: []*api.ManifestEvidence{{Files: files}}}
+}
+
+func TestVerify(t *testing.T) {
+	is := is.New(t)
+
+	applied := newManifest(
+		&api.ManifestFile{Path: "C:\\Evidence\\a.pst", Size: 10},
+		&api.ManifestFile{Path: "C:\\Evidence\\b.pst", Size: 20},
+		&api.ManifestFile{Path: "C:\\Evidence\\c.pst", Size: 30},
+	)
+
+	run := newManifest(
+		&api.ManifestFile{Path: "C:\\Evidence\\a.pst", Size: 10, Hash: "AA"},
+		&api.ManifestFile{Path: "C:\\Evidence\\b.pst", Size: 20, Hash: "BB"},
+		&api.ManifestFile{Path: "C:\\Evidence\\c.pst", Size: 30, Hash: "CC"},
+	)
+
+	// sizes are equal and the apply-manifest has no hashes
+	manifest.Verify(applied, run)
+	is.True(run.Verified)
+	is.Equal(len(run.Changes), 0)
+
+	finished := newManifest(
+		&api.ManifestFile{Path: "C:\\Evidence\\a.pst", Size: 10, Hash: "AA"},
+		&api.ManifestFile{Path: "C:\\Evidence\\b.pst", Size: 20, Hash: "XX"},
+		&api.ManifestFile{Path: "C:\\Evidence\\d.pst", Size: 40, Hash: "DD"},
+	)
+
+	manifest.Verify(run, finished)
+	is.True(!finished.Verified)
+	is.Equal(len(finished.Changes), 3)
+	is.Equal(finished.Changes[0].Path, "C:\\Evidence\\b.pst")
+	is.Equal(finished.Changes[0].Change, manifest.ChangeModified)
+	is.Equal(finished.Changes[1].Path, "C:\\Evidence\\c.pst")
+	is.Equal(finished.Changes[1].Change, manifest.ChangeRemoved)
+	is.Equal(finished.Changes[2].Path, "C:\\Evidence\\d.pst")
+	is.Equal(finished.Changes[2].Change, manifest.ChangeAdded)
+}
+
+func TestPathList(t *testing.T) {
+	is := is.New(t)
+
+	paths := manifest.PathList("C:\\Evidence\\a.pst\r\n\r\n  \\\\fs01\\Evidence\\b.pst  \nC:\\Evidence\\c")
+	is.Equal(paths, []string{"C:\\Evidence\\a.pst", "\\\\fs01\\Evidence\\b.pst", "C:\\Evidence\\c"})
+	is.Equal(len(manifest.PathList("")), 0)
+}
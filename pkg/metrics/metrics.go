@@ -0,0 +1,274 @@
+// Package metrics exposes the metrics for
+// the service in the prometheus-format
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
+	"github.com/jinzhu/gorm"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+const namespace = "avian"
+
+// Metrics holds the metrics for the service in its own registry,
+// the methods for the metrics are no-ops for a nil Metrics
+type Metrics struct {
+	registry *prometheus.Registry
+
+	// stageDuration is the duration of the stages
+	// from when they started until they finished or failed
+	stageDuration *prometheus.HistogramVec
+
+	// itemsProcessed is the amount of items
+	// processed (logged with LogItem) by the runners
+	itemsProcessed prometheus.Counter
+
+	// heartbeatTimeouts is the amount of runners
+	// that have timed out without a heartbeat
+	heartbeatTimeouts prometheus.Counter
+
+	// requestDuration is the latency for the requests to the api
+	requestDuration *prometheus.HistogramVec
+}
+
+// New returns the metrics registered in a new registry, the state of
+// the runners, servers and nms-servers are collected from the db
+func New(db *gorm.DB, logger *zap.Logger) (*Metrics, error) {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		stageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "stage_duration_seconds",
+			Help:      "Duration of the stages until they finished or failed.",
+			Buckets:   []float64{60, 300, 900, 1800, 3600, 2 * 3600, 4 * 3600, 8 * 3600, 16 * 3600, 32 * 3600},
+		}, []string{"stage", "status"}),
+		itemsProcessed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "items_processed_total",
+			Help:      "Items processed by the runners.",
+		}),
+		heartbeatTimeouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "heartbeat_timeouts_total",
+			Help:      "Runners that have timed out without a heartbeat.",
+		}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "api_request_duration_seconds",
+			Help:      "Latency of the requests to the api per method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.stageDuration,
+		m.itemsProcessed,
+		m.heartbeatTimeouts,
+		m.requestDuration,
+		&collector{db: db, logger: logger},
+	} {
+		if err := m.registry.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Handler returns the handler for the metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// StageDuration observes the duration of a stage
+// that has finished or failed (with the status)
+func (m *Metrics) StageDuration(stage, status string, seconds float64) {
+	if m == nil {
+		return
+	}
+	m.stageDuration.WithLabelValues(stage, status).Observe(seconds)
+}
+
+// ItemsProcessed adds the items processed by a runner
+func (m *Metrics) ItemsProcessed(items int) {
+	if m == nil {
+		return
+	}
+	m.itemsProcessed.Add(float64(items))
+}
+
+// HeartbeatTimeout counts a runner that
+// has timed out without a heartbeat
+func (m *Metrics) HeartbeatTimeout() {
+	if m == nil {
+		return
+	}
+	m.heartbeatTimeouts.Inc()
+}
+
+// Instrument measures the latency for the requests to the oto-methods
+func (m *Metrics) Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &recorder{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		method := strings.TrimPrefix(r.URL.Path, "/oto/")
+		m.requestDuration.WithLabelValues(method, strconv.Itoa(rec.code)).Observe(time.Since(start).Seconds())
+	})
+}
+
+// recorder records the status-code of the response
+type recorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *recorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+var (
+	runnersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "runners"),
+		"Runners in the queue by status.",
+		[]string{"status"}, nil,
+	)
+	oldestWaitingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "queue_oldest_waiting_seconds"),
+		"Age of the oldest runner waiting in the queue.",
+		nil, nil,
+	)
+	serverRunnersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "server", "active_runners"),
+		"Active runners per server.",
+		[]string{"server"}, nil,
+	)
+	nmsWorkersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "nms", "workers"),
+		"Workers licensed to the nms.",
+		[]string{"nms"}, nil,
+	)
+	nmsWorkersInUseDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "nms", "workers_in_use"),
+		"Workers in use for the nms.",
+		[]string{"nms"}, nil,
+	)
+	licencesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "nms", "licences"),
+		"Licences available at the nms per type.",
+		[]string{"nms", "type"}, nil,
+	)
+	licencesInUseDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "nms", "licences_in_use"),
+		"Licences in use for the nms per type.",
+		[]string{"nms", "type"}, nil,
+	)
+)
+
+// collector collects the state of the runners,
+// servers and nms-servers from the db
+type collector struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- runnersDesc
+	ch <- oldestWaitingDesc
+	ch <- serverRunnersDesc
+	ch <- nmsWorkersDesc
+	ch <- nmsWorkersInUseDesc
+	ch <- licencesDesc
+	ch <- licencesInUseDesc
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	c.collectRunners(ch)
+	c.collectServers(ch)
+	c.collectNms(ch)
+}
+
+func (c *collector) collectRunners(ch chan<- prometheus.Metric) {
+	var rows []struct {
+		Status int64
+		Amount int64
+	}
+	if err := c.db.Model(&api.Runner{}).Scopes(datastore.NotDeleted).Select("status, count(*) as amount").Group("status").Scan(&rows).Error; err != nil {
+		c.logger.Error("Cannot collect metrics for runners", zap.String("exception", err.Error()))
+		return
+	}
+
+	// report every status, also the ones without runners
+	amounts := make(map[int64]int64)
+	for _, row := range rows {
+		amounts[row.Status] = row.Amount
+	}
+	for _, status := range []int64{avian.StatusWaiting, avian.StatusRunning, avian.StatusFailed, avian.StatusFinished, avian.StatusTimeout} {
+		ch <- prometheus.MustNewConstMetric(runnersDesc, prometheus.GaugeValue, float64(amounts[status]), strings.ToLower(avian.Status(status)))
+	}
+
+	var oldest float64
+	var waiting api.Runner
+	err := c.db.Scopes(datastore.NotDeleted).Select("c_time").Where("status = ? AND active = ?", avian.StatusWaiting, false).Order("c_time").First(&waiting).Error
+	if err == nil {
+		oldest = time.Since(time.Unix(waiting.CTime, 0)).Seconds()
+	} else if !gorm.IsRecordNotFoundError(err) {
+		c.logger.Error("Cannot collect metrics for the queue", zap.String("exception", err.Error()))
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(oldestWaitingDesc, prometheus.GaugeValue, oldest)
+}
+
+func (c *collector) collectServers(ch chan<- prometheus.Metric) {
+	var servers []api.Server
+	if err := c.db.Select("hostname").Find(&servers).Error; err != nil {
+		c.logger.Error("Cannot collect metrics for servers", zap.String("exception", err.Error()))
+		return
+	}
+
+	var rows []struct {
+		Hostname string
+		Amount   int64
+	}
+	if err := c.db.Model(&api.Runner{}).Scopes(datastore.NotDeleted).Select("hostname, count(*) as amount").Where("active = ?", true).Group("hostname").Scan(&rows).Error; err != nil {
+		c.logger.Error("Cannot collect metrics for servers", zap.String("exception", err.Error()))
+		return
+	}
+
+	active := make(map[string]int64)
+	for _, row := range rows {
+		active[row.Hostname] = row.Amount
+	}
+	for _, server := range servers {
+		ch <- prometheus.MustNewConstMetric(serverRunnersDesc, prometheus.GaugeValue, float64(active[server.Hostname]), server.Hostname)
+	}
+}
+
+func (c *collector) collectNms(ch chan<- prometheus.Metric) {
+	var nms []api.Nms
+	if err := c.db.Preload("Licences").Find(&nms).Error; err != nil {
+		c.logger.Error("Cannot collect metrics for nms", zap.String("exception", err.Error()))
+		return
+	}
+
+	for _, n := range nms {
+		ch <- prometheus.MustNewConstMetric(nmsWorkersDesc, prometheus.GaugeValue, float64(n.Workers), n.Address)
+		ch <- prometheus.MustNewConstMetric(nmsWorkersInUseDesc, prometheus.GaugeValue, float64(n.InUse), n.Address)
+		for _, lic := range n.Licences {
+			ch <- prometheus.MustNewConstMetric(licencesDesc, prometheus.GaugeValue, float64(lic.Amount), n.Address, lic.Type)
+			ch <- prometheus.MustNewConstMetric(licencesInUseDesc, prometheus.GaugeValue, float64(lic.InUse), n.Address, lic.Type)
+		}
+	}
+}
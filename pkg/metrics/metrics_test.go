@@ -0,0 +1,74 @@
+package metrics_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/dbtest"
+	"github.com/avian-digital-forensics/auto-processing/pkg/metrics"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func TestMetrics(t *testing.T) {
+	is := is.New(t)
+
+	db := dbtest.Open(t)
+	is.NoErr(db.AutoMigrate(&api.Runner{}, &api.Server{}, &api.Nms{}, &api.Licence{}).Error)
+
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01"}).Error)
+	is.NoErr(db.Create(&api.Server{Hostname: "dev02"}).Error)
+	is.NoErr(db.Create(&api.Runner{Name: "a", Hostname: "dev01", Active: true, Status: avian.StatusRunning}).Error)
+	is.NoErr(db.Create(&api.Runner{Name: "b", Status: avian.StatusWaiting}).Error)
+	is.NoErr(db.Create(&api.Runner{Name: "c", Status: avian.StatusWaiting}).Error)
+
+	// the runners in the trash are left out
+	deleted := time.Now().Unix()
+	is.NoErr(db.Create(&api.Runner{Base: datastore.Base{DTime: &deleted}, Name: "d", Status: avian.StatusWaiting}).Error)
+	is.NoErr(db.Create(&api.Nms{Address: "nms", Workers: 8, InUse: 2, Licences: []api.Licence{{Type: "enterprise-workstation", Amount: 2, InUse: 2}}}).Error)
+
+	m, err := metrics.New(db, zap.NewNop())
+	is.NoErr(err)
+	m.ItemsProcessed(3)
+	m.HeartbeatTimeout()
+
+	// measure a request to the api
+	handler := m.Instrument(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/oto/RunnerService.List", nil))
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	is.Equal(w.Code, http.StatusOK)
+	b, err := ioutil.ReadAll(w.Body)
+	is.NoErr(err)
+	body := string(b)
+
+	for _, line := range []string{
+		`avian_runners{status="waiting"} 2`,
+		`avian_runners{status="running"} 1`,
+		`avian_runners{status="failed"} 0`,
+		`avian_server_active_runners{server="dev01"} 1`,
+		`avian_server_active_runners{server="dev02"} 0`,
+		`avian_nms_workers{nms="nms"} 8`,
+		`avian_nms_workers_in_use{nms="nms"} 2`,
+		`avian_nms_licences{nms="nms",type="enterprise-workstation"} 2`,
+		`avian_nms_licences_in_use{nms="nms",type="enterprise-workstation"} 2`,
+		`avian_items_processed_total 3`,
+		`avian_heartbeat_timeouts_total 1`,
+		`avian_api_request_duration_seconds_count{code="418",method="RunnerService.List"} 1`,
+		`avian_queue_oldest_waiting_seconds `,
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("missing metric: %s", line)
+		}
+	}
+}
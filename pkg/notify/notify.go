@@ -0,0 +1,465 @@
+// Package notify delivers the notifications for the
+// runners to the webhooks and email-addresses of
+// the notification-rules
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+)
+
+// Triggers for the notifications
+const (
+	OnFinish       = "finish"
+	OnFailure      = "failure"
+	OnTimeout      = "timeout"
+	OnStageFailure = "stage-failure"
+)
+
+// Types of the notifications
+const (
+	TypeWebhook = "webhook"
+	TypeEmail   = "email"
+)
+
+// Statuses for the deliveries
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+const (
+	// DefaultRetries is the default amount of
+	// attempts to deliver a notification
+	DefaultRetries = 5
+
+	// DefaultBackoff is the default wait before the second
+	// attempt, it is doubled for every failed attempt
+	DefaultBackoff = 5 * time.Second
+
+	// defaultBody is the json-body for the webhooks without a template
+	defaultBody = `{"trigger":{{json .Trigger}},"runner":{{json .Runner}},"matter":{{json .Matter}},"stage":{{json .Stage}},"status":{{json .Status}},"message":{{json .Message}},"time":{{json .Time}}}`
+
+	// defaultSubject is the subject for the emails without a template
+	defaultSubject = `Avian: {{.Trigger}} for runner {{.Runner}}`
+
+	// emailBody is the body for the emails
+	emailBody = `Runner: {{.Runner}}
+{{- if .Matter}}
+Matter: {{.Matter}}{{end}}
+Trigger: {{.Trigger}}
+{{- if .Stage}}
+Stage: {{.Stage}}{{end}}
+Status: {{.Status}}
+Time: {{.Time.Format "2006-01-02 15:04:05 MST"}}
+{{- if .Message}}
+
+{{.Message}}{{end}}
+`
+)
+
+// Triggers returns the valid triggers
+func Triggers() []string {
+	return []string{OnFinish, OnFailure, OnTimeout, OnStageFailure}
+}
+
+// Event is what happened with a runner, it is
+// the data for the templates of the notifications
+type Event struct {
+	Trigger string
+	Runner  string
+	Matter  string
+	Stage   string
+	Status  string
+	Message string
+	Time    time.Time
+}
+
+// SMTP is the configuration for the email-notifications
+type SMTP struct {
+	// Address (host:port) for the smtp-server
+	Address string
+
+	// Username and Password to authenticate with,
+	// no authentication is used if they are empty
+	Username string
+	Password string
+
+	// From is the sender of the emails
+	From string
+}
+
+// Notifier delivers the notifications for the events
+type Notifier struct {
+	// Retries is the amount of attempts to deliver a notification
+	Retries int
+
+	// Backoff is the wait before the second attempt,
+	// it is doubled for every failed attempt
+	Backoff time.Duration
+
+	db      *gorm.DB
+	smtp    SMTP
+	client  *http.Client
+	logger  *zap.Logger
+	pending sync.WaitGroup
+
+	// stop is closed when the notifier is closed, the
+	// deliveries stop waiting for their next attempt
+	stop chan struct{}
+	once sync.Once
+}
+
+// New returns a new notifier
+func New(db *gorm.DB, smtp SMTP, logger *zap.Logger) *Notifier {
+	return &Notifier{
+		Retries: DefaultRetries,
+		Backoff: DefaultBackoff,
+		db:      db,
+		smtp:    smtp,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		logger:  logger,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Notify delivers the notifications for the rules that matches the
+// event, the notifications are delivered in the background
+func (n *Notifier) Notify(e Event) {
+	if n == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	logger := n.logger.With(zap.String("runner", e.Runner), zap.String("trigger", e.Trigger))
+
+	var rules []api.Notification
+	if err := n.db.Where("runner = ? OR runner = ?", "", e.Runner).Order("id").Find(&rules).Error; err != nil {
+		logger.Error("Cannot get notification-rules", zap.String("exception", err.Error()))
+		return
+	}
+
+	for _, rule := range rules {
+		if !Triggered(rule, e.Trigger) {
+			continue
+		}
+
+		event, err := json.Marshal(e)
+		if err != nil {
+			logger.Error("Cannot encode event", zap.String("exception", err.Error()))
+			return
+		}
+
+		delivery := api.Delivery{
+			NotificationID: rule.ID,
+			Notification:   rule.Name,
+			Runner:         e.Runner,
+			Trigger:        e.Trigger,
+			Status:         StatusPending,
+			Event:          string(event),
+		}
+		if err := n.db.Create(&delivery).Error; err != nil {
+			logger.Error("Cannot create delivery", zap.String("notification", rule.Name), zap.String("exception", err.Error()))
+			continue
+		}
+
+		n.pending.Add(1)
+		go n.deliver(rule, delivery, e)
+	}
+}
+
+// Resume resumes the pending deliveries, the deliveries that were
+// pending when the service stopped are delivered in the background
+func (n *Notifier) Resume() error {
+	var deliveries []api.Delivery
+	if err := n.db.Where("status = ?", StatusPending).Order("id").Find(&deliveries).Error; err != nil {
+		return fmt.Errorf("cannot get the pending deliveries: %v", err)
+	}
+
+	for _, delivery := range deliveries {
+		logger := n.logger.With(zap.String("notification", delivery.Notification), zap.String("runner", delivery.Runner))
+
+		// the deliveries without the event (from before
+		// it was stored) are resumed with what is known
+		e := Event{Trigger: delivery.Trigger, Runner: delivery.Runner, Time: time.Unix(delivery.CTime, 0)}
+		if delivery.Event != "" {
+			if err := json.Unmarshal([]byte(delivery.Event), &e); err != nil {
+				logger.Error("Cannot decode event for delivery", zap.String("exception", err.Error()))
+			}
+		}
+
+		var rule api.Notification
+		if err := n.db.First(&rule, delivery.NotificationID).Error; err != nil {
+			logger.Warn("Cannot resume delivery, the notification-rule doesn't exist", zap.String("exception", err.Error()))
+			delivery.Status = StatusFailed
+			delivery.LastError = "the notification-rule has been deleted"
+			if err := n.db.Save(&delivery).Error; err != nil {
+				logger.Error("Cannot save delivery", zap.String("exception", err.Error()))
+			}
+			continue
+		}
+
+		logger.Info("Resuming delivery", zap.Int64("attempts", delivery.Attempts))
+		n.pending.Add(1)
+		go n.deliver(rule, delivery, e)
+	}
+	return nil
+}
+
+// Close stops the deliveries from waiting for their next attempt,
+// the deliveries that are stopped are kept pending and resumed when
+// the service starts
+func (n *Notifier) Close() {
+	if n != nil {
+		n.once.Do(func() { close(n.stop) })
+	}
+}
+
+// Wait waits for the pending deliveries,
+// or until the context is done
+func (n *Notifier) Wait(ctx context.Context) error {
+	if n == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		n.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deliver sends the notification until it is delivered
+// or the retries are exhausted, every attempt is
+// saved to the delivery-log
+func (n *Notifier) deliver(rule api.Notification, delivery api.Delivery, e Event) {
+	defer n.pending.Done()
+	logger := n.logger.With(
+		zap.String("notification", rule.Name),
+		zap.String("runner", e.Runner),
+		zap.String("trigger", e.Trigger),
+	)
+
+	backoff := n.Backoff
+	for {
+		err := n.send(rule, e)
+		delivery.Attempts++
+		if err == nil {
+			delivery.Status = StatusDelivered
+			delivery.LastError = ""
+			delivery.DeliveredAt = time.Now().Unix()
+		} else {
+			logger.Warn("Failed to deliver notification", zap.Int64("attempt", delivery.Attempts), zap.String("exception", err.Error()))
+			delivery.LastError = err.Error()
+			if delivery.Attempts >= int64(n.Retries) {
+				delivery.Status = StatusFailed
+			}
+		}
+
+		if err := n.db.Save(&delivery).Error; err != nil {
+			logger.Error("Cannot save delivery", zap.String("exception", err.Error()))
+		}
+		if delivery.Status != StatusPending {
+			logger.Info("Notification "+delivery.Status, zap.Int64("attempts", delivery.Attempts))
+			return
+		}
+
+		select {
+		case <-n.stop:
+			logger.Info("Notifier closed, the delivery is resumed on startup", zap.Int64("attempts", delivery.Attempts))
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send sends the notification for the event
+func (n *Notifier) send(rule api.Notification, e Event) error {
+	switch rule.Type {
+	case TypeWebhook:
+		return n.webhook(rule, e)
+	case TypeEmail:
+		return n.email(rule, e)
+	}
+	return fmt.Errorf("invalid type: %s", rule.Type)
+}
+
+// webhook posts the json-body for the event to the endpoint
+func (n *Notifier) webhook(rule api.Notification, e Event) error {
+	body, err := Body(rule, e)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.client.Post(rule.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response from %s: %s", rule.Endpoint, resp.Status)
+	}
+	return nil
+}
+
+// email sends an email for the event to the recipients
+func (n *Notifier) email(rule api.Notification, e Event) error {
+	if n.smtp.Address == "" {
+		return fmt.Errorf("no smtp-server configured for the service")
+	}
+
+	subject, err := render("subject", rule.Subject, defaultSubject, e)
+	if err != nil {
+		return err
+	}
+	body, err := render("email", "", emailBody, e)
+	if err != nil {
+		return err
+	}
+
+	recipients := split(rule.Recipients)
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.smtp.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", strings.Join(strings.Fields(string(subject)), " "))
+	fmt.Fprintf(&msg, "Date: %s\r\n", e.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.Replace(string(body), "\n", "\r\n", -1))
+
+	var auth smtp.Auth
+	if n.smtp.Username != "" {
+		host := n.smtp.Address
+		if i := strings.LastIndex(host, ":"); i != -1 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", n.smtp.Username, n.smtp.Password, host)
+	}
+	return smtp.SendMail(n.smtp.Address, auth, n.smtp.From, recipients, msg.Bytes())
+}
+
+// Body returns the json-body of the webhook for the event
+func Body(rule api.Notification, e Event) ([]byte, error) {
+	body, err := render("body", rule.Body, defaultBody, e)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("the body isn't valid json: %s", body)
+	}
+	return body, nil
+}
+
+// Validate validates the notification-rule
+func Validate(rule api.Notification) error {
+	if rule.Name == "" {
+		return fmt.Errorf("specify name for the notification")
+	}
+
+	triggers := split(rule.Triggers)
+	if len(triggers) == 0 {
+		return fmt.Errorf("specify triggers for %s - %s", rule.Name, strings.Join(Triggers(), ", "))
+	}
+	for _, trigger := range triggers {
+		if !contains(Triggers(), trigger) {
+			return fmt.Errorf("invalid trigger for %s: %s - expected %s", rule.Name, trigger, strings.Join(Triggers(), ", "))
+		}
+	}
+
+	// render the templates with an example-event
+	// to validate them before they are used
+	e := Event{Trigger: triggers[0], Runner: "runner", Status: "Failed", Message: `an "exception"`, Time: time.Now()}
+	switch rule.Type {
+	case TypeWebhook:
+		if !strings.HasPrefix(rule.Endpoint, "http://") && !strings.HasPrefix(rule.Endpoint, "https://") {
+			return fmt.Errorf("specify endpoint for %s - an http- or https-url", rule.Name)
+		}
+		if _, err := Body(rule, e); err != nil {
+			return fmt.Errorf("invalid body for %s: %v", rule.Name, err)
+		}
+	case TypeEmail:
+		if len(split(rule.Recipients)) == 0 {
+			return fmt.Errorf("specify recipients for %s", rule.Name)
+		}
+		if _, err := render("subject", rule.Subject, defaultSubject, e); err != nil {
+			return fmt.Errorf("invalid subject for %s: %v", rule.Name, err)
+		}
+	default:
+		return fmt.Errorf("specify type for %s - %s or %s", rule.Name, TypeWebhook, TypeEmail)
+	}
+	return nil
+}
+
+// Triggered returns true if the rule has the trigger
+func Triggered(rule api.Notification, trigger string) bool {
+	return contains(split(rule.Triggers), trigger)
+}
+
+// render renders the template (or the fallback if it is empty) for the event,
+// the function json is available in the templates to quote the values
+func render(name, text, fallback string, e Event) ([]byte, error) {
+	if text == "" {
+		text = fallback
+	}
+
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, e); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// split splits the comma-separated values
+func split(values string) []string {
+	var result []string
+	for _, value := range strings.Split(values, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
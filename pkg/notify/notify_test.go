@@ -0,0 +1,177 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/dbtest"
+	"github.com/avian-digital-forensics/auto-processing/pkg/notify"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func TestNotify(t *testing.T) {
+	is := is.New(t)
+
+	db := dbtest.Open(t)
+	// the deliveries are saved from other goroutines
+	db.DB().SetMaxOpenConns(1)
+	is.NoErr(db.AutoMigrate(&api.Notification{}, &api.Delivery{}).Error)
+
+	// fail the first request to retry the delivery
+	var mu sync.Mutex
+	var bodies []map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var body map[string]string
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &body)
+		bodies = append(bodies, body)
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	rules := []api.Notification{
+		{Name: "all", Triggers: "failure,timeout", Type: notify.TypeWebhook, Endpoint: srv.URL, Body: `{"text":{{json .Runner}},"why":{{json .Message}}}`},
+		{Name: "other", Runner: "other", Triggers: "failure", Type: notify.TypeWebhook, Endpoint: srv.URL},
+		{Name: "finish", Triggers: "finish", Type: notify.TypeWebhook, Endpoint: srv.URL},
+	}
+	for _, rule := range rules {
+		is.NoErr(notify.Validate(rule))
+		is.NoErr(db.Create(&rule).Error)
+	}
+
+	n := notify.New(db, notify.SMTP{}, zap.NewNop())
+	n.Backoff = time.Millisecond
+	n.Notify(notify.Event{Trigger: notify.OnFailure, Runner: "runner", Message: `"quoted"`})
+	is.NoErr(n.Wait(context.Background()))
+
+	// only the rule for all runners with the trigger is delivered
+	var deliveries []api.Delivery
+	is.NoErr(db.Find(&deliveries).Error)
+	is.Equal(len(deliveries), 1)
+	is.Equal(deliveries[0].Notification, "all")
+	is.Equal(deliveries[0].Status, notify.StatusDelivered)
+	is.Equal(deliveries[0].Attempts, int64(2))
+	is.Equal(deliveries[0].LastError, "")
+
+	is.Equal(len(bodies), 2)
+	is.Equal(bodies[1]["text"], "runner")
+	is.Equal(bodies[1]["why"], `"quoted"`)
+
+	// the delivery fails when the retries are exhausted
+	n.Retries = 2
+	srv.Close()
+	n.Notify(notify.Event{Trigger: notify.OnFinish, Runner: "runner"})
+	is.NoErr(n.Wait(context.Background()))
+
+	var failed api.Delivery
+	is.NoErr(db.Where("notification = ?", "finish").First(&failed).Error)
+	is.Equal(failed.Status, notify.StatusFailed)
+	is.Equal(failed.Attempts, int64(2))
+	is.True(failed.LastError != "")
+}
+
+func TestResume(t *testing.T) {
+	is := is.New(t)
+
+	db := dbtest.Open(t)
+	db.DB().SetMaxOpenConns(1)
+	is.NoErr(db.AutoMigrate(&api.Notification{}, &api.Delivery{}).Error)
+
+	// fail the requests until the service has "restarted"
+	var mu sync.Mutex
+	var fail = true
+	var bodies []map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var body map[string]string
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &body)
+		bodies = append(bodies, body)
+	}))
+	defer srv.Close()
+
+	rule := api.Notification{Name: "all", Triggers: "failure", Type: notify.TypeWebhook, Endpoint: srv.URL, Body: `{"why":{{json .Message}}}`}
+	is.NoErr(db.Create(&rule).Error)
+
+	// the delivery is waiting for its next attempt when the notifier is closed
+	n := notify.New(db, notify.SMTP{}, zap.NewNop())
+	n.Backoff = time.Hour
+	n.Notify(notify.Event{Trigger: notify.OnFailure, Runner: "runner", Message: "out of memory"})
+	time.Sleep(100 * time.Millisecond)
+	n.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	is.NoErr(n.Wait(ctx))
+
+	var delivery api.Delivery
+	is.NoErr(db.First(&delivery).Error)
+	is.Equal(delivery.Status, notify.StatusPending)
+	is.Equal(delivery.Attempts, int64(1))
+
+	// a pending delivery for a deleted rule
+	is.NoErr(db.Create(&api.Delivery{NotificationID: 42, Notification: "deleted", Runner: "runner", Trigger: notify.OnFailure, Status: notify.StatusPending}).Error)
+
+	// the pending deliveries are resumed with the event on startup
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	n = notify.New(db, notify.SMTP{}, zap.NewNop())
+	is.NoErr(n.Resume())
+	is.NoErr(n.Wait(context.Background()))
+
+	is.NoErr(db.First(&delivery, delivery.ID).Error)
+	is.Equal(delivery.Status, notify.StatusDelivered)
+	is.Equal(delivery.Attempts, int64(2))
+	is.Equal(len(bodies), 1)
+	is.Equal(bodies[0]["why"], "out of memory")
+
+	var deleted api.Delivery
+	is.NoErr(db.First(&deleted, "notification = ?", "deleted").Error)
+	is.Equal(deleted.Status, notify.StatusFailed)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  api.Notification
+		valid bool
+	}{
+		{"webhook", api.Notification{Name: "a", Triggers: "finish", Type: notify.TypeWebhook, Endpoint: "https://hooks.example.com"}, true},
+		{"email", api.Notification{Name: "a", Triggers: "failure, timeout", Type: notify.TypeEmail, Recipients: "a@example.com"}, true},
+		{"no name", api.Notification{Triggers: "finish", Type: notify.TypeWebhook, Endpoint: "https://hooks.example.com"}, false},
+		{"no triggers", api.Notification{Name: "a", Type: notify.TypeWebhook, Endpoint: "https://hooks.example.com"}, false},
+		{"invalid trigger", api.Notification{Name: "a", Triggers: "started", Type: notify.TypeWebhook, Endpoint: "https://hooks.example.com"}, false},
+		{"invalid type", api.Notification{Name: "a", Triggers: "finish", Type: "sms"}, false},
+		{"invalid endpoint", api.Notification{Name: "a", Triggers: "finish", Type: notify.TypeWebhook, Endpoint: "hooks.example.com"}, false},
+		{"invalid json", api.Notification{Name: "a", Triggers: "finish", Type: notify.TypeWebhook, Endpoint: "https://hooks.example.com", Body: `{"text":{{.Runner}}}`}, false},
+		{"invalid template", api.Notification{Name: "a", Triggers: "finish", Type: notify.TypeWebhook, Endpoint: "https://hooks.example.com", Body: `{{.Runner`}, false},
+		{"no recipients", api.Notification{Name: "a", Triggers: "finish", Type: notify.TypeEmail}, false},
+	}
+
+	for _, tt := range tests {
+		err := notify.Validate(tt.rule)
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package powershell
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -58,12 +59,29 @@ func NewClient(shell ps.Shell, opts Options) (*Client, error) {
 	}, nil
 }
 
+// Quote quotes the argument as a single-quoted string for powershell,
+// the single-quotes (powershell also treats the typographic single-quotes
+// as quotes) are escaped by doubling them
+func Quote(arg string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range arg {
+		switch r {
+		case '\'', '\u2018', '\u2019', '\u201a', '\u201b':
+			b.WriteRune(r)
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
 // IsUnc checks if the path is UNC
 func IsUnc(path string) bool { return strings.HasPrefix(path, "\\\\") }
 
 // CheckPath checks if the specified path exists
 func (c *Client) CheckPath(path string) error {
-	stdout, _, err := c.Session.Execute(fmt.Sprintf("Test-Path -Path '%s'", path))
+	stdout, _, err := c.Session.Execute("Test-Path -LiteralPath " + Quote(path))
 	if strings.HasPrefix(stdout, "False") {
 		return fmt.Errorf("no such path: %s", path)
 	}
@@ -72,7 +90,7 @@ func (c *Client) CheckPath(path string) error {
 
 // CheckPathFromHost checks if the specified path exists from the host
 func (c *Client) CheckPathFromHost(path string) error {
-	stdout, _, err := c.Shell.Execute(fmt.Sprintf("Test-Path -Path '%s'", path))
+	stdout, _, err := c.Shell.Execute("Test-Path -LiteralPath " + Quote(path))
 	if strings.HasPrefix(stdout, "False") {
 		return fmt.Errorf("no such path: %s", path)
 	}
@@ -215,6 +233,67 @@ func (c *Client) Echo(arg string) (string, error) {
 	return stdout, nil
 }
 
+// FileInfo holds information for a file in the server
+type FileInfo struct {
+	Path string
+	Size int64
+	Hash string
+}
+
+// executer executes commands in a powershell-process
+type executer interface {
+	Execute(cmd string) (string, string, error)
+}
+
+// ListFiles lists all the files in the specified path recursively,
+// the SHA256-hash is calculated for every file if hash is true
+func (c *Client) ListFiles(path string, hash bool) ([]FileInfo, error) {
+	return listFiles(c.Session, path, hash)
+}
+
+// ListFilesFromHost lists all the files in the specified path from the host
+func (c *Client) ListFilesFromHost(path string, hash bool) ([]FileInfo, error) {
+	return listFiles(c.Shell, path, hash)
+}
+
+func listFiles(shell executer, path string, hash bool) ([]FileInfo, error) {
+	var hashCmd string
+	if hash {
+		hashCmd = "; Hash = (Get-FileHash -LiteralPath $_.FullName -Algorithm SHA256).Hash"
+	}
+
+	cmd := fmt.Sprintf("ConvertTo-Json -Compress -InputObject @(Get-ChildItem -LiteralPath %s -Recurse -File | ForEach-Object { [PSCustomObject]@{ Path = $_.FullName; Size = $_.Length%s } })",
+		Quote(path),
+		hashCmd,
+	)
+
+	stdout, stderr, err := shell.Execute(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if stderr != "" {
+		return nil, fmt.Errorf("stderr: %s", stderr)
+	}
+
+	var files []FileInfo
+	if err := json.Unmarshal([]byte(stdout), &files); err != nil {
+		return nil, fmt.Errorf("unable to parse files for path: %s - %v", path, err)
+	}
+	return files, nil
+}
+
+// GetContent returns the content of the specified file
+func (c *Client) GetContent(path string) (string, error) {
+	stdout, stderr, err := c.Session.Execute("Get-Content -LiteralPath " + Quote(path))
+	if err != nil {
+		return "", err
+	}
+	if stderr != "" {
+		return "", fmt.Errorf("stderr: %s", stderr)
+	}
+	return stdout, nil
+}
+
 func (c *Client) RemoveItem(path string) error {
 	stdout, stderr, err := c.Session.Execute(fmt.Sprintf("Remove-Item -Path '%s' -Force", path))
 	if stderr != "" {
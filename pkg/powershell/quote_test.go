@@ -0,0 +1,63 @@
+package powershell_test
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/powershell"
+)
+
+// recorder records the commands executed in the shell
+type recorder struct {
+	cmds   []string
+	stdout string
+}
+
+func (r *recorder) Execute(cmd string) (string, string, error) {
+	r.cmds = append(r.cmds, cmd)
+	return r.stdout, "", nil
+}
+
+func (r *recorder) Exit()  {}
+func (r *recorder) Close() {}
+
+func TestQuote(t *testing.T) {
+	is := is.New(t)
+
+	var tt = []struct {
+		arg      string
+		expected string
+	}{
+		{arg: `C:\Evidence`, expected: `'C:\Evidence'`},
+		{arg: `C:\O'Brien`, expected: `'C:\O''Brien'`},
+		{arg: `C:\x'; Remove-Item C:\ -Recurse; '`, expected: `'C:\x''; Remove-Item C:\ -Recurse; '''`},
+		{arg: "C:\\O\u2019Brien", expected: "'C:\\O\u2019\u2019Brien'"},
+	}
+
+	for _, tc := range tt {
+		is.Equal(powershell.Quote(tc.arg), tc.expected)
+	}
+}
+
+func TestListFilesQuote(t *testing.T) {
+	is := is.New(t)
+
+	shell := &recorder{stdout: `[{"Path":"C:\\O'Brien\\a.pst","Size":1}]`}
+	client := &powershell.Client{Shell: shell}
+
+	files, err := client.ListFilesFromHost(`C:\O'Brien`, false)
+	is.NoErr(err)
+	is.Equal(len(files), 1)
+	is.Equal(files[0].Path, `C:\O'Brien\a.pst`)
+
+	// the path is passed as a single escaped literal
+	is.Equal(len(shell.cmds), 1)
+	is.Equal(shell.cmds[0], `ConvertTo-Json -Compress -InputObject @(Get-ChildItem -LiteralPath 'C:\O''Brien' -Recurse -File | ForEach-Object { [PSCustomObject]@{ Path = $_.FullName; Size = $_.Length } })`)
+
+	// and the same for the content of the path-lists
+	client.Session = shell
+	_, err = client.GetContent(`C:\O'Brien\paths.txt`)
+	is.NoErr(err)
+	is.Equal(shell.cmds[1], `Get-Content -LiteralPath 'C:\O''Brien\paths.txt'`)
+}
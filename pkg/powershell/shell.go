@@ -0,0 +1,92 @@
+package powershell
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ps "github.com/simonjanss/go-powershell"
+)
+
+// Shell wraps a powershell-process to be safe for concurrent
+// use, the commands are executed one at a time
+type Shell struct {
+	mu        sync.Mutex
+	shell     ps.Shell
+	executing int32
+	closed    bool
+
+	// started is when the current command
+	// started (unix-nano), 0 if it is idle
+	started int64
+}
+
+// NewShell returns the shell for the powershell-process
+func NewShell(shell ps.Shell) *Shell {
+	return &Shell{shell: shell}
+}
+
+// Execute executes the command in the shell
+func (s *Shell) Execute(cmd string) (string, string, error) {
+	atomic.AddInt32(&s.executing, 1)
+	defer atomic.AddInt32(&s.executing, -1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return "", "", errors.New("shell is closed")
+	}
+
+	atomic.StoreInt64(&s.started, time.Now().UnixNano())
+	defer atomic.StoreInt64(&s.started, 0)
+	return s.shell.Execute(cmd)
+}
+
+// Exit exits the powershell-process
+func (s *Shell) Exit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.shell.Exit()
+}
+
+// Close closes the powershell-process
+func (s *Shell) Close() { s.Exit() }
+
+// Alive returns an error if the shell doesn't respond within the
+// timeout, a shell that is executing a command is considered alive
+// unless the command has been executing for longer than maxCommand
+func (s *Shell) Alive(timeout, maxCommand time.Duration) error {
+	if atomic.LoadInt32(&s.executing) != 0 {
+		started := atomic.LoadInt64(&s.started)
+		if started == 0 {
+			return nil
+		}
+		if since := time.Since(time.Unix(0, started)); since > maxCommand {
+			return fmt.Errorf("shell has been executing a command for %v", since.Round(time.Second))
+		}
+		return nil
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		stdout, _, err := s.Execute("echo alive")
+		if err == nil && !strings.Contains(stdout, "alive") {
+			err = fmt.Errorf("unexpected output: %s", stdout)
+		}
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("shell didn't respond within %v", timeout)
+	}
+}
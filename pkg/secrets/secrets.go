@@ -0,0 +1,432 @@
+// Package secrets encrypts the credentials that are stored in
+// the db with a master-key and resolves the references to the
+// credentials (env:<name>, file:<path> or secret:<name>) - the
+// env-references are restricted to the env-variables with the prefix
+// AVIAN_SECRET_ and the file-references to the secrets-directory
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// EnvKey is the env-variable for the master-key (base64),
+	// it is used instead of the key-file if it is set
+	EnvKey = "AVIAN_MASTER_KEY"
+
+	// EnvPrefix is the prefix for the env-variables
+	// that can be referenced with env:<name>
+	EnvPrefix = "AVIAN_SECRET_"
+
+	// DefaultDir is the default directory for the
+	// files that can be referenced with file:<path>
+	DefaultDir = "/run/secrets"
+
+	// KeySize is the size of the master-key (AES-256)
+	KeySize = 32
+
+	// Masked is the value for the credentials in the responses
+	Masked = "********"
+
+	// prefix for the encrypted values, the values
+	// without the prefix are stored in plaintext
+	prefix = "enc:v1:"
+
+	// prefixes for the references to the credentials, they
+	// are stored as they are and resolved when they are used
+	refEnv    = "env:"
+	refFile   = "file:"
+	refSecret = "secret:"
+)
+
+// denied are the env-variables that can't be referenced, even
+// if they would have the prefix (the service reads its own
+// master-key and the DSN for the db from them)
+var denied = []string{EnvKey, "AVIAN_DB_DSN"}
+
+// references restricts the files that can be referenced
+var references = struct {
+	dir     string
+	keyFile string
+}{dir: DefaultDir}
+
+// Restrict sets the directory for the file-references and the
+// key-file for the master-key that can't be referenced (even
+// if it is in the directory)
+func Restrict(dir, keyFile string) {
+	references.dir = dir
+	references.keyFile = keyFile
+}
+
+// Cipher encrypts and decrypts the credentials with the master-key
+type Cipher struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// New returns a cipher for the master-key
+func New(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid master-key - expected %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	return &Cipher{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+// ID returns the id for the master-key, the id is
+// stored with the values that are encrypted with it
+func (c *Cipher) ID() string {
+	return c.id
+}
+
+// Encrypt encrypts the value, empty values are not encrypted
+func (c *Cipher) Encrypt(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("cannot generate nonce: %v", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(value), []byte(c.id))
+	return prefix + c.id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts the value, the values
+// that aren't encrypted are returned as they are
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !Encrypted(value) {
+		return value, nil
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(value, prefix), ":", 2)
+	if len(parts) != 2 {
+		return "", errors.New("invalid encrypted value")
+	}
+	if parts[0] != c.id {
+		return "", fmt.Errorf("value is encrypted with another master-key (id: %s), the current key has id: %s", parts[0], c.id)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", errors.New("invalid encrypted value")
+	}
+	nonce, sealed := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, sealed, []byte(c.id))
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt value: %v", err)
+	}
+	return string(plain), nil
+}
+
+// Seal returns the value to store for a credential, the references
+// are stored as they are (if they can be resolved) and the
+// other values are encrypted with the master-key
+func Seal(db *gorm.DB, c *Cipher, value string) (string, error) {
+	if Reference(value) {
+		if _, err := Resolve(db, c, value); err != nil {
+			return "", err
+		}
+		return value, nil
+	}
+	return c.Encrypt(value)
+}
+
+// Resolve returns the stored credential in plaintext, the
+// encrypted values are decrypted and the references resolved
+func Resolve(db *gorm.DB, c *Cipher, value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, refEnv):
+		name := strings.TrimPrefix(value, refEnv)
+		if err := allowEnv(name); err != nil {
+			return "", fmt.Errorf("cannot resolve %s - %v", value, err)
+		}
+		resolved, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("cannot resolve %s - the env-variable isn't set", value)
+		}
+		return resolved, nil
+
+	case strings.HasPrefix(value, refFile):
+		path, err := allowFile(strings.TrimPrefix(value, refFile))
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve %s - %v", value, err)
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve %s - %v", value, err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+
+	case strings.HasPrefix(value, refSecret):
+		var secret api.Secret
+		if err := db.First(&secret, "name = ?", strings.TrimPrefix(value, refSecret)).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return "", fmt.Errorf("cannot resolve %s - the secret doesn't exist", value)
+			}
+			return "", fmt.Errorf("cannot resolve %s - %v", value, err)
+		}
+		return c.Decrypt(secret.Value)
+	}
+	return c.Decrypt(value)
+}
+
+// allowEnv returns an error if the env-variable can't be referenced
+func allowEnv(name string) error {
+	for _, env := range denied {
+		if name == env {
+			return fmt.Errorf("the env-variable %s can't be referenced", name)
+		}
+	}
+	if !strings.HasPrefix(name, EnvPrefix) || name == EnvPrefix {
+		return fmt.Errorf("only the env-variables with the prefix %s can be referenced", EnvPrefix)
+	}
+	return nil
+}
+
+// allowFile returns the path to the referenced file, the relative
+// paths are in the secrets-directory - an error is returned if the
+// file (with the symlinks resolved) isn't in the directory or
+// if it is the key-file for the master-key
+func allowFile(path string) (string, error) {
+	if references.dir == "" {
+		return "", errors.New("the file-references are disabled")
+	}
+	dir, err := filepath.Abs(references.dir)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	// the symlinks are resolved, so they can't point out of the directory
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("only the files in %s can be referenced", references.dir)
+	}
+
+	if references.keyFile != "" {
+		if keyFile, err := filepath.EvalSymlinks(references.keyFile); err == nil {
+			if keyFile, err = filepath.Abs(keyFile); err == nil && keyFile == path {
+				return "", errors.New("the master-key can't be referenced")
+			}
+		}
+	}
+	return path, nil
+}
+
+// Reference returns true if the value is a reference to
+// an env-variable, a file or a named secret
+func Reference(value string) bool {
+	for _, prefix := range []string{refEnv, refFile, refSecret} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretReference returns the reference to the named secret
+func SecretReference(name string) string {
+	return refSecret + name
+}
+
+// Match returns true if the stored credential
+// matches the value in plaintext
+func Match(db *gorm.DB, c *Cipher, stored, value string) (bool, error) {
+	plain, err := Resolve(db, c, stored)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare([]byte(plain), []byte(value)) == 1, nil
+}
+
+// Mask returns the masked value for the responses, the
+// references are returned as they are and the empty values
+// are kept empty
+func Mask(value string) string {
+	if value == "" || Reference(value) {
+		return value
+	}
+	return Masked
+}
+
+// Encrypted returns true if the value is encrypted
+func Encrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// NewKey returns a new random master-key
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("cannot generate master-key: %v", err)
+	}
+	return key, nil
+}
+
+// Encode returns the master-key encoded as base64
+func Encode(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// Decode returns the master-key from base64
+func Decode(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid master-key - expected base64: %v", err)
+	}
+	return key, nil
+}
+
+// LoadKey returns the master-key from the env-variable or the key-file
+func LoadKey(path string) ([]byte, error) {
+	if value := os.Getenv(EnvKey); value != "" {
+		return Decode(value)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(string(b))
+}
+
+// WriteKey writes the master-key to the key-file,
+// the file is only readable by the owner
+func WriteKey(path string, key []byte) error {
+	if err := ioutil.WriteFile(path, []byte(Encode(key)+"\n"), 0600); err != nil {
+		return fmt.Errorf("cannot write master-key: %v", err)
+	}
+	return nil
+}
+
+// EncryptStored encrypts the credentials that are
+// stored in plaintext, it returns the amount encrypted
+func EncryptStored(db *gorm.DB, c *Cipher) (int, error) {
+	return update(db, func(value string) (string, bool, error) {
+		if value == "" || Encrypted(value) || Reference(value) {
+			return value, false, nil
+		}
+		encrypted, err := c.Encrypt(value)
+		return encrypted, true, err
+	})
+}
+
+// Rotate re-encrypts the stored credentials with the
+// new master-key, it returns the amount re-encrypted
+func Rotate(db *gorm.DB, from, to *Cipher) (int, error) {
+	return update(db, func(value string) (string, bool, error) {
+		if value == "" || Reference(value) {
+			return value, false, nil
+		}
+		plain, err := from.Decrypt(value)
+		if err != nil {
+			return "", false, err
+		}
+		encrypted, err := to.Encrypt(plain)
+		return encrypted, true, err
+	})
+}
+
+// CountEncrypted returns the amount of stored credentials
+// that are encrypted (with any master-key)
+func CountEncrypted(db *gorm.DB) (int, error) {
+	var total int
+	for _, credential := range credentials {
+		var count int
+		if err := db.Model(credential.table).Where(credential.column+" LIKE ?", prefix+"%").Count(&count).Error; err != nil {
+			return 0, fmt.Errorf("cannot count the encrypted credentials: %v", err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// credentials are the tables and columns for the stored credentials
+// (and the secrets of the api-tokens)
+var credentials = []struct {
+	table  interface{}
+	column string
+}{
+	{&api.Server{}, "password"},
+	{&api.Nms{}, "password"},
+	{&api.Secret{}, "value"},
+	{&api.Token{}, "secret"},
+}
+
+// update updates the stored credentials with fn in a
+// transaction, fn returns the new value and if the
+// value should be updated
+func update(db *gorm.DB, fn func(value string) (string, bool, error)) (int, error) {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+
+	var updated int
+	for _, credential := range credentials {
+		var rows []struct {
+			ID    uint
+			Value string
+		}
+		if err := tx.Model(credential.table).Select("id, " + credential.column + " AS value").Scan(&rows).Error; err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("cannot get the credentials: %v", err)
+		}
+
+		for _, row := range rows {
+			value, ok, err := fn(row.Value)
+			if err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+			if !ok {
+				continue
+			}
+			if err := tx.Model(credential.table).Where("id = ?", row.ID).UpdateColumn(credential.column, value).Error; err != nil {
+				tx.Rollback()
+				return 0, fmt.Errorf("cannot update the credentials: %v", err)
+			}
+			updated++
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,217 @@
+package secrets_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/dbtest"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"github.com/matryer/is"
+)
+
+func newCipher(is *is.I) *secrets.Cipher {
+	key, err := secrets.NewKey()
+	is.NoErr(err)
+	c, err := secrets.New(key)
+	is.NoErr(err)
+	return c
+}
+
+func TestCipher(t *testing.T) {
+	is := is.New(t)
+	c := newCipher(is)
+
+	encrypted, err := c.Encrypt("hunter2")
+	is.NoErr(err)
+	is.True(secrets.Encrypted(encrypted))
+	is.True(!strings.Contains(encrypted, "hunter2"))
+
+	plain, err := c.Decrypt(encrypted)
+	is.NoErr(err)
+	is.Equal(plain, "hunter2")
+
+	match, err := secrets.Match(nil, c, encrypted, "hunter2")
+	is.NoErr(err)
+	is.True(match)
+	match, err = secrets.Match(nil, c, encrypted, "hunter3")
+	is.NoErr(err)
+	is.True(!match)
+
+	is.Equal(secrets.Mask(encrypted), secrets.Masked)
+	is.Equal(secrets.Mask(""), "")
+
+	// the values in plaintext are returned as they are
+	plain, err = c.Decrypt("legacy")
+	is.NoErr(err)
+	is.Equal(plain, "legacy")
+
+	empty, err := c.Encrypt("")
+	is.NoErr(err)
+	is.Equal(empty, "")
+
+	// the value can't be decrypted with another key
+	_, err = newCipher(is).Decrypt(encrypted)
+	is.True(err != nil)
+
+	_, err = secrets.New([]byte("short"))
+	is.True(err != nil)
+}
+
+func TestRotate(t *testing.T) {
+	is := is.New(t)
+
+	db := dbtest.Open(t)
+	is.NoErr(db.AutoMigrate(&api.Server{}, &api.Nms{}, &api.Secret{}, &api.Token{}).Error)
+
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01", Password: "server-pw"}).Error)
+	is.NoErr(db.Create(&api.Server{Hostname: "dev02"}).Error)
+	is.NoErr(db.Create(&api.Nms{Address: "nms", Password: "nms-pw"}).Error)
+	is.NoErr(db.Create(&api.Token{Key: "cli", Secret: "token-secret"}).Error)
+
+	count, err := secrets.CountEncrypted(db)
+	is.NoErr(err)
+	is.Equal(count, 0)
+
+	// the plaintext credentials are encrypted once
+	old := newCipher(is)
+	encrypted, err := secrets.EncryptStored(db, old)
+	is.NoErr(err)
+	is.Equal(encrypted, 3)
+	count, err = secrets.CountEncrypted(db)
+	is.NoErr(err)
+	is.Equal(count, 3)
+	encrypted, err = secrets.EncryptStored(db, old)
+	is.NoErr(err)
+	is.Equal(encrypted, 0)
+
+	next := newCipher(is)
+	rotated, err := secrets.Rotate(db, old, next)
+	is.NoErr(err)
+	is.Equal(rotated, 3)
+
+	var server api.Server
+	is.NoErr(db.First(&server, "hostname = ?", "dev01").Error)
+	_, err = old.Decrypt(server.Password)
+	is.True(err != nil) // encrypted with the new key
+	password, err := next.Decrypt(server.Password)
+	is.NoErr(err)
+	is.Equal(password, "server-pw")
+
+	var nms api.Nms
+	is.NoErr(db.First(&nms).Error)
+	password, err = next.Decrypt(nms.Password)
+	is.NoErr(err)
+	is.Equal(password, "nms-pw")
+
+	var token api.Token
+	is.NoErr(db.First(&token).Error)
+	secret, err := next.Decrypt(token.Secret)
+	is.NoErr(err)
+	is.Equal(secret, "token-secret")
+
+	// the credentials are left as they are if the rotation fails
+	_, err = secrets.Rotate(db, old, newCipher(is))
+	is.True(err != nil)
+	is.NoErr(db.First(&nms).Error)
+	password, err = next.Decrypt(nms.Password)
+	is.NoErr(err)
+	is.Equal(password, "nms-pw")
+}
+
+func TestResolve(t *testing.T) {
+	is := is.New(t)
+	c := newCipher(is)
+
+	db := dbtest.Open(t)
+	is.NoErr(db.AutoMigrate(&api.Secret{}).Error)
+
+	value, err := c.Encrypt("secret-pw")
+	is.NoErr(err)
+	is.NoErr(db.Create(&api.Secret{Name: "nms", Value: value}).Error)
+
+	os.Setenv("AVIAN_SECRET_TEST_PASSWORD", "env-pw")
+	defer os.Unsetenv("AVIAN_SECRET_TEST_PASSWORD")
+
+	dir, err := ioutil.TempDir("", "secrets")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "nms")
+	is.NoErr(ioutil.WriteFile(path, []byte("file-pw\n"), 0600))
+	keyFile := filepath.Join(dir, "avian-master.key")
+	is.NoErr(ioutil.WriteFile(keyFile, []byte("master-key\n"), 0600))
+	secrets.Restrict(dir, keyFile)
+	defer secrets.Restrict(secrets.DefaultDir, "")
+
+	for reference, expected := range map[string]string{
+		"env:AVIAN_SECRET_TEST_PASSWORD": "env-pw",
+		"file:" + path:                   "file-pw",
+		"file:nms":                       "file-pw",
+		secrets.SecretReference("nms"):   "secret-pw",
+	} {
+		is.True(secrets.Reference(reference))
+		is.Equal(secrets.Mask(reference), reference)
+
+		plain, err := secrets.Resolve(db, c, reference)
+		is.NoErr(err)
+		is.Equal(plain, expected)
+
+		// the references are stored as they are
+		sealed, err := secrets.Seal(db, c, reference)
+		is.NoErr(err)
+		is.Equal(sealed, reference)
+
+		match, err := secrets.Match(db, c, reference, expected)
+		is.NoErr(err)
+		is.True(match)
+	}
+
+	// the references must be resolvable to be stored
+	for _, reference := range []string{
+		"env:AVIAN_SECRET_MISSING",
+		"file:" + filepath.Join(dir, "missing"),
+		secrets.SecretReference("missing"),
+	} {
+		_, err := secrets.Seal(db, c, reference)
+		is.True(err != nil)
+	}
+
+	// the references to the credentials of the service, and to the
+	// env-variables and files outside of the allow-list, are rejected
+	os.Setenv(secrets.EnvKey, "master-key")
+	defer os.Unsetenv(secrets.EnvKey)
+	os.Setenv("AVIAN_DB_DSN", "avian:secret@tcp(db01:3306)/avian")
+	defer os.Unsetenv("AVIAN_DB_DSN")
+
+	outside := filepath.Join(os.TempDir(), filepath.Base(dir)+"-outside")
+	is.NoErr(ioutil.WriteFile(outside, []byte("outside-pw\n"), 0600))
+	defer os.Remove(outside)
+	link := filepath.Join(dir, "link")
+	is.NoErr(os.Symlink(outside, link))
+
+	for _, reference := range []string{
+		"env:" + secrets.EnvKey,
+		"env:AVIAN_DB_DSN",
+		"env:PATH",
+		"env:" + secrets.EnvPrefix,
+		"file:" + keyFile,
+		"file:avian-master.key",
+		"file:" + outside,
+		"file:../" + filepath.Base(outside),
+		"file:link",
+		"file:" + dir,
+	} {
+		_, err := secrets.Seal(db, c, reference)
+		is.True(err != nil)
+		_, err = secrets.Resolve(db, c, reference)
+		is.True(err != nil)
+	}
+
+	// the other values are encrypted
+	sealed, err := secrets.Seal(db, c, "plain-pw")
+	is.NoErr(err)
+	is.True(secrets.Encrypted(sealed))
+}
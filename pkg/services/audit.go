@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
+	"go.uber.org/zap"
+
+	"github.com/jinzhu/gorm"
+)
+
+type AuditService struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewAuditService(db *gorm.DB, logger *zap.Logger) AuditService {
+	return AuditService{db: db, logger: logger}
+}
+
+// List returns the entries from the audit-log with the latest
+// first, the entries are recorded by the audit-handler
+func (s AuditService) List(ctx context.Context, r api.AuditListRequest) (*api.AuditListResponse, error) {
+	p, err := newPage(r.Limit, r.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.Order("id desc")
+	if r.Since != "" {
+		since, err := utils.ParseSince(r.Since, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("c_time >= ?", since.Unix())
+	}
+	if r.Actor != "" {
+		query = query.Where("actor = ?", r.Actor)
+	}
+
+	var entries []api.AuditEntry
+	if err := p.apply(query).Find(&entries).Error; err != nil {
+		s.logger.Error("Cannot list the audit-log", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot list the audit-log: %v", err)
+	}
+
+	next, n := p.next(len(entries))
+	return &api.AuditListResponse{Entries: entries[:n], NextPageToken: next}, nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// defaultLimit is the page-size if the limit isn't specified
+	defaultLimit = 100
+
+	// maxLimit is the max page-size
+	maxLimit = 1000
+)
+
+// page holds the limit and offset for a list-request
+type page struct {
+	limit  int64
+	offset int64
+}
+
+// newPage returns the page for the limit and the page-token
+func newPage(limit int64, token string) (page, error) {
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	p := page{limit: limit}
+	if token == "" {
+		return p, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return p, fmt.Errorf("invalid page-token: %s", token)
+	}
+	p.offset, err = strconv.ParseInt(string(b), 10, 64)
+	if err != nil || p.offset < 0 {
+		return p, fmt.Errorf("invalid page-token: %s", token)
+	}
+	return p, nil
+}
+
+// apply applies the page to the query, one extra row
+// is fetched to know if there is a next page
+func (p page) apply(query *gorm.DB) *gorm.DB {
+	return query.Offset(p.offset).Limit(p.limit + 1)
+}
+
+// next returns the token for the next page from the amount of rows
+// fetched with the query, and the amount of rows to return
+func (p page) next(rows int) (string, int) {
+	if int64(rows) <= p.limit {
+		return "", rows
+	}
+	offset := strconv.FormatInt(p.offset+p.limit, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(offset)), int(p.limit)
+}
+
+// sortOrder returns the order for the sort-field, columns maps the
+// fields to the db-columns, the order is by id if the field is empty
+func sortOrder(field string, columns map[string]string) (string, error) {
+	direction := "asc"
+	if strings.HasPrefix(field, "-") {
+		direction = "desc"
+		field = field[1:]
+	}
+	if field == "" {
+		field = "id"
+	}
+
+	column, ok := columns[strings.ToLower(field)]
+	if !ok {
+		var fields []string
+		for f := range columns {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+		return "", fmt.Errorf("invalid sort: %s - expected one of: %s", field, strings.Join(fields, ", "))
+	}
+
+	// order by the id as well for a stable order between the pages
+	if column == "id" {
+		return "id " + direction, nil
+	}
+	return fmt.Sprintf("%s %s, id %s", column, direction, direction), nil
+}
+
+// like returns the glob-pattern (* and ?) as a pattern for LIKE,
+// the wildcards for LIKE in the pattern are escaped with \
+func like(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteByte('%')
+		case '?':
+			b.WriteByte('_')
+		case '%', '_', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// likeCondition returns the condition for the column to match the pattern
+// from like, the escape-character (\) is quoted for the dialect and the
+// matching is case-insensitive like for sqlite (and the default
+// collation for mysql)
+func likeCondition(db *gorm.DB, column string) string {
+	switch db.Dialect().GetName() {
+	case datastore.DriverPostgres:
+		return column + ` ILIKE ? ESCAPE '\'`
+	case datastore.DriverMySQL:
+		// the backslashes are escaped in the string-literals for mysql
+		return column + ` LIKE ? ESCAPE '\\'`
+	}
+	return column + ` LIKE ? ESCAPE '\'`
+}
+
+// parseStatus returns the status for the name of a status
+func parseStatus(name string) (int64, error) {
+	for _, status := range []int64{
+		avian.StatusWaiting,
+		avian.StatusRunning,
+		avian.StatusFailed,
+		avian.StatusFinished,
+		avian.StatusTimeout,
+	} {
+		if strings.EqualFold(name, avian.Status(status)) {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid status: %s - expected waiting, running, failed, finished or timeout", name)
+}
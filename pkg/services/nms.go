@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
 	"go.uber.org/zap"
 
 	"github.com/jinzhu/gorm"
@@ -14,10 +17,11 @@ import (
 type NmsService struct {
 	db     *gorm.DB
 	logger *zap.Logger
+	cipher *secrets.Cipher
 }
 
-func NewNmsService(db *gorm.DB, logger *zap.Logger) NmsService {
-	return NmsService{db: db, logger: logger}
+func NewNmsService(db *gorm.DB, logger *zap.Logger, cipher *secrets.Cipher) NmsService {
+	return NmsService{db: db, logger: logger, cipher: cipher}
 }
 
 func (s NmsService) Apply(ctx context.Context, r api.NmsApplyRequests) (*api.NmsApplyResponse, error) {
@@ -47,11 +51,20 @@ func (s NmsService) Apply(ctx context.Context, r api.NmsApplyRequests) (*api.Nms
 			s.logger.Debug("NMS already exists - will update", zap.String("nms", nms.Address))
 		}
 
+		// The password is encrypted with the master-key,
+		// unless it is a reference (env:, file: or secret:)
+		password, err := secrets.Seal(tx, s.cipher, nms.Password)
+		if err != nil {
+			tx.Rollback()
+			s.logger.Error("Cannot store password for nms", zap.String("nms", nms.Address), zap.String("exception", err.Error()))
+			return nil, fmt.Errorf("cannot store password for nms: %s - %v", nms.Address, err)
+		}
+
 		// Set data to the new Nms-model
 		newNms.Address = nms.Address
 		newNms.Port = nms.Port
 		newNms.Username = nms.Username
-		newNms.Password = nms.Password
+		newNms.Password = password
 		newNms.Workers = nms.Workers
 
 		// Create hash-map for the existing licences
@@ -88,6 +101,7 @@ func (s NmsService) Apply(ctx context.Context, r api.NmsApplyRequests) (*api.Nms
 		}
 
 		// Append the new nms to the response
+		newNms.Password = secrets.Mask(newNms.Password)
 		resp.Nms = append(resp.Nms, newNms)
 	}
 
@@ -102,15 +116,153 @@ func (s NmsService) Apply(ctx context.Context, r api.NmsApplyRequests) (*api.Nms
 }
 func (s NmsService) List(ctx context.Context, r api.NmsListRequest) (*api.NmsListResponse, error) {
 	s.logger.Debug("Getting NMS-list")
+	p, err := newPage(r.Limit, r.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := sortOrder(r.Sort, map[string]string{
+		"id":      "id",
+		"address": "address",
+		"created": "c_time",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.Order(order)
+	if r.Address != "" {
+		query = query.Where(likeCondition(query, "address"), like(r.Address))
+	}
+
 	var nms []api.Nms
-	if err := s.db.Preload("Licences").Find(&nms).Error; err != nil {
+	if err := p.apply(query).Preload("Licences").Find(&nms).Error; err != nil {
 		s.logger.Error("Cannot get NMS-list", zap.String("exception", err.Error()))
 		return nil, err
 	}
+
+	next, n := p.next(len(nms))
+	nms = nms[:n]
+	for i := range nms {
+		nms[i].Password = secrets.Mask(nms[i].Password)
+	}
 	s.logger.Debug("Got NMS-list", zap.Int("amount", len(nms)))
-	return &api.NmsListResponse{Nms: nms}, nil
+	return &api.NmsListResponse{Nms: nms, NextPageToken: next}, nil
 }
 
+// ListLicences returns the licences for the requested nms-server
 func (s NmsService) ListLicences(ctx context.Context, r api.NmsListLicencesRequest) (*api.NmsListLicencesResponse, error) {
-	return nil, nil
+	nms, err := s.get(s.db, r.NmsID, r.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	var licences []api.Licence
+	if err := s.db.Where("nms_id = ?", nms.ID).Order("type").Find(&licences).Error; err != nil {
+		s.logger.Error("Cannot get licences", zap.String("nms", nms.Address), zap.String("exception", err.Error()))
+		return nil, err
+	}
+	s.logger.Debug("Got licences", zap.String("nms", nms.Address), zap.Int("amount", len(licences)))
+	return &api.NmsListLicencesResponse{Licences: licences}, nil
+}
+
+// Get returns the requested nms-server
+func (s NmsService) Get(ctx context.Context, r api.NmsGetRequest) (*api.NmsGetResponse, error) {
+	nms, err := s.get(s.db.Preload("Licences"), 0, r.Address)
+	if err != nil {
+		return nil, err
+	}
+	nms.Password = secrets.Mask(nms.Password)
+	return &api.NmsGetResponse{Nms: nms}, nil
+}
+
+// VerifyPassword verifies the stored password for
+// the nms-server, it requires an admin-token
+func (s NmsService) VerifyPassword(ctx context.Context, r api.NmsVerifyPasswordRequest) (*api.NmsVerifyPasswordResponse, error) {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		s.logger.Warn("Cannot verify password for nms", zap.String("nms", r.Address), zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	nms, err := s.get(s.db, 0, r.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := secrets.Match(s.db, s.cipher, nms.Password, r.Password)
+	if err != nil {
+		s.logger.Error("Cannot resolve password for nms", zap.String("nms", r.Address), zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot resolve password for nms: %s - %v", r.Address, err)
+	}
+	s.logger.Info("Verified password for nms", zap.String("nms", r.Address), zap.Bool("match", match))
+	return &api.NmsVerifyPasswordResponse{Match: match}, nil
+}
+
+// Delete deletes the requested nms-server and its licences,
+// the nms-server can't be deleted while it is used by
+// any runners that are waiting or active
+func (s NmsService) Delete(ctx context.Context, r api.NmsDeleteRequest) (*api.NmsDeleteResponse, error) {
+	logger := s.logger.With(zap.String("nms", r.Address))
+
+	tx := s.db.Begin()
+	nms, err := s.get(tx, 0, r.Address)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	runners, err := usedByRunners(tx, "nms", nms.Address)
+	if err != nil {
+		tx.Rollback()
+		logger.Error("Cannot get the runners for the nms", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	if len(runners) != 0 {
+		tx.Rollback()
+		logger.Error("Cannot delete nms used by runners", zap.Strings("runners", runners))
+		return nil, fmt.Errorf("cannot delete nms %s - used by the waiting or active runners: %s", nms.Address, strings.Join(runners, ", "))
+	}
+
+	logger.Debug("Deleting licences for nms")
+	if err := tx.Where("nms_id = ?", nms.ID).Delete(&api.Licence{}).Error; err != nil {
+		tx.Rollback()
+		logger.Error("Cannot delete licences for nms", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	logger.Debug("Deleting nms")
+	if err := tx.Delete(&nms).Error; err != nil {
+		tx.Rollback()
+		logger.Error("Cannot delete nms", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Error("Commit failed", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	logger.Info("Deleted nms")
+	return &api.NmsDeleteResponse{}, nil
+}
+
+// get returns the nms-server by the id, or by the address if the id is zero
+func (s NmsService) get(db *gorm.DB, id uint, address string) (api.Nms, error) {
+	var nms api.Nms
+	var err error
+	if id != 0 {
+		err = db.First(&nms, id).Error
+	} else {
+		err = db.First(&nms, "address = ?", address).Error
+	}
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			if id != 0 {
+				return nms, fmt.Errorf("nms not found: %d", id)
+			}
+			return nms, fmt.Errorf("nms not found: %s", address)
+		}
+		s.logger.Error("Cannot get nms", zap.String("nms", address), zap.Uint("id", id), zap.String("exception", err.Error()))
+		return nms, err
+	}
+	return nms, nil
 }
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/notify"
+	"go.uber.org/zap"
+
+	"github.com/jinzhu/gorm"
+)
+
+type NotificationService struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewNotificationService(db *gorm.DB, logger *zap.Logger) NotificationService {
+	return NotificationService{db: db, logger: logger}
+}
+
+// Apply creates or updates the notification-rules (by name)
+func (s NotificationService) Apply(ctx context.Context, r api.NotificationApplyRequests) (*api.NotificationApplyResponse, error) {
+	tx := s.db.BeginTx(ctx, nil)
+
+	var resp api.NotificationApplyResponse
+	for _, req := range r.Notifications {
+		logger := s.logger.With(zap.String("notification", req.Name))
+
+		var rule api.Notification
+		if err := tx.Where("name = ?", req.Name).First(&rule).Error; err != nil {
+			if !gorm.IsRecordNotFoundError(err) {
+				tx.Rollback()
+				logger.Error("Cannot get notification", zap.String("exception", err.Error()))
+				return nil, err
+			}
+		}
+
+		rule.Name = req.Name
+		rule.Runner = req.Runner
+		rule.Triggers = strings.Join(req.Triggers, ",")
+		rule.Type = req.Type
+		rule.Endpoint = req.Endpoint
+		rule.Body = req.Body
+		rule.Recipients = strings.Join(req.Recipients, ",")
+		rule.Subject = req.Subject
+		if err := notify.Validate(rule); err != nil {
+			tx.Rollback()
+			logger.Error("Validation failed for notification", zap.String("exception", err.Error()))
+			return nil, err
+		}
+
+		if err := tx.Save(&rule).Error; err != nil {
+			tx.Rollback()
+			logger.Error("Cannot save notification - rolling back transaction", zap.String("exception", err.Error()))
+			return nil, fmt.Errorf("failed to apply notification %s : %v", rule.Name, err)
+		}
+		logger.Info("Applied notification")
+		resp.Notifications = append(resp.Notifications, rule)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		s.logger.Error("Commit failed", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (s NotificationService) List(ctx context.Context, r api.NotificationListRequest) (*api.NotificationListResponse, error) {
+	var rules []api.Notification
+	if err := s.db.Order("name").Find(&rules).Error; err != nil {
+		s.logger.Error("Cannot list notifications", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot list notifications: %v", err)
+	}
+	return &api.NotificationListResponse{Notifications: rules}, nil
+}
+
+func (s NotificationService) Delete(ctx context.Context, r api.NotificationDeleteRequest) (*api.NotificationDeleteResponse, error) {
+	logger := s.logger.With(zap.String("notification", r.Name))
+	var rule api.Notification
+	if err := s.db.Where("name = ?", r.Name).First(&rule).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("notification not found: %s", r.Name)
+		}
+		logger.Error("Cannot get notification", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	if err := s.db.Delete(&rule).Error; err != nil {
+		logger.Error("Cannot delete notification", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot delete notification: %v", err)
+	}
+	logger.Info("Deleted notification")
+	return &api.NotificationDeleteResponse{}, nil
+}
+
+// Deliveries returns the log of the deliveries with the latest first
+func (s NotificationService) Deliveries(ctx context.Context, r api.NotificationDeliveriesRequest) (*api.NotificationDeliveriesResponse, error) {
+	p, err := newPage(r.Limit, r.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.Order("id desc")
+	if r.Notification != "" {
+		query = query.Where("notification = ?", r.Notification)
+	}
+	if r.Runner != "" {
+		query = query.Where("runner = ?", r.Runner)
+	}
+	if r.Status != "" {
+		query = query.Where("status = ?", strings.ToLower(r.Status))
+	}
+
+	var deliveries []api.Delivery
+	if err := p.apply(query).Find(&deliveries).Error; err != nil {
+		s.logger.Error("Cannot list deliveries", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot list deliveries: %v", err)
+	}
+
+	next, n := p.next(len(deliveries))
+	return &api.NotificationDeliveriesResponse{Deliveries: deliveries[:n], NextPageToken: next}, nil
+}
@@ -5,12 +5,22 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/avian-digital-forensics/auto-processing/generate/script"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
 	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
+	"github.com/avian-digital-forensics/auto-processing/pkg/events"
 	"github.com/avian-digital-forensics/auto-processing/pkg/logging"
+	"github.com/avian-digital-forensics/auto-processing/pkg/manifest"
+	"github.com/avian-digital-forensics/auto-processing/pkg/metrics"
+	"github.com/avian-digital-forensics/auto-processing/pkg/notify"
 	"github.com/avian-digital-forensics/auto-processing/pkg/powershell"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
 	ps "github.com/simonjanss/go-powershell"
 
 	"github.com/jinzhu/gorm"
@@ -22,16 +32,30 @@ import (
 type RunnerService struct {
 	DB         *gorm.DB
 	shell      ps.Shell
+	uri        string
+	ca         string
 	logger     *zap.Logger
 	logHandler logging.Service
+	events     *events.Broker
+	notifier   *notify.Notifier
+	cipher     *secrets.Cipher
+	metrics    *metrics.Metrics
+	manifests  *manifest.Builder
 }
 
-func NewRunnerService(db *gorm.DB, shell ps.Shell, logger *zap.Logger, logHandler logging.Service) RunnerService {
+func NewRunnerService(db *gorm.DB, shell ps.Shell, uri, ca string, logger *zap.Logger, logHandler logging.Service, broker *events.Broker, notifier *notify.Notifier, cipher *secrets.Cipher, m *metrics.Metrics, manifests *manifest.Builder) RunnerService {
 	return RunnerService{
 		DB:         db,
 		shell:      shell,
+		uri:        uri,
+		ca:         ca,
 		logger:     logger,
 		logHandler: logHandler,
+		events:     broker,
+		notifier:   notifier,
+		cipher:     cipher,
+		metrics:    m,
+		manifests:  manifests,
 	}
 }
 
@@ -50,24 +74,7 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 
 	logger.Debug("Creating runner")
 	// Create the requested runner
-
-	// add the switches
-	var switches []*api.NuixSwitch
-	for _, nuixSwitch := range r.Switches {
-		switches = append(switches, &api.NuixSwitch{Value: nuixSwitch})
-	}
-
-	runner := api.Runner{
-		Name:         r.Name,
-		Hostname:     r.Hostname,
-		Nms:          r.Nms,
-		Licence:      r.Licence,
-		Xmx:          r.Xmx,
-		Workers:      r.Workers,
-		CaseSettings: r.CaseSettings,
-		Stages:       r.Stages,
-		Switches:     switches,
-	}
+	runner := newRunner(r)
 
 	// Validate the runner
 	logger.Info("Validating runner")
@@ -75,6 +82,11 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 		logger.Error("Validation failed for runner", zap.String("exception", err.Error()))
 		return nil, err
 	}
+
+	if _, err := script.New(runner.Engine); err != nil {
+		logger.Error("Validation failed for runner", zap.String("exception", err.Error()))
+		return nil, err
+	}
 	logger.Debug("Validation OK")
 
 	logger.Info("Looking if runner already exists")
@@ -86,8 +98,21 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 		}
 	}
 
-	// Create transaction for deleting and creating stages
+	// the names of the runners in the trash are still taken
+	if fromDB.DTime != nil {
+		logger.Error("Runner with the same name is in the trash")
+		return nil, fmt.Errorf("runner: %s is in the trash, restore it by command: 'avian runners restore %s' or purge it", runner.Name, runner.Name)
+	}
+
+	// Create transaction for deleting and creating stages,
+	// it is rolled back on every return before the commit
 	tx := s.DB.Begin()
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
 
 	if fromDB.ID != 0 {
 		if !r.Update {
@@ -120,8 +145,12 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 
 			if stage.Process != nil {
 				for _, evidence := range stage.Process.EvidenceStore {
+					if err := deleteEvidenceSources(tx, evidence); err != nil {
+						logger.Error("Failed to delete sources for evidence", zap.String("exception", err.Error()))
+						return nil, fmt.Errorf("failed to delete sources for evidence: %v", err)
+					}
+
 					if err := tx.Delete(&evidence).Error; err != nil {
-						tx.Rollback()
 						logger.Error("Failed to delete evidence", zap.String("exception", err.Error()))
 						return nil, fmt.Errorf("failed to delete evidence: %v", err)
 					}
@@ -129,7 +158,6 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 			}
 
 			if err := tx.Delete(&stage).Error; err != nil {
-				tx.Rollback()
 				logger.Error("Failed to delete stage", zap.String("stage", avian.Name(stage)), zap.String("exception", err.Error()))
 				return nil, fmt.Errorf("failed to delete stage: %s - %v", avian.Name(stage), err)
 			}
@@ -163,7 +191,12 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 	if len(server.Username) != 0 {
 		logger.Debug("Adding credentials for powershell-session")
 		opts.Username = server.Username
-		opts.Password = server.Password
+		password, err := secrets.Resolve(s.DB, s.cipher, server.Password)
+		if err != nil {
+			logger.Error("Cannot resolve password for server", zap.String("exception", err.Error()))
+			return nil, fmt.Errorf("cannot resolve password for server: %s - %v", server.Hostname, err)
+		}
+		opts.Password = password
 	}
 
 	// create the client
@@ -191,20 +224,44 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 		}
 	}
 
+	// check that the paths listed in the path-lists
+	// for the evidence exists in the server
+	logger.Info("Validating path-lists for runner")
+	for _, stage := range runner.Stages {
+		if stage.Process == nil {
+			continue
+		}
+		for _, evidence := range stage.Process.EvidenceStore {
+			if len(evidence.PathList) == 0 {
+				continue
+			}
+			if err := checkPathList(client, evidence.PathList); err != nil {
+				logger.Error("Failed to validate path-list", zap.String("path_list", evidence.PathList), zap.String("exception", err.Error()))
+				return nil, fmt.Errorf("path-list: %s - err : %v", evidence.PathList, err)
+			}
+		}
+	}
+
 	// Add the runner to the db
 	logger.Info("Saving runner to DB")
 	runner.Status = avian.StatusWaiting
 	if err := tx.Save(&runner).Error; err != nil {
-		tx.Rollback()
 		logger.Error("Cannot to save runner to DB", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("failed to create runner: %v", err)
 	}
 
 	if err := tx.Commit().Error; err != nil {
-		tx.Rollback()
 		logger.Error("Cannot commit transaction to DB", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("failed to create runner: %v", err)
 	}
+	committed = true
+
+	// Record the manifest for the evidence in the background, the
+	// previous manifests are kept for the chain of custody and the
+	// latest manifest is the one the evidence is verified against
+	if manifest.Enabled(runner) {
+		s.manifests.Go(runner, server, manifest.PhaseApply, false)
+	}
 
 	logger.Info("Runner has been created")
 	return &api.RunnerApplyResponse{Runner: runner}, nil
@@ -212,8 +269,54 @@ func (s RunnerService) Apply(ctx context.Context, r api.RunnerApplyRequest) (*ap
 
 func (s RunnerService) List(ctx context.Context, r api.RunnerListRequest) (*api.RunnerListResponse, error) {
 	s.logger.Debug("Getting runners-list")
+	p, err := newPage(r.Limit, r.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := sortOrder(r.Sort, map[string]string{
+		"id":       "id",
+		"name":     "name",
+		"created":  "c_time",
+		"status":   "status",
+		"hostname": "hostname",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.DB.Model(&api.Runner{}).Scopes(datastore.NotDeleted)
+	if r.Status != "" {
+		status, err := parseStatus(r.Status)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("status = ?", status)
+	}
+	if r.Hostname != "" {
+		query = query.Where("hostname = ?", r.Hostname)
+	}
+	if r.Nms != "" {
+		query = query.Where("nms = ?", r.Nms)
+	}
+	if r.Licence != "" {
+		query = query.Where("licence = ?", r.Licence)
+	}
+	if r.Name != "" {
+		query = query.Where(likeCondition(query, "name"), like(r.Name))
+	}
+	if r.CreatedAfter != 0 {
+		query = query.Where("c_time >= ?", r.CreatedAfter)
+	}
+	if r.CreatedBefore != 0 {
+		query = query.Where("c_time < ?", r.CreatedBefore)
+	}
+
+	// only the stages are preloaded (for the current stage
+	// in the list-view) and only for the runners in the page
 	var runners []api.Runner
-	err := s.DB.Preload("Stages.Process").
+	err = p.apply(query.Order(order)).
+		Preload("Stages.Process").
 		Preload("Stages.SearchAndTag").
 		Preload("Stages.Exclude").
 		Preload("Stages.Ocr").
@@ -224,14 +327,18 @@ func (s RunnerService) List(ctx context.Context, r api.RunnerListRequest) (*api.
 		s.logger.Error("Cannot get runners-list", zap.String("exception", err.Error()))
 		return nil, err
 	}
+
+	next, n := p.next(len(runners))
+	runners = runners[:n]
 	s.logger.Debug("Got Runners-list", zap.Int("amount", len(runners)))
-	return &api.RunnerListResponse{Runners: runners}, nil
+	return &api.RunnerListResponse{Runners: runners, NextPageToken: next}, nil
 }
 
 func (s RunnerService) Get(ctx context.Context, r api.RunnerGetRequest) (*api.RunnerGetResponse, error) {
 	s.logger.Debug("Getting runner", zap.String("runner", r.Name))
 	var runner api.Runner
-	err := s.DB.Preload("Stages.Process").
+	err := s.DB.Scopes(datastore.NotDeleted).
+		Preload("Stages.Process").
 		Preload("Stages.SearchAndTag").
 		Preload("Stages.Exclude").
 		Preload("Stages.Ocr").
@@ -249,6 +356,78 @@ func (s RunnerService) Get(ctx context.Context, r api.RunnerGetRequest) (*api.Ru
 	return &api.RunnerGetResponse{Runner: runner}, nil
 }
 
+// Manifest returns the evidence-manifests for the runner
+func (s RunnerService) Manifest(ctx context.Context, r api.RunnerManifestRequest) (*api.RunnerManifestResponse, error) {
+	logger := s.logger.With(zap.String("runner", r.Name))
+	logger.Debug("Getting manifests for runner")
+	var runner api.Runner
+	if err := s.DB.Scopes(datastore.NotDeleted).First(&runner, "name = ?", r.Name).Error; err != nil {
+		logger.Error("Cannot get runner", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	var manifests []api.Manifest
+	err := s.DB.Preload("Evidence.Files").
+		Preload("Changes").
+		Where("runner_id = ?", runner.ID).
+		Order("id").
+		Find(&manifests).Error
+	if err != nil {
+		logger.Error("Cannot get manifests for runner", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	logger.Debug("Returning manifests for runner", zap.Int("amount", len(manifests)))
+	return &api.RunnerManifestResponse{Manifests: manifests}, nil
+}
+
+// Script returns the generated script for the runner,
+// the finished stages are left out of the script
+func (s RunnerService) Script(ctx context.Context, r api.RunnerScriptRequest) (*api.RunnerScriptResponse, error) {
+	var runner api.Runner
+	if r.Runner != nil {
+		// Generate the script for the requested
+		// runner (that hasn't been applied)
+		runner = newRunner(*r.Runner)
+		if err := runner.Validate(); err != nil {
+			s.logger.Error("Validation failed for runner", zap.String("runner", runner.Name), zap.String("exception", err.Error()))
+			return nil, err
+		}
+	} else {
+		runner.Name = r.Name
+		if err := getPreloadedRunner(s.DB, &runner); err != nil {
+			s.logger.Error("Cannot get runner", zap.String("runner", r.Name), zap.String("exception", err.Error()))
+			return nil, fmt.Errorf("failed to get runner: %s - %v", r.Name, err)
+		}
+	}
+
+	// the engine for the server is used
+	// if the runner hasn't specified one
+	var server api.Server
+	if err := s.DB.First(&server, "hostname = ?", runner.Hostname).Error; err != nil && !gorm.IsRecordNotFoundError(err) {
+		s.logger.Error("Cannot get server for runner", zap.String("runner", runner.Name), zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("failed to get server: %s - %v", runner.Hostname, err)
+	}
+
+	engine := script.Engine(runner, server)
+	logger := s.logger.With(zap.String("runner", runner.Name), zap.String("engine", engine))
+	logger.Debug("Generating script for runner")
+	generator, err := script.New(engine)
+	if err != nil {
+		logger.Error("Cannot generate script for runner", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	code, err := generator.Generate(s.uri, s.ca, runner)
+	if err != nil {
+		logger.Error("Cannot generate script for runner", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("failed to generate script for runner: %s - %v", runner.Name, err)
+	}
+	return &api.RunnerScriptResponse{Script: code}, nil
+}
+
+// Delete moves the runner to the trash, or deletes it permanently
+// if it is purged (the runners in the trash can also be purged)
 func (s RunnerService) Delete(ctx context.Context, r api.RunnerDeleteRequest) (*api.RunnerDeleteResponse, error) {
 	s.logger.Debug("Getting runner to delete", zap.String("runner", r.Name))
 	if r.DeleteAllCases {
@@ -260,19 +439,14 @@ func (s RunnerService) Delete(ctx context.Context, r api.RunnerDeleteRequest) (*
 	// start transaction for the delete
 	tx := s.DB.Begin()
 
+	query := tx
+	if !r.Purge {
+		query = query.Scopes(datastore.NotDeleted)
+	}
+
 	var runner api.Runner
-	err := tx.Preload("Switches").
-		Preload("Stages.Process").
-		Preload("Stages.SearchAndTag").
-		Preload("Stages.Exclude").
-		Preload("Stages.Ocr").
-		Preload("Stages.Reload").
-		Preload("Stages.Populate").
-		Preload("CaseSettings.Case").
-		Preload("CaseSettings.CompoundCase").
-		Preload("CaseSettings.ReviewCompound").
-		First(&runner, "name = ? OR id = ?", r.Name, r.Name).Error
-	if err != nil {
+	condition, args := nameOrID(r.Name)
+	if err := query.Where(condition, args...).First(&runner).Error; err != nil {
 		tx.Rollback()
 		s.logger.Error("Cannot get runner", zap.String("runner", r.Name), zap.String("exception", err.Error()))
 		return nil, err
@@ -280,6 +454,7 @@ func (s RunnerService) Delete(ctx context.Context, r api.RunnerDeleteRequest) (*
 
 	// check if the runner is active
 	// unless the delete is forced
+	var stopped bool
 	if runner.Active {
 		if !r.Force {
 			tx.Rollback()
@@ -287,59 +462,299 @@ func (s RunnerService) Delete(ctx context.Context, r api.RunnerDeleteRequest) (*
 			return nil, fmt.Errorf("Cannot delete active runner - use force argument")
 		}
 
-		// set the runners server to inactive
-		if err := tx.Model(&api.Server{}).Where("hostname = ?", runner.Hostname).Update("active", false).Error; err != nil {
+		// the forced delete stops the run and releases the server, the
+		// licences and the token for the runner - unless the run has
+		// been stopped since the runner was read
+		query := tx.Model(&api.Runner{}).
+			Where("id = ? AND active = ?", runner.ID, true).
+			Updates(map[string]interface{}{"active": false, "status": avian.StatusFailed})
+		if query.Error != nil {
+			tx.Rollback()
+			s.logger.Error("Cannot stop active runner", zap.String("runner", r.Name), zap.String("exception", query.Error.Error()))
+			return nil, query.Error
+		}
+		if query.RowsAffected != 0 {
+			if err := release(tx, runner); err != nil {
+				tx.Rollback()
+				s.logger.Error("Cannot release the server and licences for runner", zap.String("runner", r.Name), zap.String("exception", err.Error()))
+				return nil, fmt.Errorf("Cannot release the server and licences for the active runner: %v", err)
+			}
+			stopped = true
+		}
+		runner.Active = false
+		runner.Status = avian.StatusFailed
+	}
+
+	if r.Purge {
+		s.logger.Debug("Purging runner", zap.String("runner", r.Name))
+		if err := purgeRunner(tx, &runner); err != nil {
+			tx.Rollback()
+			s.logger.Error("Cannot delete runner", zap.String("runner", r.Name), zap.String("exception", err.Error()))
+			return nil, err
+		}
+	} else {
+		s.logger.Debug("Moving runner to the trash", zap.String("runner", r.Name))
+		if err := tx.Model(&runner).UpdateColumn("d_time", time.Now().Unix()).Error; err != nil {
 			tx.Rollback()
-			s.logger.Error("Cannot set server to inactive",
-				zap.String("runner", r.Name),
-				zap.String("server", runner.Hostname),
-				zap.String("exception", err.Error()),
-			)
-			return nil, fmt.Errorf("Cannot set the active server to inactive: %v", err)
+			s.logger.Error("Cannot move runner to the trash", zap.String("runner", r.Name), zap.String("exception", err.Error()))
+			return nil, err
 		}
 	}
 
-	s.logger.Debug("Deleting runner", zap.String("runner", r.Name))
-	if err := tx.Delete(&runner).Error; err != nil {
+	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		s.logger.Error("Cannot delete runner", zap.String("runner", r.Name), zap.String("exception", err.Error()))
+		s.logger.Error("Cannot delete runner, failed to commit transaction", zap.String("runner", r.Name), zap.String("exception", err.Error()))
 		return nil, err
 	}
 
-	if err := tx.Model(&runner).Association("Stages").Delete(runner.Stages).Error; err != nil {
-		tx.Rollback()
-		s.logger.Error("Cannot delete runner", zap.String("runner", r.Name), zap.String("exception", err.Error()))
+	if stopped {
+		s.logger.Info("Stopped active runner for the delete", zap.String("runner", r.Name))
+		s.Publish(events.Event{Type: events.TypeRunner, Runner: runner.Name, Status: avian.Status(runner.Status)})
+	}
+	return &api.RunnerDeleteResponse{}, nil
+}
+
+// nameOrID returns the condition for a runner by its name, or by
+// its id if the name is numeric (the integer id isn't compared
+// with a string, postgres rejects a non-numeric string for it)
+func nameOrID(name string) (string, []interface{}) {
+	if id, err := strconv.ParseUint(name, 10, 64); err == nil {
+		return "name = ? OR id = ?", []interface{}{name, id}
+	}
+	return "name = ?", []interface{}{name}
+}
+
+// ListTrash returns the runners in the trash, the latest deleted first
+func (s RunnerService) ListTrash(ctx context.Context, r api.RunnerListTrashRequest) (*api.RunnerListTrashResponse, error) {
+	p, err := newPage(r.Limit, r.PageToken)
+	if err != nil {
 		return nil, err
 	}
 
-	if err := tx.Model(&runner).Association("Switches").Delete(runner.Switches).Error; err != nil {
-		tx.Rollback()
-		s.logger.Error("Cannot delete runner", zap.String("runner", r.Name), zap.String("exception", err.Error()))
+	var runners []api.Runner
+	if err := p.apply(s.DB.Scopes(datastore.Deleted).Order("d_time desc, id desc")).Find(&runners).Error; err != nil {
+		s.logger.Error("Cannot get the runners in the trash", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	next, n := p.next(len(runners))
+	return &api.RunnerListTrashResponse{Runners: runners[:n], NextPageToken: next}, nil
+}
+
+// Restore restores the runner from the trash, unless
+// a new runner with the same name has been applied
+func (s RunnerService) Restore(ctx context.Context, r api.RunnerRestoreRequest) (*api.RunnerRestoreResponse, error) {
+	logger := s.logger.With(zap.String("runner", r.Name))
+
+	tx := s.DB.Begin()
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	var runner api.Runner
+	if err := tx.Scopes(datastore.Deleted).First(&runner, "name = ?", r.Name).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("runner: %s is not in the trash", r.Name)
+		}
+		logger.Error("Cannot get runner from the trash", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	var live int
+	if err := tx.Model(&api.Runner{}).Scopes(datastore.NotDeleted).Where("name = ?", r.Name).Count(&live).Error; err != nil {
+		logger.Error("Cannot check for runners with the same name", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	if live != 0 {
+		return nil, fmt.Errorf("runner: %s already exists - delete it before restoring the runner from the trash", r.Name)
+	}
+
+	if err := tx.Model(&runner).UpdateColumn("d_time", gorm.Expr("NULL")).Error; err != nil {
+		logger.Error("Cannot restore runner from the trash", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("failed to restore runner: %v", err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		logger.Error("Cannot restore runner, failed to commit transaction", zap.String("exception", err.Error()))
 		return nil, err
 	}
+	committed = true
+	runner.DTime = nil
+
+	logger.Info("Restored runner from the trash")
+	return &api.RunnerRestoreResponse{Runner: runner}, nil
+}
 
-	if err := tx.Model(&runner).Association("CaseSettings").Delete(runner.CaseSettings).Error; err != nil {
+// Purge permanently deletes the runners that
+// have been in the trash for longer than OlderThan
+func (s RunnerService) Purge(ctx context.Context, r api.RunnerPurgeRequest) (*api.RunnerPurgeResponse, error) {
+	if r.OlderThan == "" {
+		return nil, errors.New("older-than must be specified for the runners to purge (for example 30d)")
+	}
+	olderThan, err := utils.ParseDuration(r.OlderThan)
+	if err != nil {
+		return nil, err
+	}
+	logger := s.logger.With(zap.String("older_than", r.OlderThan))
+
+	tx := s.DB.Begin()
+
+	var runners []api.Runner
+	err = tx.Scopes(datastore.Deleted).
+		Where("d_time <= ?", time.Now().Add(-olderThan).Unix()).
+		Order("d_time").
+		Find(&runners).Error
+	if err != nil {
 		tx.Rollback()
-		s.logger.Error("Cannot delete runner", zap.String("runner", r.Name), zap.String("exception", err.Error()))
+		logger.Error("Cannot get the runners to purge", zap.String("exception", err.Error()))
 		return nil, err
 	}
 
+	var purged []string
+	for i := range runners {
+		if err := purgeRunner(tx, &runners[i]); err != nil {
+			tx.Rollback()
+			logger.Error("Cannot purge runner", zap.String("runner", runners[i].Name), zap.String("exception", err.Error()))
+			return nil, fmt.Errorf("failed to purge runner: %s - %v", runners[i].Name, err)
+		}
+		purged = append(purged, runners[i].Name)
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		s.logger.Error("Cannot delete runner, failed to commit transaction", zap.String("runner", r.Name), zap.String("exception", err.Error()))
+		logger.Error("Cannot purge runners, failed to commit transaction", zap.String("exception", err.Error()))
 		return nil, err
 	}
 
-	return &api.RunnerDeleteResponse{}, nil
+	logger.Info("Purged runners from the trash", zap.Strings("runners", purged))
+	return &api.RunnerPurgeResponse{Runners: purged}, nil
+}
+
+// purgeRunner permanently deletes the runner with its stages,
+// evidence, switches, case-settings, manifests and tokens
+func purgeRunner(tx *gorm.DB, runner *api.Runner) error {
+	if err := deleteStages(tx, runner.ID); err != nil {
+		return err
+	}
+	if err := tx.Where("runner_id = ?", runner.ID).Delete(&api.NuixSwitch{}).Error; err != nil {
+		return fmt.Errorf("cannot delete the switches for runner: %v", err)
+	}
+	if err := deleteCaseSettings(tx, runner.CaseSettingsID); err != nil {
+		return err
+	}
+	if err := manifest.Delete(tx, runner.ID); err != nil {
+		return err
+	}
+	if err := auth.Revoke(tx, runner.ID); err != nil {
+		return err
+	}
+	return tx.Delete(runner).Error
+}
+
+// deleteStages deletes the stages for the runner with their
+// settings and the evidence to process, the empty lists are
+// skipped - the query would have an empty IN () for postgres and mysql
+func deleteStages(tx *gorm.DB, runnerID uint) error {
+	var stages []uint
+	if err := tx.Model(&api.Stage{}).Where("runner_id = ?", runnerID).Pluck("id", &stages).Error; err != nil {
+		return fmt.Errorf("cannot get the stages for runner: %v", err)
+	}
+	if len(stages) == 0 {
+		return nil
+	}
+
+	var processes []uint
+	if err := tx.Model(&api.Process{}).Where("stage_id IN (?)", stages).Pluck("id", &processes).Error; err != nil {
+		return fmt.Errorf("cannot get the processes for the stages: %v", err)
+	}
+	if len(processes) != 0 {
+		var evidence []api.Evidence
+		if err := tx.Where("process_id IN (?)", processes).Find(&evidence).Error; err != nil {
+			return fmt.Errorf("cannot get the evidence for the stages: %v", err)
+		}
+		for i := range evidence {
+			if err := deleteEvidenceSources(tx, &evidence[i]); err != nil {
+				return fmt.Errorf("cannot delete the sources for evidence: %s - %v", evidence[i].Name, err)
+			}
+		}
+		if err := tx.Where("process_id IN (?)", processes).Delete(&api.Evidence{}).Error; err != nil {
+			return fmt.Errorf("cannot delete the evidence for the stages: %v", err)
+		}
+	}
+
+	var searches []uint
+	if err := tx.Model(&api.SearchAndTag{}).Where("stage_id IN (?)", stages).Pluck("id", &searches).Error; err != nil {
+		return fmt.Errorf("cannot get the search and tags for the stages: %v", err)
+	}
+	if len(searches) != 0 {
+		if err := tx.Where("search_and_tag_id IN (?)", searches).Delete(&api.File{}).Error; err != nil {
+			return fmt.Errorf("cannot delete the files for the search and tags: %v", err)
+		}
+	}
+
+	var populates []uint
+	if err := tx.Model(&api.Populate{}).Where("stage_id IN (?)", stages).Pluck("id", &populates).Error; err != nil {
+		return fmt.Errorf("cannot get the populates for the stages: %v", err)
+	}
+	if len(populates) != 0 {
+		if err := tx.Where("populate_id IN (?)", populates).Delete(&api.Type{}).Error; err != nil {
+			return fmt.Errorf("cannot delete the types for the populates: %v", err)
+		}
+	}
+
+	for _, settings := range []interface{}{&api.Process{}, &api.SearchAndTag{}, &api.Populate{}, &api.Exclude{}, &api.Ocr{}, &api.Reload{}} {
+		if err := tx.Where("stage_id IN (?)", stages).Delete(settings).Error; err != nil {
+			return fmt.Errorf("cannot delete the settings for the stages: %v", err)
+		}
+	}
+	if err := tx.Where("id IN (?)", stages).Delete(&api.Stage{}).Error; err != nil {
+		return fmt.Errorf("cannot delete the stages: %v", err)
+	}
+	return nil
+}
+
+// deleteCaseSettings deletes the case-settings with their cases
+func deleteCaseSettings(tx *gorm.DB, id uint) error {
+	if id == 0 {
+		return nil
+	}
+	var settings api.CaseSettings
+	if err := tx.First(&settings, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("cannot get the case-settings for runner: %v", err)
+	}
+
+	var cases []uint
+	for _, id := range []uint{settings.CaseID, settings.CompoundCaseID, settings.ReviewCompoundID} {
+		if id != 0 {
+			cases = append(cases, id)
+		}
+	}
+	if len(cases) != 0 {
+		if err := tx.Where("id IN (?)", cases).Delete(&api.Case{}).Error; err != nil {
+			return fmt.Errorf("cannot delete the cases for runner: %v", err)
+		}
+	}
+	if err := tx.Delete(&settings).Error; err != nil {
+		return fmt.Errorf("cannot delete the case-settings for runner: %v", err)
+	}
+	return nil
 }
 
 func (s RunnerService) Start(ctx context.Context, r api.RunnerStartRequest) (*api.RunnerStartResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("runner_id", int(r.ID)))
 	logger.Info("Starting runner")
-	var runner api.Runner
-	if err := s.DB.First(&runner, r.ID).Error; err != nil {
-		logger.Error("Cannot get runner", zap.String("exception", err.Error()))
-		return nil, fmt.Errorf("cannot get runner: %v", err)
+	runner, err := s.currentRun(logger, r.RunID, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !runner.Active {
+		logger.Info("Runner has already stopped, ignoring the start")
+		return &api.RunnerStartResponse{}, nil
 	}
 	now := time.Now()
 	runner.Status = avian.StatusRunning
@@ -349,87 +764,100 @@ func (s RunnerService) Start(ctx context.Context, r api.RunnerStartRequest) (*ap
 		return nil, fmt.Errorf("cannot save runner: %v", err)
 	}
 
+	s.Publish(events.Event{Type: events.TypeRunner, Runner: runner.Name, Status: avian.Status(runner.Status)})
+
 	return &api.RunnerStartResponse{}, nil
 }
 
 func (s RunnerService) Failed(ctx context.Context, r api.RunnerFailedRequest) (*api.RunnerFailedResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("runner_id", int(r.ID)))
 	logger.Info("Failed runner")
-	var runner api.Runner
-	if err := s.DB.First(&runner, r.ID).Error; err != nil {
-		logger.Error("Cannot get runner", zap.String("exception", err.Error()))
-		return nil, fmt.Errorf("cannot get runner: %v", err)
+	runner, err := s.currentRun(logger, r.RunID, r.ID)
+	if err != nil {
+		return nil, err
 	}
-	runner.Status = avian.StatusFailed
-	runner.Active = false
-	if err := s.DB.Save(&runner).Error; err != nil {
+
+	stopped, err := s.Deactivate(&runner, avian.StatusFailed)
+	if err != nil {
 		logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("cannot save runner: %v", err)
 	}
-
-	// Set servers activity
-	if err := s.SetServerActivity(runner, false); err != nil {
-		return nil, fmt.Errorf("Failed to set servers activity: %v", err)
-	}
-
-	// update nms information
-	if err := s.ResetNms(runner); err != nil {
-		return nil, fmt.Errorf("Failed to set servers activity: %v", err)
+	if !stopped {
+		logger.Info("Runner has already stopped, ignoring the failure")
+		return &api.RunnerFailedResponse{}, nil
 	}
 
+	// the script is removed, and the events and notifications are
+	// sent, best-effort - the run has been stopped and released
 	if err := s.RemoveScript(runner); err != nil {
-		return nil, err
+		logger.Warn("Cannot remove script for runner", zap.String("exception", err.Error()))
 	}
 
+	s.Publish(events.Event{Type: events.TypeRunner, Runner: runner.Name, Status: avian.Status(runner.Status), Message: r.Exception})
+	s.Notify(notify.Event{Trigger: notify.OnFailure, Runner: runner.Name, Status: avian.Status(runner.Status), Message: r.Exception})
+
 	return &api.RunnerFailedResponse{}, nil
 }
 
 func (s RunnerService) Finish(ctx context.Context, r api.RunnerFinishRequest) (*api.RunnerFinishResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("runner_id", int(r.ID)))
 	logger.Info("Finished runner")
-
-	var runner api.Runner
-	if err := s.DB.First(&runner, r.ID).Error; err != nil {
-		logger.Error("Cannot get runner", zap.String("exception", err.Error()))
-		return nil, fmt.Errorf("cannot get runner: %v", err)
-	}
-	runner.Status = avian.StatusFinished
-	runner.Active = false
-	if err := s.DB.Save(&runner).Error; err != nil {
-		logger.Error("Cannot save the failed runner", zap.String("exception", err.Error()))
-		return nil, fmt.Errorf("cannot save runner: %v", err)
+	runner, err := s.currentRun(logger, r.RunID, r.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Set servers activity
-	if err := s.SetServerActivity(runner, false); err != nil {
-		return nil, fmt.Errorf("Failed to set servers activity: %v", err)
+	stopped, err := s.Deactivate(&runner, avian.StatusFinished)
+	if err != nil {
+		logger.Error("Cannot save the finished runner", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot save runner: %v", err)
 	}
-
-	// update nms information
-	if err := s.ResetNms(runner); err != nil {
-		return nil, fmt.Errorf("Failed to set servers activity: %v", err)
+	if !stopped {
+		logger.Info("Runner has already stopped, ignoring the finish")
+		return &api.RunnerFinishResponse{}, nil
 	}
 
+	// the script is removed, and the events and notifications are
+	// sent, best-effort - the run has been stopped and released
 	if err := s.RemoveScript(runner); err != nil {
-		return nil, err
+		logger.Warn("Cannot remove script for runner", zap.String("exception", err.Error()))
 	}
 
+	s.Publish(events.Event{Type: events.TypeRunner, Runner: runner.Name, Status: avian.Status(runner.Status)})
+	s.Notify(notify.Event{Trigger: notify.OnFinish, Runner: runner.Name, Status: avian.Status(runner.Status)})
+
+	// verify the evidence after the processing
+	s.VerifyManifest(runner)
+
 	return &api.RunnerFinishResponse{}, nil
 }
 
 func (s RunnerService) Heartbeat(ctx context.Context, r api.RunnerStartRequest) (*api.RunnerStartResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("runner_id", int(r.ID)))
 	logger.Debug("Retrieved heartbeat from runner")
+	if _, err := s.currentRun(logger, r.RunID, r.ID); err != nil {
+		return nil, err
+	}
 	if err := s.DB.Model(&api.Runner{}).Where("id = ?", r.ID).Update("healthy_at", time.Now()).Error; err != nil {
 		logger.Error("Failed to update healthy_at", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("Failed to update healthy_at: %v", err)
 	}
+
+	// extend the token for the runner-script
+	if err := auth.Extend(s.DB, r.ID); err != nil {
+		logger.Error("Failed to extend token", zap.String("exception", err.Error()))
+		return nil, err
+	}
 	return &api.RunnerStartResponse{}, nil
 }
 
 func (s RunnerService) StartStage(ctx context.Context, r api.StageRequest) (*api.StageResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("stage_id", int(r.StageID)))
 	logger.Debug("StartStage request")
+	if _, err := s.currentRun(logger, r.RunID, "name = ?", r.Runner); err != nil {
+		return nil, err
+	}
+
 	var stage api.Stage
 	if err := s.DB.Preload("Process").
 		Preload("SearchAndTag").
@@ -444,18 +872,69 @@ func (s RunnerService) StartStage(ctx context.Context, r api.StageRequest) (*api
 
 	logger.Debug("Set stage-status to running", zap.Int("stage_id", int(r.StageID)))
 	avian.SetStatusRunning(&stage)
+
+	// reset the progress for the stage
+	stage.StartedAt = time.Now().Unix()
+	stage.Total = 0
+	stage.Progress = 0
+	stage.EstimatedFinish = 0
 	if err := s.DB.Save(&stage).Error; err != nil {
 		logger.Error("Cannot set stage-status to running", zap.String("exception", err.Error()))
 		return nil, fmt.Errorf("failed to update stage to running: %v", err)
 	}
 
 	logger.Info("STARTING STAGE", zap.String("stage", avian.Name(&stage)))
+	s.Publish(events.Event{
+		Type:    events.TypeStage,
+		Runner:  r.Runner,
+		Stage:   avian.Name(&stage),
+		StageID: stage.ID,
+		Status:  avian.Status(avian.StageState(&stage)),
+	})
+	return &api.StageResponse{Stage: stage}, nil
+}
+
+// ProgressStage sets the progress for a stage and estimates
+// when it will finish from the throughput of the stage
+func (s RunnerService) ProgressStage(ctx context.Context, r api.StageProgressRequest) (*api.StageResponse, error) {
+	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("stage_id", int(r.StageID)))
+	if _, err := s.currentRun(logger, r.RunID, "name = ?", r.Runner); err != nil {
+		return nil, err
+	}
+
+	var stage api.Stage
+	if err := s.DB.First(&stage, r.StageID).Error; err != nil {
+		logger.Error("Cannot get the requested stage", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("did not get requested stage : %v", err)
+	}
+
+	stage.Total = r.Total
+	stage.Progress = r.Count
+	stage.EstimatedFinish = avian.EstimateFinish(stage.StartedAt, time.Now().Unix(), r.Total, r.Count)
+	if err := s.DB.Save(&stage).Error; err != nil {
+		logger.Error("Cannot set progress for stage", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("failed to update progress for stage: %v", err)
+	}
+
+	logger.Debug("Progress for stage", zap.Int64("total", r.Total), zap.Int64("count", r.Count))
+	s.Publish(events.Event{
+		Type:            events.TypeProgress,
+		Runner:          r.Runner,
+		StageID:         stage.ID,
+		Total:           stage.Total,
+		Progress:        stage.Progress,
+		EstimatedFinish: stage.EstimatedFinish,
+	})
 	return &api.StageResponse{Stage: stage}, nil
 }
 
 func (s RunnerService) FailedStage(ctx context.Context, r api.StageRequest) (*api.StageResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("stage_id", int(r.StageID)))
 	logger.Debug("FailedStage request")
+	if _, err := s.currentRun(logger, r.RunID, "name = ?", r.Runner); err != nil {
+		return nil, err
+	}
+
 	var stage api.Stage
 	if err := s.DB.Preload("Process").
 		Preload("SearchAndTag").
@@ -476,12 +955,30 @@ func (s RunnerService) FailedStage(ctx context.Context, r api.StageRequest) (*ap
 	}
 
 	logger.Info("FAILED STAGE", zap.String("stage", avian.Name(&stage)))
+	s.observeStage(stage, "failed")
+	s.Publish(events.Event{
+		Type:    events.TypeStage,
+		Runner:  r.Runner,
+		Stage:   avian.Name(&stage),
+		StageID: stage.ID,
+		Status:  avian.Status(avian.StageState(&stage)),
+	})
+	s.Notify(notify.Event{
+		Trigger: notify.OnStageFailure,
+		Runner:  r.Runner,
+		Stage:   avian.Name(&stage),
+		Status:  avian.Status(avian.StageState(&stage)),
+	})
 	return &api.StageResponse{Stage: stage}, nil
 }
 
 func (s RunnerService) FinishStage(ctx context.Context, r api.StageRequest) (*api.StageResponse, error) {
 	logger := s.logger.With(zap.String("runner", r.Runner), zap.Int("stage_id", int(r.StageID)))
 	logger.Debug("FinishStage request")
+	if _, err := s.currentRun(logger, r.RunID, "name = ?", r.Runner); err != nil {
+		return nil, err
+	}
+
 	var stage api.Stage
 	if err := s.DB.Preload("Process").
 		Preload("SearchAndTag").
@@ -502,38 +999,78 @@ func (s RunnerService) FinishStage(ctx context.Context, r api.StageRequest) (*ap
 	}
 
 	logger.Info("FINISHED STAGE", zap.String("stage", avian.Name(&stage)))
+	s.observeStage(stage, "finished")
+	s.Publish(events.Event{
+		Type:    events.TypeStage,
+		Runner:  r.Runner,
+		Stage:   avian.Name(&stage),
+		StageID: stage.ID,
+		Status:  avian.Status(avian.StageState(&stage)),
+	})
 	return &api.StageResponse{Stage: stage}, nil
 }
 
 // LogItem logs an item that has been processed
 func (s RunnerService) LogItem(ctx context.Context, r api.LogItemRequest) (*api.LogResponse, error) {
+	if _, err := s.currentRun(s.logger.With(zap.String("runner", r.Runner)), r.RunID, "name = ?", r.Runner); err != nil {
+		return nil, err
+	}
+
 	logger, err := s.logHandler.Get(r.Runner + "-item.log")
 	if err != nil {
 		return nil, err
 	}
 
-	logger = logger.With(
-		zap.String("runner", r.Runner),
+	logItem(logger.With(zap.String("runner", r.Runner)), r)
+	s.metrics.ItemsProcessed(1)
+	return &api.LogResponse{}, nil
+}
+
+// LogItems logs a batch of items for the runner
+func (s RunnerService) LogItems(ctx context.Context, r api.LogItemsRequest) (*api.LogResponse, error) {
+	if _, err := s.currentRun(s.logger.With(zap.String("runner", r.Runner)), r.RunID, "name = ?", r.Runner); err != nil {
+		return nil, err
+	}
+
+	logger, err := s.logHandler.Get(r.Runner + "-item.log")
+	if err != nil {
+		return nil, err
+	}
+
+	logger = logger.With(zap.String("runner", r.Runner))
+	for _, item := range r.Items {
+		logItem(logger, item)
+	}
+	s.metrics.ItemsProcessed(len(r.Items))
+	return &api.LogResponse{}, nil
+}
+
+// logItem writes the item to the item-log
+func logItem(logger *zap.Logger, r api.LogItemRequest) {
+	fields := []zap.Field{
 		zap.String("stage", r.Stage),
 		zap.Int("stage_id", r.StageID),
 		zap.Int("count", r.Count),
-	)
+	}
 
 	if len(r.ProcessStage) > 0 {
-		logger = logger.With(zap.String("process_stage", r.ProcessStage))
+		fields = append(fields, zap.String("process_stage", r.ProcessStage))
 	}
 	if len(r.MimeType) > 0 {
-		logger = logger.With(zap.String("mime_type", r.MimeType))
+		fields = append(fields, zap.String("mime_type", r.MimeType))
 	}
 	if len(r.GUID) > 0 {
-		logger = logger.With(zap.String("guid", r.GUID))
+		fields = append(fields, zap.String("guid", r.GUID))
 	}
 
-	logger.Debug(r.Message)
-	return &api.LogResponse{}, nil
+	logger.Debug(r.Message, fields...)
 }
 
 func (s RunnerService) LogDebug(ctx context.Context, r api.LogRequest) (*api.LogResponse, error) {
+	if _, err := s.currentRun(s.logger.With(zap.String("runner", r.Runner)), r.RunID, "name = ?", r.Runner); err != nil {
+		return nil, err
+	}
+
 	logger, err := s.logHandler.Get(r.Runner + "-runner.log")
 	if err != nil {
 		return nil, err
@@ -549,10 +1086,15 @@ func (s RunnerService) LogDebug(ctx context.Context, r api.LogRequest) (*api.Log
 	}
 
 	logger.Debug(r.Message)
+	s.publishLog(r, "debug")
 	return &api.LogResponse{}, nil
 }
 
 func (s RunnerService) LogInfo(ctx context.Context, r api.LogRequest) (*api.LogResponse, error) {
+	if _, err := s.currentRun(s.logger.With(zap.String("runner", r.Runner)), r.RunID, "name = ?", r.Runner); err != nil {
+		return nil, err
+	}
+
 	logger, err := s.logHandler.Get(r.Runner + "-runner.log")
 	if err != nil {
 		return nil, err
@@ -564,10 +1106,15 @@ func (s RunnerService) LogInfo(ctx context.Context, r api.LogRequest) (*api.LogR
 	}
 
 	logger.Info(r.Message)
+	s.publishLog(r, "info")
 	return &api.LogResponse{}, nil
 }
 
 func (s RunnerService) LogError(ctx context.Context, r api.LogRequest) (*api.LogResponse, error) {
+	if _, err := s.currentRun(s.logger.With(zap.String("runner", r.Runner)), r.RunID, "name = ?", r.Runner); err != nil {
+		return nil, err
+	}
+
 	logger, err := s.logHandler.Get(r.Runner + "-runner.log")
 	if err != nil {
 		return nil, err
@@ -582,9 +1129,66 @@ func (s RunnerService) LogError(ctx context.Context, r api.LogRequest) (*api.Log
 	}
 
 	logger.Error(r.Message)
+	s.publishLog(r, "error")
 	return &api.LogResponse{}, nil
 }
 
+// Publish publishes the event for a runner to the
+// subscribers of the event-stream
+func (s RunnerService) Publish(e events.Event) {
+	if s.events == nil || !s.events.Subscribed() {
+		return
+	}
+	e.Matter = s.matter(e.Runner)
+	s.events.Publish(e)
+}
+
+// observeStage observes the duration of the stage for the metrics
+func (s RunnerService) observeStage(stage api.Stage, status string) {
+	if stage.StartedAt == 0 {
+		return
+	}
+	duration := time.Now().Unix() - stage.StartedAt
+	s.metrics.StageDuration(avian.Name(&stage), status, float64(duration))
+}
+
+// Notify delivers the notifications for the event of a runner
+func (s RunnerService) Notify(e notify.Event) {
+	if s.notifier == nil {
+		return
+	}
+	e.Matter = s.matter(e.Runner)
+	s.notifier.Notify(e)
+}
+
+// publishLog publishes the log-message from a runner
+func (s RunnerService) publishLog(r api.LogRequest, level string) {
+	message := r.Message
+	if len(r.Exception) > 0 {
+		message += ": " + r.Exception
+	}
+	s.Publish(events.Event{
+		Type:    events.TypeLog,
+		Runner:  r.Runner,
+		Stage:   r.Stage,
+		StageID: uint(r.StageID),
+		Level:   level,
+		Message: message,
+	})
+}
+
+// matter returns the name of the compound-case for the runner
+func (s RunnerService) matter(name string) string {
+	var runner api.Runner
+	if err := s.DB.Preload("CaseSettings.CompoundCase").Where("name = ?", name).First(&runner).Error; err != nil {
+		return ""
+	}
+	if runner.CaseSettings == nil || runner.CaseSettings.CompoundCase == nil {
+		return ""
+	}
+	return runner.CaseSettings.CompoundCase.Name
+}
+
 func (s RunnerService) getLogger(logName string) (*zap.Logger, error) {
 	log, err := os.OpenFile(logName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
 	if err != nil {
@@ -608,62 +1212,131 @@ func (s RunnerService) getLogger(logName string) (*zap.Logger, error) {
 	return logger, nil
 }
 
-func (s RunnerService) SetServerActivity(runner api.Runner, active bool) error {
-	if err := s.DB.Model(&api.Server{}).Where("hostname = ?", runner.Hostname).Update("active", active).Error; err != nil {
-		s.logger.Error("Cannot set servers activity",
+// currentRun returns the runner for a callback from a runner-script,
+// the callbacks from the scripts of other runs are rejected
+func (s RunnerService) currentRun(logger *zap.Logger, runID string, where ...interface{}) (api.Runner, error) {
+	var runner api.Runner
+	if err := s.DB.First(&runner, where...).Error; err != nil {
+		logger.Error("Cannot get runner", zap.String("exception", err.Error()))
+		return runner, fmt.Errorf("cannot get runner: %v", err)
+	}
+
+	if runner.RunID != runID {
+		logger.Warn("Rejected callback from another run of the runner",
+			zap.String("run_id", runID),
+			zap.String("current_run_id", runner.RunID),
+		)
+		return runner, fmt.Errorf("run: %s is not the current run for runner: %s", runID, runner.Name)
+	}
+	return runner, nil
+}
+
+// Deactivate sets the runner to inactive with the status and releases
+// the server, the nms-licences and the token for the runner-script in
+// a transaction, false is returned if the run already has been stopped
+// - so the server and licences for the run are only released once
+func (s RunnerService) Deactivate(runner *api.Runner, status int64) (bool, error) {
+	tx := s.DB.Begin()
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+
+	query := tx.Model(&api.Runner{}).
+		Where("id = ? AND run_id = ? AND active = ?", runner.ID, runner.RunID, true).
+		Updates(map[string]interface{}{"active": false, "status": status})
+	if query.Error != nil {
+		tx.Rollback()
+		return false, query.Error
+	}
+	if query.RowsAffected == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := release(tx, *runner); err != nil {
+		tx.Rollback()
+		s.logger.Error("Cannot release the server and licences for runner",
 			zap.String("runner", runner.Name),
-			zap.String("server", runner.Hostname),
 			zap.String("exception", err.Error()),
 		)
+		return false, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return false, err
+	}
+	runner.Active = false
+	runner.Status = status
+	return true, nil
+}
+
+// release sets the server for the runner to inactive, releases
+// the nms-licences and revokes the token for the runner-script
+func release(db *gorm.DB, runner api.Runner) error {
+	if err := db.Model(&api.Server{}).Where("hostname = ?", runner.Hostname).Update("active", false).Error; err != nil {
+		return fmt.Errorf("cannot set server: %s to inactive: %v", runner.Hostname, err)
+	}
+	if err := resetNms(db, runner); err != nil {
 		return err
 	}
-	return nil
+	return auth.Revoke(db, runner.ID)
 }
 
-func (s RunnerService) ResetNms(runner api.Runner) error {
+// resetNms releases the workers and the licence for the runner
+func resetNms(db *gorm.DB, runner api.Runner) error {
 	// Get the latest data for the nms-server
 	var nms api.Nms
-	if err := s.DB.Preload("Licences").First(&nms, "address = ?", runner.Nms).Error; err != nil {
-		s.logger.Error("Cannot get NMS from DB",
-			zap.String("runner", runner.Name),
-			zap.String("nms", runner.Nms),
-			zap.String("exception", err.Error()),
-		)
-		return err
+	if err := db.Preload("Licences").First(&nms, "address = ?", runner.Nms).Error; err != nil {
+		return fmt.Errorf("cannot get nms: %s - %v", runner.Nms, err)
 	}
 
 	// Reset the licences for the nms
+	// the licences are updated by index, since
+	// they are saved again with the nms
 	nms.InUse = nms.InUse - runner.Workers
-	for _, lic := range nms.Licences {
+	for i := range nms.Licences {
+		lic := &nms.Licences[i]
 		if lic.Type == runner.Licence {
 			lic.InUse = lic.InUse - 1
-			if err := s.DB.Save(&lic).Error; err != nil {
-				s.logger.Error("Cannot update licence-information to DB",
-					zap.String("runner", runner.Name),
-					zap.String("nms", runner.Nms),
-					zap.String("licence", lic.Type),
-					zap.String("exception", err.Error()),
-				)
-				return err
+			if err := db.Save(lic).Error; err != nil {
+				return fmt.Errorf("cannot update licence: %s for nms: %s - %v", lic.Type, runner.Nms, err)
 			}
 		}
 	}
 
 	// update the nms to the db
-	if err := s.DB.Save(&nms).Error; err != nil {
-		s.logger.Error("Cannot update nms-information to DB",
-			zap.String("runner", runner.Name),
-			zap.String("nms", runner.Nms),
-			zap.String("licence", runner.Licence),
-			zap.String("exception", err.Error()),
-		)
-		return err
+	if err := db.Save(&nms).Error; err != nil {
+		return fmt.Errorf("cannot update nms: %s - %v", runner.Nms, err)
 	}
 	return nil
 }
 
+// newRunner returns a runner from the apply-request
+func newRunner(r api.RunnerApplyRequest) api.Runner {
+	// add the switches
+	var switches []*api.NuixSwitch
+	for _, nuixSwitch := range r.Switches {
+		switches = append(switches, &api.NuixSwitch{Value: nuixSwitch})
+	}
+
+	return api.Runner{
+		Name:         r.Name,
+		Hostname:     r.Hostname,
+		Nms:          r.Nms,
+		Licence:      r.Licence,
+		Xmx:          r.Xmx,
+		Workers:      r.Workers,
+		CaseSettings: r.CaseSettings,
+		Stages:       r.Stages,
+		Switches:     switches,
+		Engine:       r.Engine,
+	}
+}
+
 func getPreloadedRunner(db *gorm.DB, runner *api.Runner) error {
-	return db.Preload("Stages.Process.EvidenceStore").
+	return db.Preload("Stages.Process.EvidenceStore.Paths").
+		Preload("Stages.Process.EvidenceStore.Filters").
+		Preload("Stages.Process.EvidenceStore.Metadata").
 		Preload("Stages.SearchAndTag.Files").
 		Preload("Stages.Exclude").
 		Preload("Stages.Ocr").
@@ -676,6 +1349,62 @@ func getPreloadedRunner(db *gorm.DB, runner *api.Runner) error {
 		First(&runner, "name = ?", runner.Name).Error
 }
 
+// VerifyManifest records a new manifest for the evidence of the
+// runner in the background and compares it to the previous manifest
+func (s RunnerService) VerifyManifest(runner api.Runner) {
+	logger := s.logger.With(zap.String("runner", runner.Name))
+	if err := getPreloadedRunner(s.DB, &runner); err != nil {
+		logger.Error("Cannot get runner to verify manifest", zap.String("exception", err.Error()))
+		return
+	}
+
+	if !manifest.Enabled(runner) {
+		return
+	}
+
+	var server api.Server
+	if err := s.DB.First(&server, "hostname = ?", runner.Hostname).Error; err != nil {
+		logger.Error("Failed to retrive server from db", zap.String("server", runner.Hostname), zap.String("exception", err.Error()))
+		return
+	}
+
+	logger.Info("Verifying manifest for the evidence")
+	s.manifests.Go(runner, server, manifest.PhaseFinish, true)
+}
+
+// checkPathList checks that all the paths
+// listed in the path-list exists in the server
+func checkPathList(client *powershell.Client, pathList string) error {
+	content, err := client.GetContent(pathList)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range manifest.PathList(content) {
+		if powershell.IsUnc(path) {
+			err = client.CheckPathFromHost(path)
+		} else {
+			err = client.CheckPath(path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteEvidenceSources deletes the paths, filters
+// and metadata that belongs to the evidence
+func deleteEvidenceSources(db *gorm.DB, evidence *api.Evidence) error {
+	if err := db.Where("evidence_id = ?", evidence.ID).Delete(&api.EvidencePath{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("evidence_id = ?", evidence.ID).Delete(&api.EvidenceFilter{}).Error; err != nil {
+		return err
+	}
+	return db.Where("evidence_id = ?", evidence.ID).Delete(&api.EvidenceMetadata{}).Error
+}
+
 func mergeRunner(src, dst api.Runner) {
 
 }
@@ -694,7 +1423,12 @@ func (s RunnerService) RemoveScript(runner api.Runner) error {
 	if len(server.Username) != 0 {
 		logger.Debug("Adding credentials for powershell-session")
 		opts.Username = server.Username
-		opts.Password = server.Password
+		password, err := secrets.Resolve(s.DB, s.cipher, server.Password)
+		if err != nil {
+			logger.Error("Cannot resolve password for server", zap.String("exception", err.Error()))
+			return fmt.Errorf("cannot resolve password for server: %s - %v", server.Hostname, err)
+		}
+		opts.Password = password
 	}
 
 	// create the client
@@ -707,7 +1441,13 @@ func (s RunnerService) RemoveScript(runner api.Runner) error {
 	// close the client on exit
 	defer client.Close()
 
-	var scriptName = fmt.Sprintf("%s\\%s.gen.rb", server.NuixPath, runner.Name)
+	// the extension depends on the engine for the runner or server
+	fileName, err := script.FileName(runner, server)
+	if err != nil {
+		logger.Error("Cannot get the name for the script-file", zap.String("exception", err.Error()))
+		return err
+	}
+	var scriptName = fmt.Sprintf("%s\\%s", server.NuixPath, fileName)
 	if err := client.RemoveItem(scriptName); err != nil {
 		logger.Error("Failed to remove script-file in ps-session",
 			zap.String("server", runner.Hostname),
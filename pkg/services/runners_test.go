@@ -0,0 +1,340 @@
+package services_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/dbtest"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"github.com/avian-digital-forensics/auto-processing/pkg/services"
+	"github.com/jinzhu/gorm"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func newCipher(is *is.I) *secrets.Cipher {
+	key, err := secrets.NewKey()
+	is.NoErr(err)
+	c, err := secrets.New(key)
+	is.NoErr(err)
+	return c
+}
+
+// seedRun creates an active runner with its server, nms and token -
+// the password for the server is a missing secret, so the script
+// can't be removed from the server when the runner is stopped
+func seedRun(is *is.I, db *gorm.DB) api.Runner {
+	is.NoErr(tables.Migrate(db))
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01", Username: "avian", Password: secrets.SecretReference("missing"), Active: true}).Error)
+	is.NoErr(db.Create(&api.Nms{Address: "nms01", Workers: 4, InUse: 2, Licences: []api.Licence{
+		{Type: "enterprise-workstation", Amount: 2, InUse: 1},
+	}}).Error)
+
+	runner := api.Runner{
+		Name:     "case-01",
+		Hostname: "dev01",
+		Nms:      "nms01",
+		Licence:  "enterprise-workstation",
+		Workers:  2,
+		Active:   true,
+		Status:   avian.StatusRunning,
+		RunID:    "run-2",
+	}
+	is.NoErr(db.Create(&runner).Error)
+	_, err := auth.NewRunnerToken(db, newCipher(is), runner)
+	is.NoErr(err)
+	return runner
+}
+
+// released checks that the server, the licences and
+// the token for the runner have been released once
+func released(is *is.I, db *gorm.DB, runner api.Runner, status int64) {
+	var stopped api.Runner
+	is.NoErr(db.First(&stopped, runner.ID).Error)
+	is.True(!stopped.Active)
+	is.Equal(stopped.Status, status)
+
+	var server api.Server
+	is.NoErr(db.First(&server, "hostname = ?", "dev01").Error)
+	is.True(!server.Active)
+
+	var nms api.Nms
+	is.NoErr(db.Preload("Licences").First(&nms, "address = ?", "nms01").Error)
+	is.Equal(nms.InUse, int64(0))
+	is.Equal(nms.Licences[0].InUse, int64(0))
+
+	var tokens int
+	is.NoErr(db.Model(&api.Token{}).Where("runner_id = ?", runner.ID).Count(&tokens).Error)
+	is.Equal(tokens, 0)
+}
+
+func TestFinishStaleRun(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	runner := seedRun(is, db)
+	svc := services.NewRunnerService(db, nil, "", "", zap.NewNop(), nil, nil, nil, nil, nil, nil)
+
+	// the callbacks from a previous run are rejected
+	_, err := svc.Finish(context.Background(), api.RunnerFinishRequest{ID: runner.ID, Runner: runner.Name, RunID: "run-1"})
+	is.True(err != nil)
+	_, err = svc.Failed(context.Background(), api.RunnerFailedRequest{ID: runner.ID, Runner: runner.Name, RunID: "run-1"})
+	is.True(err != nil)
+
+	var active api.Runner
+	is.NoErr(db.First(&active, runner.ID).Error)
+	is.True(active.Active)
+	is.Equal(active.Status, avian.StatusRunning)
+}
+
+func TestFinishTwice(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	runner := seedRun(is, db)
+	svc := services.NewRunnerService(db, nil, "", "", zap.NewNop(), nil, nil, nil, nil, nil, nil)
+
+	// the retried callbacks don't release the licences again
+	request := api.RunnerFinishRequest{ID: runner.ID, Runner: runner.Name, RunID: runner.RunID}
+	_, err := svc.Finish(context.Background(), request)
+	is.NoErr(err)
+	_, err = svc.Finish(context.Background(), request)
+	is.NoErr(err)
+	_, err = svc.Failed(context.Background(), api.RunnerFailedRequest{ID: runner.ID, Runner: runner.Name, RunID: runner.RunID})
+	is.NoErr(err)
+
+	released(is, db, runner, avian.StatusFinished)
+}
+
+func TestFinishAfterCleanupFailure(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	runner := seedRun(is, db)
+	svc := services.NewRunnerService(db, nil, "", "", zap.NewNop(), nil, nil, nil, nil, nil, nil)
+
+	// the script can't be removed from the server
+	is.True(svc.RemoveScript(runner) != nil)
+
+	// but the run is finished and released anyway
+	_, err := svc.Finish(context.Background(), api.RunnerFinishRequest{ID: runner.ID, Runner: runner.Name, RunID: runner.RunID})
+	is.NoErr(err)
+	released(is, db, runner, avian.StatusFinished)
+}
+
+func TestDeleteActive(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	runner := seedRun(is, db)
+	svc := services.NewRunnerService(db, nil, "", "", zap.NewNop(), nil, nil, nil, nil, nil, nil)
+
+	// the active runner is only deleted with force
+	_, err := svc.Delete(context.Background(), api.RunnerDeleteRequest{Name: runner.Name})
+	is.True(err != nil)
+
+	// the forced delete stops the run and releases it
+	_, err = svc.Delete(context.Background(), api.RunnerDeleteRequest{Name: runner.Name, Force: true})
+	is.NoErr(err)
+	released(is, db, runner, avian.StatusFailed)
+
+	// the callbacks from the stopped run don't release it again
+	_, err = svc.Failed(context.Background(), api.RunnerFailedRequest{ID: runner.ID, Runner: runner.Name, RunID: runner.RunID})
+	is.NoErr(err)
+	released(is, db, runner, avian.StatusFailed)
+}
+
+func TestPurge(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	runner := seedRun(is, db)
+	svc := services.NewRunnerService(db, nil, "", "", zap.NewNop(), nil, nil, nil, nil, nil, nil)
+
+	is.NoErr(db.Create(&api.Manifest{RunnerID: runner.ID, Evidence: []*api.ManifestEvidence{
+		{Name: "Evidence", Files: []*api.ManifestFile{{Path: `C:\Evidence\a.pst`}}},
+	}, Changes: []*api.ManifestChange{{Path: `C:\Evidence\b.pst`}}}).Error)
+
+	is.NoErr(db.Model(&runner).Association("Switches").Append(&api.NuixSwitch{Value: "-Xmx4g"}).Error)
+	is.NoErr(db.Model(&runner).Association("CaseSettings").Append(&api.CaseSettings{
+		CaseLocation:   `C:\Cases`,
+		Case:           &api.Case{Name: "case-01"},
+		CompoundCase:   &api.Case{Name: "compound-01"},
+		ReviewCompound: &api.Case{Name: "review-01"},
+	}).Error)
+	is.NoErr(db.Model(&runner).Association("Stages").Append(
+		&api.Stage{Process: &api.Process{EvidenceStore: []*api.Evidence{{
+			Name:      "Evidence",
+			Directory: `C:\Evidence`,
+			Paths:     []*api.EvidencePath{{Path: `D:\Evidence`}},
+			Filters:   []*api.EvidenceFilter{{Pattern: "*.pst"}},
+			Metadata:  []*api.EvidenceMetadata{{Key: "custodian", Value: "avian"}},
+		}}}},
+		&api.Stage{SearchAndTag: &api.SearchAndTag{Search: "kind:email", Tag: "emails", Files: []*api.File{{Path: `C:\Searches\a.txt`}}}},
+		&api.Stage{Populate: &api.Populate{Search: "kind:email", Types: []*api.Type{{Type: "native"}}}},
+		&api.Stage{Exclude: &api.Exclude{Search: "kind:image", Reason: "images"}},
+		&api.Stage{Ocr: &api.Ocr{Search: "kind:image"}},
+		&api.Stage{Reload: &api.Reload{Search: "kind:image"}},
+	).Error)
+
+	_, err := svc.Delete(context.Background(), api.RunnerDeleteRequest{Name: runner.Name, Force: true, Purge: true})
+	is.NoErr(err)
+
+	// the runner is deleted with its stages, evidence,
+	// switches, case-settings, manifests and tokens
+	for _, model := range []interface{}{
+		&api.Runner{},
+		&api.Stage{},
+		&api.Process{},
+		&api.Evidence{},
+		&api.EvidencePath{},
+		&api.EvidenceFilter{},
+		&api.EvidenceMetadata{},
+		&api.SearchAndTag{},
+		&api.File{},
+		&api.Populate{},
+		&api.Type{},
+		&api.Exclude{},
+		&api.Ocr{},
+		&api.Reload{},
+		&api.NuixSwitch{},
+		&api.CaseSettings{},
+		&api.Case{},
+		&api.Manifest{},
+		&api.ManifestEvidence{},
+		&api.ManifestFile{},
+		&api.ManifestChange{},
+		&api.Token{},
+	} {
+		var count int
+		is.NoErr(db.Model(model).Count(&count).Error)
+		is.Equal(count, 0)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	is.NoErr(tables.Migrate(db))
+	svc := services.NewRunnerService(db, nil, "", "", zap.NewNop(), nil, nil, nil, nil, nil, nil)
+
+	is.NoErr(db.Create(&api.Runner{Name: "case-01", Hostname: "dev01"}).Error)
+	_, err := svc.Delete(context.Background(), api.RunnerDeleteRequest{Name: "case-01"})
+	is.NoErr(err)
+
+	// a new runner has been applied with the same name
+	live := api.Runner{Name: "case-01", Hostname: "dev02"}
+	is.NoErr(db.Create(&live).Error)
+	_, err = svc.Restore(context.Background(), api.RunnerRestoreRequest{Name: "case-01"})
+	is.True(err != nil)
+
+	var trashed int
+	is.NoErr(db.Model(&api.Runner{}).Where("d_time IS NOT NULL").Count(&trashed).Error)
+	is.Equal(trashed, 1)
+
+	// the runner is restored when the new runner is deleted
+	_, err = svc.Delete(context.Background(), api.RunnerDeleteRequest{Name: strconv.Itoa(int(live.ID)), Purge: true})
+	is.NoErr(err)
+	restored, err := svc.Restore(context.Background(), api.RunnerRestoreRequest{Name: "case-01"})
+	is.NoErr(err)
+	is.Equal(restored.Runner.Hostname, "dev01")
+	is.True(restored.Runner.DTime == nil)
+}
+
+func TestListTrash(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	is.NoErr(tables.Migrate(db))
+	svc := services.NewRunnerService(db, nil, "", "", zap.NewNop(), nil, nil, nil, nil, nil, nil)
+
+	for _, name := range []string{"case-01", "case-02", "case-03"} {
+		is.NoErr(db.Create(&api.Runner{Name: name, Hostname: "dev01"}).Error)
+		_, err := svc.Delete(context.Background(), api.RunnerDeleteRequest{Name: name})
+		is.NoErr(err)
+	}
+
+	// the trash is listed in pages
+	first, err := svc.ListTrash(context.Background(), api.RunnerListTrashRequest{Limit: 2})
+	is.NoErr(err)
+	is.Equal(len(first.Runners), 2)
+	is.True(first.NextPageToken != "")
+
+	last, err := svc.ListTrash(context.Background(), api.RunnerListTrashRequest{Limit: 2, PageToken: first.NextPageToken})
+	is.NoErr(err)
+	is.Equal(len(last.Runners), 1)
+	is.Equal(last.NextPageToken, "")
+
+	var names []string
+	for _, runner := range append(first.Runners, last.Runners...) {
+		names = append(names, runner.Name)
+	}
+	is.Equal(names, []string{"case-03", "case-02", "case-01"})
+}
+
+func TestApplyRollback(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	is.NoErr(tables.Migrate(db))
+	svc := services.NewRunnerService(db, nil, "", "", zap.NewNop(), nil, nil, nil, nil, nil, nil)
+
+	caseSettings := func() *api.CaseSettings {
+		return &api.CaseSettings{
+			CaseLocation:   `C:\Cases`,
+			Case:           &api.Case{Name: "case-01"},
+			CompoundCase:   &api.Case{Name: "compound-01"},
+			ReviewCompound: &api.Case{Name: "review-01"},
+		}
+	}
+	is.NoErr(db.Create(&api.Runner{
+		Name:         "case-01",
+		Hostname:     "dev01",
+		CaseSettings: caseSettings(),
+		Stages:       []*api.Stage{{Exclude: &api.Exclude{Search: "kind:email", Reason: "emails"}}},
+	}).Error)
+
+	// the update fails after the stages were deleted in the transaction
+	_, err := svc.Apply(context.Background(), api.RunnerApplyRequest{
+		Name:         "case-01",
+		Hostname:     "missing",
+		Nms:          "nms01",
+		Licence:      "enterprise-workstation",
+		Xmx:          "2g",
+		Workers:      2,
+		CaseSettings: caseSettings(),
+		Stages:       []*api.Stage{{Exclude: &api.Exclude{Search: "kind:image", Reason: "images"}}},
+		Update:       true,
+	})
+	is.True(err != nil)
+
+	// so the deletes are rolled back
+	var stages int
+	is.NoErr(db.Model(&api.Stage{}).Count(&stages).Error)
+	is.Equal(stages, 1)
+	is.NoErr(db.Create(&api.Server{Hostname: "dev01"}).Error)
+}
+
+func TestDeleteByID(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	is.NoErr(tables.Migrate(db))
+	svc := services.NewRunnerService(db, nil, "", "", zap.NewNop(), nil, nil, nil, nil, nil, nil)
+
+	runner := api.Runner{Name: "case-01", Hostname: "dev01"}
+	is.NoErr(db.Create(&runner).Error)
+
+	// the runner is deleted by its name or its id
+	_, err := svc.Delete(context.Background(), api.RunnerDeleteRequest{Name: "case-01"})
+	is.NoErr(err)
+	_, err = svc.Restore(context.Background(), api.RunnerRestoreRequest{Name: "case-01"})
+	is.NoErr(err)
+	_, err = svc.Delete(context.Background(), api.RunnerDeleteRequest{Name: strconv.Itoa(int(runner.ID))})
+	is.NoErr(err)
+
+	var trashed api.Runner
+	is.NoErr(db.First(&trashed, runner.ID).Error)
+	is.True(trashed.DTime != nil)
+
+	// the runners in the trash are only deleted when they are purged
+	_, err = svc.Delete(context.Background(), api.RunnerDeleteRequest{Name: "case-01"})
+	is.True(err != nil)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"go.uber.org/zap"
+
+	"github.com/jinzhu/gorm"
+)
+
+// secretName is the valid names for the secrets
+var secretName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+type SecretService struct {
+	db     *gorm.DB
+	logger *zap.Logger
+	cipher *secrets.Cipher
+}
+
+func NewSecretService(db *gorm.DB, logger *zap.Logger, cipher *secrets.Cipher) SecretService {
+	return SecretService{db: db, logger: logger, cipher: cipher}
+}
+
+// Set creates or updates the secret, the value
+// is encrypted with the master-key
+func (s SecretService) Set(ctx context.Context, r api.SecretSetRequest) (*api.SecretSetResponse, error) {
+	logger := s.logger.With(zap.String("secret", r.Name))
+	if !secretName.MatchString(r.Name) {
+		return nil, fmt.Errorf("invalid name for secret: %q - use letters, digits, _, . or -", r.Name)
+	}
+	if r.Value == "" {
+		return nil, fmt.Errorf("value must be specified for secret: %s", r.Name)
+	}
+
+	value, err := s.cipher.Encrypt(r.Value)
+	if err != nil {
+		logger.Error("Cannot encrypt secret", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	var secret api.Secret
+	if err := s.db.Where("name = ?", r.Name).First(&secret).Error; err != nil && !gorm.IsRecordNotFoundError(err) {
+		logger.Error("Cannot get secret", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	secret.Name = r.Name
+	secret.Value = value
+	if err := s.db.Save(&secret).Error; err != nil {
+		logger.Error("Cannot save secret", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot save secret: %v", err)
+	}
+
+	logger.Info("Secret has been set")
+	secret.Value = ""
+	return &api.SecretSetResponse{Secret: secret}, nil
+}
+
+// List returns the secrets, the values are never returned
+func (s SecretService) List(ctx context.Context, r api.SecretListRequest) (*api.SecretListResponse, error) {
+	var list []api.Secret
+	if err := s.db.Order("name").Find(&list).Error; err != nil {
+		s.logger.Error("Cannot list secrets", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot list secrets: %v", err)
+	}
+
+	for i := range list {
+		list[i].Value = ""
+	}
+	return &api.SecretListResponse{Secrets: list}, nil
+}
+
+// Delete deletes the secret, the secret can't be deleted
+// while it is referenced by any servers or nms-servers
+func (s SecretService) Delete(ctx context.Context, r api.SecretDeleteRequest) (*api.SecretDeleteResponse, error) {
+	logger := s.logger.With(zap.String("secret", r.Name))
+
+	var secret api.Secret
+	if err := s.db.Where("name = ?", r.Name).First(&secret).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("secret not found: %s", r.Name)
+		}
+		logger.Error("Cannot get secret", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	var servers []api.Server
+	var nms []api.Nms
+	reference := secrets.SecretReference(secret.Name)
+	if err := s.db.Select("hostname").Where("password = ?", reference).Find(&servers).Error; err != nil {
+		logger.Error("Cannot get the servers for the secret", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	if err := s.db.Select("address").Where("password = ?", reference).Find(&nms).Error; err != nil {
+		logger.Error("Cannot get the nms for the secret", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	var used []string
+	for _, server := range servers {
+		used = append(used, "server: "+server.Hostname)
+	}
+	for _, n := range nms {
+		used = append(used, "nms: "+n.Address)
+	}
+	if len(used) != 0 {
+		logger.Error("Cannot delete secret that is referenced", zap.Strings("references", used))
+		return nil, fmt.Errorf("cannot delete secret %s - referenced by %s", secret.Name, strings.Join(used, ", "))
+	}
+
+	if err := s.db.Delete(&secret).Error; err != nil {
+		logger.Error("Cannot delete secret", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	logger.Info("Deleted secret")
+	return &api.SecretDeleteResponse{}, nil
+}
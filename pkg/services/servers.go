@@ -3,9 +3,15 @@ package services
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/avian-digital-forensics/auto-processing/generate/script"
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
 	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	avian "github.com/avian-digital-forensics/auto-processing/pkg/avian-client"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore"
 	"github.com/avian-digital-forensics/auto-processing/pkg/powershell"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
 	"go.uber.org/zap"
 
 	"github.com/jinzhu/gorm"
@@ -16,10 +22,11 @@ type ServerService struct {
 	db     *gorm.DB
 	shell  ps.Shell
 	logger *zap.Logger
+	cipher *secrets.Cipher
 }
 
-func NewServerService(db *gorm.DB, shell ps.Shell, logger *zap.Logger) ServerService {
-	return ServerService{db: db, shell: shell, logger: logger}
+func NewServerService(db *gorm.DB, shell ps.Shell, logger *zap.Logger, cipher *secrets.Cipher) ServerService {
+	return ServerService{db: db, shell: shell, logger: logger, cipher: cipher}
 }
 
 func (s ServerService) Apply(ctx context.Context, r api.ServerApplyRequest) (*api.ServerApplyResponse, error) {
@@ -35,6 +42,11 @@ func (s ServerService) Apply(ctx context.Context, r api.ServerApplyRequest) (*ap
 		return nil, fmt.Errorf("specify operating_system for %s - 'linux' or 'windows'", r.Hostname)
 	}
 
+	if _, err := script.New(r.Engine); err != nil {
+		logger.Error("specify engine - 'ruby' or 'python'", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("specify engine for %s - %v", r.Hostname, err)
+	}
+
 	// Check if the requested server exists (in that case update it)
 	logger.Debug("Checking if server already exists")
 	var newSrv api.Server
@@ -60,7 +72,12 @@ func (s ServerService) Apply(ctx context.Context, r api.ServerApplyRequest) (*ap
 		if len(newSrv.Username) != 0 {
 			logger.Debug("Adding credentials for powershell-session")
 			opts.Username = newSrv.Username
-			opts.Password = newSrv.Password
+			password, err := secrets.Resolve(s.db, s.cipher, newSrv.Password)
+			if err != nil {
+				logger.Error("Cannot resolve password for server", zap.String("exception", err.Error()))
+				return nil, fmt.Errorf("cannot resolve password for server: %s - %v", newSrv.Hostname, err)
+			}
+			opts.Password = password
 		}
 
 		// create the client
@@ -76,13 +93,22 @@ func (s ServerService) Apply(ctx context.Context, r api.ServerApplyRequest) (*ap
 		}
 	}
 
+	// The password is encrypted with the master-key,
+	// unless it is a reference (env:, file: or secret:)
+	password, err := secrets.Seal(s.db, s.cipher, r.Password)
+	if err != nil {
+		logger.Error("Cannot store password for server", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot store password for server: %s - %v", r.Hostname, err)
+	}
+
 	// Set data to the new Server-model
 	newSrv.Hostname = r.Hostname
 	newSrv.Port = r.Port
 	newSrv.Username = r.Username
-	newSrv.Password = r.Password
+	newSrv.Password = password
 	newSrv.OperatingSystem = r.OperatingSystem
 	newSrv.NuixPath = r.NuixPath
+	newSrv.Engine = r.Engine
 
 	// Save the new NMS to the DB
 	logger.Info("Saving server to the DB")
@@ -92,16 +118,153 @@ func (s ServerService) Apply(ctx context.Context, r api.ServerApplyRequest) (*ap
 	}
 
 	logger.Debug("Server has been saved to the DB")
-	return &api.ServerApplyResponse{}, nil
+	newSrv.Password = secrets.Mask(newSrv.Password)
+	return &api.ServerApplyResponse{Server: newSrv}, nil
 }
 
 func (s ServerService) List(ctx context.Context, r api.ServerListRequest) (*api.ServerListResponse, error) {
 	s.logger.Debug("Getting Servers-list")
+	p, err := newPage(r.Limit, r.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := sortOrder(r.Sort, map[string]string{
+		"id":       "id",
+		"hostname": "hostname",
+		"created":  "c_time",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.Order(order)
+	if r.Hostname != "" {
+		query = query.Where(likeCondition(query, "hostname"), like(r.Hostname))
+	}
+
 	var servers []api.Server
-	if err := s.db.Find(&servers).Error; err != nil {
+	if err := p.apply(query).Find(&servers).Error; err != nil {
 		s.logger.Error("Cannot get Servers-list", zap.String("exception", err.Error()))
 		return nil, err
 	}
+
+	next, n := p.next(len(servers))
+	servers = servers[:n]
+	for i := range servers {
+		servers[i].Password = secrets.Mask(servers[i].Password)
+	}
 	s.logger.Debug("Got Servers-list", zap.Int("amount", len(servers)))
-	return &api.ServerListResponse{Servers: servers}, nil
+	return &api.ServerListResponse{Servers: servers, NextPageToken: next}, nil
+}
+
+// Get returns the requested server
+func (s ServerService) Get(ctx context.Context, r api.ServerGetRequest) (*api.ServerGetResponse, error) {
+	logger := s.logger.With(zap.String("server", r.Hostname))
+	logger.Debug("Getting server")
+	var server api.Server
+	if err := s.db.First(&server, "hostname = ?", r.Hostname).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("server not found: %s", r.Hostname)
+		}
+		logger.Error("Cannot get server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	logger.Debug("Returning server")
+	server.Password = secrets.Mask(server.Password)
+	return &api.ServerGetResponse{Server: server}, nil
+}
+
+// VerifyPassword verifies the stored password for the
+// server, it requires an admin-token
+func (s ServerService) VerifyPassword(ctx context.Context, r api.ServerVerifyPasswordRequest) (*api.ServerVerifyPasswordResponse, error) {
+	logger := s.logger.With(zap.String("server", r.Hostname))
+	if err := auth.RequireAdmin(ctx); err != nil {
+		logger.Warn("Cannot verify password for server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	var server api.Server
+	if err := s.db.First(&server, "hostname = ?", r.Hostname).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("server not found: %s", r.Hostname)
+		}
+		logger.Error("Cannot get server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	match, err := secrets.Match(s.db, s.cipher, server.Password, r.Password)
+	if err != nil {
+		logger.Error("Cannot resolve password for server", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot resolve password for server: %s - %v", r.Hostname, err)
+	}
+	logger.Info("Verified password for server", zap.Bool("match", match))
+	return &api.ServerVerifyPasswordResponse{Match: match}, nil
+}
+
+// Delete deletes the requested server, the server
+// can't be deleted while it is used by any runners
+// that are waiting or active
+func (s ServerService) Delete(ctx context.Context, r api.ServerDeleteRequest) (*api.ServerDeleteResponse, error) {
+	logger := s.logger.With(zap.String("server", r.Hostname))
+	logger.Debug("Getting server to delete")
+
+	tx := s.db.Begin()
+	var server api.Server
+	if err := tx.First(&server, "hostname = ?", r.Hostname).Error; err != nil {
+		tx.Rollback()
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("server not found: %s", r.Hostname)
+		}
+		logger.Error("Cannot get server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	runners, err := usedByRunners(tx, "hostname", server.Hostname)
+	if err != nil {
+		tx.Rollback()
+		logger.Error("Cannot get the runners for the server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	if len(runners) != 0 {
+		tx.Rollback()
+		logger.Error("Cannot delete server used by runners", zap.Strings("runners", runners))
+		return nil, fmt.Errorf("cannot delete server %s - used by the waiting or active runners: %s", server.Hostname, strings.Join(runners, ", "))
+	}
+
+	logger.Debug("Deleting server")
+	if err := tx.Delete(&server).Error; err != nil {
+		tx.Rollback()
+		logger.Error("Cannot delete server", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Error("Commit failed", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	logger.Info("Deleted server")
+	return &api.ServerDeleteResponse{}, nil
+}
+
+// usedByRunners returns the names of the runners that are waiting
+// or active with the value for the column (hostname or nms),
+// the runners in the trash are left out
+func usedByRunners(db *gorm.DB, column, value string) ([]string, error) {
+	var runners []api.Runner
+	err := db.Scopes(datastore.NotDeleted).
+		Select("name").
+		Where(column+" = ?", value).
+		Where("status IN (?) OR active = ?", []int64{avian.StatusWaiting, avian.StatusRunning}, true).
+		Order("id").
+		Find(&runners).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, runner := range runners {
+		names = append(names, runner.Name)
+	}
+	return names, nil
 }
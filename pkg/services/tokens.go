@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/secrets"
+	"go.uber.org/zap"
+
+	"github.com/jinzhu/gorm"
+)
+
+type TokenService struct {
+	db     *gorm.DB
+	logger *zap.Logger
+	cipher *secrets.Cipher
+}
+
+func NewTokenService(db *gorm.DB, logger *zap.Logger, cipher *secrets.Cipher) TokenService {
+	return TokenService{db: db, logger: logger, cipher: cipher}
+}
+
+func (s TokenService) Create(ctx context.Context, r api.TokenCreateRequest) (*api.TokenCreateResponse, error) {
+	logger := s.logger.With(zap.String("token", r.Name))
+	if r.Name == "" {
+		return nil, fmt.Errorf("name must be specified for the token")
+	}
+
+	// only the admin-tokens can create other admin-tokens
+	if r.Admin {
+		if err := auth.RequireAdmin(ctx); err != nil {
+			logger.Warn("Cannot create admin-token", zap.String("exception", err.Error()))
+			return nil, err
+		}
+	}
+
+	var expires time.Duration
+	if r.Expires != "" {
+		var err error
+		expires, err = time.ParseDuration(r.Expires)
+		if err != nil || expires <= 0 {
+			return nil, fmt.Errorf("invalid expires: %s - expected a duration like 720h", r.Expires)
+		}
+	}
+
+	token, err := auth.New(r.Name, expires)
+	if err != nil {
+		logger.Error("Cannot generate token", zap.String("exception", err.Error()))
+		return nil, err
+	}
+	token.Admin = r.Admin
+
+	value, err := auth.Create(s.db, s.cipher, token)
+	if err != nil {
+		logger.Error("Cannot create token", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot create token: %v", err)
+	}
+
+	logger.Info("Created token", zap.String("key", token.Key), zap.Bool("admin", token.Admin))
+	token.Secret = ""
+	return &api.TokenCreateResponse{Token: *token, Value: value}, nil
+}
+
+func (s TokenService) List(ctx context.Context, r api.TokenListRequest) (*api.TokenListResponse, error) {
+	// only the admin-tokens can list the tokens
+	if err := auth.RequireAdmin(ctx); err != nil {
+		s.logger.Warn("Cannot list tokens", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	var tokens []api.Token
+	if err := s.db.Find(&tokens).Error; err != nil {
+		s.logger.Error("Cannot list tokens", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot list tokens: %v", err)
+	}
+
+	// the secrets are only returned when the tokens are created
+	for i := range tokens {
+		tokens[i].Secret = ""
+	}
+	return &api.TokenListResponse{Tokens: tokens}, nil
+}
+
+func (s TokenService) Delete(ctx context.Context, r api.TokenDeleteRequest) (*api.TokenDeleteResponse, error) {
+	logger := s.logger.With(zap.String("key", r.Key))
+	if r.Key == "" {
+		return nil, fmt.Errorf("key must be specified for the token")
+	}
+
+	// only the admin-tokens can revoke the tokens
+	if err := auth.RequireAdmin(ctx); err != nil {
+		logger.Warn("Cannot revoke token", zap.String("exception", err.Error()))
+		return nil, err
+	}
+
+	var token api.Token
+	if err := s.db.Where(&api.Token{Key: r.Key}).First(&token).Error; err != nil {
+		return nil, fmt.Errorf("cannot find token: %s", r.Key)
+	}
+
+	if err := s.db.Delete(&token).Error; err != nil {
+		logger.Error("Cannot revoke token", zap.String("exception", err.Error()))
+		return nil, fmt.Errorf("cannot revoke token: %v", err)
+	}
+
+	logger.Info("Revoked token", zap.String("token", token.Name))
+	return &api.TokenDeleteResponse{}, nil
+}
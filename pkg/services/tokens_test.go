@@ -0,0 +1,45 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/auth"
+	api "github.com/avian-digital-forensics/auto-processing/pkg/avian-api"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/dbtest"
+	"github.com/avian-digital-forensics/auto-processing/pkg/datastore/tables"
+	"github.com/avian-digital-forensics/auto-processing/pkg/services"
+	"github.com/matryer/is"
+	"go.uber.org/zap"
+)
+
+func TestTokensRequireAdmin(t *testing.T) {
+	is := is.New(t)
+	db := dbtest.Open(t)
+	is.NoErr(tables.Migrate(db))
+	svc := services.NewTokenService(db, zap.NewNop(), newCipher(is))
+
+	admin := auth.NewContext(context.Background(), api.Token{Name: "admin", Admin: true})
+	ci := auth.NewContext(context.Background(), api.Token{Name: "ci"})
+
+	created, err := svc.Create(admin, api.TokenCreateRequest{Name: "admin-2", Admin: true})
+	is.NoErr(err)
+
+	// the tokens are only listed for the admin-tokens
+	_, err = svc.List(ci, api.TokenListRequest{})
+	is.True(err != nil)
+	list, err := svc.List(admin, api.TokenListRequest{})
+	is.NoErr(err)
+	is.Equal(len(list.Tokens), 1)
+	is.Equal(list.Tokens[0].Secret, "")
+
+	// and only revoked by the admin-tokens
+	_, err = svc.Delete(ci, api.TokenDeleteRequest{Key: created.Token.Key})
+	is.True(err != nil)
+	_, err = svc.Delete(admin, api.TokenDeleteRequest{Key: created.Token.Key})
+	is.NoErr(err)
+
+	list, err = svc.List(admin, api.TokenListRequest{})
+	is.NoErr(err)
+	is.Equal(len(list.Tokens), 0)
+}
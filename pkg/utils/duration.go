@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDuration parses a duration like time.ParseDuration,
+// it also accepts the days with the suffix d (like 30d)
+func ParseDuration(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// ParseSince parses the time to list from, either a duration
+// back in time from now (like 24h or 7d) or a date (2006-01-02)
+// or a time in RFC3339 (2006-01-02T15:04:05Z07:00)
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s - expected a duration (like 24h or 7d) or a date (like 2006-01-02)", value)
+}
@@ -0,0 +1,40 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avian-digital-forensics/auto-processing/pkg/utils"
+	"github.com/matryer/is"
+)
+
+func TestParseDuration(t *testing.T) {
+	is := is.New(t)
+
+	d, err := utils.ParseDuration("30d")
+	is.NoErr(err)
+	is.Equal(d, 30*24*time.Hour)
+
+	d, err = utils.ParseDuration("90m")
+	is.NoErr(err)
+	is.Equal(d, 90*time.Minute)
+
+	_, err = utils.ParseDuration("xd")
+	is.True(err != nil)
+
+	now := time.Date(2020, 6, 15, 12, 0, 0, 0, time.UTC)
+	since, err := utils.ParseSince("7d", now)
+	is.NoErr(err)
+	is.Equal(since, now.AddDate(0, 0, -7))
+
+	since, err = utils.ParseSince("2020-06-01", now)
+	is.NoErr(err)
+	is.Equal(since, time.Date(2020, 6, 1, 0, 0, 0, 0, time.Local))
+
+	since, err = utils.ParseSince("2020-06-01T10:00:00Z", now)
+	is.NoErr(err)
+	is.Equal(since, time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC))
+
+	_, err = utils.ParseSince("yesterday", now)
+	is.True(err != nil)
+}